@@ -13,17 +13,28 @@ const (
 	EgressTraffic  = "egress"
 	IngressTraffic = "ingress"
 	PingTraffic    = "ping"
+	MirrorTraffic  = "mirror"
 
-	PingName           = "ping"
-	TimeoutName        = "timeout"
-	FailoverName       = "failover"
-	ProxyName          = "proxy"
-	RetryName          = "retry"
-	RetryRateLimitName = "retryRateLimit"
-	RetryRateBurstName = "retryBurst"
-	RateLimitName      = "rateLimit"
-	RateBurstName      = "burst"
-	ControllerName     = "name"
+	PingName             = "ping"
+	TimeoutName          = "timeout"
+	FailoverName         = "failover"
+	ProxyName            = "proxy"
+	CircuitBreakerName   = "circuitBreaker"
+	RetryName            = "retry"
+	RetryRateLimitName   = "retryRateLimit"
+	RetryRateBurstName   = "retryBurst"
+	RetryAttemptName     = "retryAttempt"
+	RetrySkipName        = "retrySkip"
+	RateLimitName        = "rateLimit"
+	RateBurstName        = "burst"
+	RateLimitKeyName     = "rateLimitKey"
+	ConcurrencyLimitName = "concurrencyLimit"
+	InFlightName         = "inFlight"
+	HedgeName            = "hedge"
+	EndpointName         = "endpoint"
+	BranchName           = "branch"
+	PriorityName         = "priority"
+	ControllerName       = "name"
 )
 
 // Accessor - function type
@@ -205,12 +216,32 @@ func (l *Entry) Value(value string) string {
 		return l.CtrlState[FailoverName]
 	case ProxyOperator:
 		return l.CtrlState[ProxyName]
+	case CircuitBreakerOperator:
+		return l.CtrlState[CircuitBreakerName]
 	case RetryOperator:
 		return l.CtrlState[RetryName]
 	case RetryRateLimitOperator:
 		return l.CtrlState[RetryRateLimitName]
 	case RetryRateBurstOperator:
 		return l.CtrlState[RetryRateBurstName]
+	case RetryAttemptOperator:
+		return l.CtrlState[RetryAttemptName]
+	case RetrySkipOperator:
+		return l.CtrlState[RetrySkipName]
+	case ConcurrencyLimitOperator:
+		return l.CtrlState[ConcurrencyLimitName]
+	case InFlightOperator:
+		return l.CtrlState[InFlightName]
+	case HedgeOperator:
+		return l.CtrlState[HedgeName]
+	case RateLimitKeyOperator:
+		return l.CtrlState[RateLimitKeyName]
+	case EndpointOperator:
+		return l.CtrlState[EndpointName]
+	case BranchOperator:
+		return l.CtrlState[BranchName]
+	case PriorityOperator:
+		return l.CtrlState[PriorityName]
 	}
 	if strings.HasPrefix(value, RequestReferencePrefix) {
 		name := requestOperatorHeaderName(value)
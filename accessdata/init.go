@@ -18,15 +18,25 @@ var Operators = map[string]*Operator{
 	OriginInstanceIdOperator: {"instance_id", OriginInstanceIdOperator},
 
 	// Route
-	RouteNameOperator:       {"route_name", RouteNameOperator},
-	TimeoutDurationOperator: {"timeout_ms", TimeoutDurationOperator},
-	RateLimitOperator:       {"rate_limit", RateLimitOperator},
-	RateBurstOperator:       {"rate_burst", RateBurstOperator},
-	RetryOperator:           {"retry", RetryOperator},
-	RetryRateLimitOperator:  {"retry_rate_limit", RetryRateLimitOperator},
-	RetryRateBurstOperator:  {"retry_rate_burst", RetryRateBurstOperator},
-	FailoverOperator:        {"failover", FailoverOperator},
-	ProxyOperator:           {"proxy", ProxyOperator},
+	RouteNameOperator:        {"route_name", RouteNameOperator},
+	TimeoutDurationOperator:  {"timeout_ms", TimeoutDurationOperator},
+	RateLimitOperator:        {"rate_limit", RateLimitOperator},
+	RateBurstOperator:        {"rate_burst", RateBurstOperator},
+	RetryOperator:            {"retry", RetryOperator},
+	RetryRateLimitOperator:   {"retry_rate_limit", RetryRateLimitOperator},
+	RetryRateBurstOperator:   {"retry_rate_burst", RetryRateBurstOperator},
+	RetryAttemptOperator:     {"retry_attempt", RetryAttemptOperator},
+	RetrySkipOperator:        {"retry_skip", RetrySkipOperator},
+	ConcurrencyLimitOperator: {"concurrency_limit", ConcurrencyLimitOperator},
+	InFlightOperator:         {"in_flight", InFlightOperator},
+	HedgeOperator:            {"hedge", HedgeOperator},
+	RateLimitKeyOperator:     {"rate_limit_key", RateLimitKeyOperator},
+	EndpointOperator:         {"endpoint", EndpointOperator},
+	BranchOperator:           {"branch", BranchOperator},
+	PriorityOperator:         {"priority", PriorityOperator},
+	FailoverOperator:         {"failover", FailoverOperator},
+	ProxyOperator:            {"proxy", ProxyOperator},
+	CircuitBreakerOperator:   {"circuit_breaker", CircuitBreakerOperator},
 
 	// Response
 	ResponseStatusCodeOperator:    {"status_code", ResponseStatusCodeOperator},
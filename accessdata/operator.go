@@ -13,7 +13,7 @@ const (
 	UserAgentHeaderName    = "USER-AGENT"
 	ForwardedForHeaderName = "X-FORWARDED-FOR"
 
-	TrafficOperator        = "%TRAFFIC%"      // ingress, egress, ping
+	TrafficOperator        = "%TRAFFIC%"      // ingress, egress, ping, mirror
 	StartTimeOperator      = "%START_TIME%"   // start time
 	DurationOperator       = "%DURATION%"     // Total duration in milliseconds of the request from the start time to the last byte out.
 	DurationStringOperator = "%DURATION_STR%" // Time package formatted
@@ -24,15 +24,25 @@ const (
 	OriginServiceOperator    = "%SERVICE%"     // origin service
 	OriginInstanceIdOperator = "%INSTANCE_ID%" // origin instance id
 
-	RouteNameOperator       = "%ROUTE_NAME%"
-	TimeoutDurationOperator = "%TIMEOUT_DURATION%"
-	RateLimitOperator       = "%RATE_LIMIT%"
-	RateBurstOperator       = "%RATE_BURST%"
-	RetryOperator           = "%RETRY"
-	RetryRateLimitOperator  = "%RETRY_RATE_LIMIT%"
-	RetryRateBurstOperator  = "%RETRY_RATE_BURST%"
-	FailoverOperator        = "%FAILOVER%"
-	ProxyOperator           = "%PROXY%"
+	RouteNameOperator        = "%ROUTE_NAME%"
+	TimeoutDurationOperator  = "%TIMEOUT_DURATION%"
+	RateLimitOperator        = "%RATE_LIMIT%"
+	RateBurstOperator        = "%RATE_BURST%"
+	RetryOperator            = "%RETRY"
+	RetryRateLimitOperator   = "%RETRY_RATE_LIMIT%"
+	RetryRateBurstOperator   = "%RETRY_RATE_BURST%"
+	RetryAttemptOperator     = "%RETRY_ATTEMPT%"     // attempt number, 1 is the original request
+	RetrySkipOperator        = "%RETRY_SKIP%"        // reason a retryable response was not retried
+	ConcurrencyLimitOperator = "%CONCURRENCY_LIMIT%" // current adaptive concurrency limit
+	InFlightOperator         = "%IN_FLIGHT%"         // in-flight requests when logged
+	HedgeOperator            = "%HEDGE%"             // true if the response is from the hedged request
+	RateLimitKeyOperator     = "%RATE_LIMIT_KEY%"    // rate limiter key of a rejected request
+	EndpointOperator         = "%ENDPOINT%"          // proxy endpoint selected for the request
+	BranchOperator           = "%BRANCH%"            // proxy branch, primary or canary
+	PriorityOperator         = "%PRIORITY%"          // priority of an ingress request, low, normal, high or critical
+	FailoverOperator         = "%FAILOVER%"
+	ProxyOperator            = "%PROXY%"
+	CircuitBreakerOperator   = "%CIRCUIT_BREAKER%" // closed, open, half-open
 
 	ResponseStatusCodeOperator    = "%STATUS_CODE%"    // HTTP status code
 	ResponseBytesReceivedOperator = "%BYTES_RECEIVED%" // bytes received
@@ -94,7 +104,8 @@ func requestOperatorHeaderName(value string) string {
 func IsStringValue(op Operator) bool {
 	switch op.Value {
 	case DurationOperator, TimeoutDurationOperator, RateBurstOperator,
-		RateLimitOperator, RetryOperator, RetryRateLimitOperator, RetryRateBurstOperator,
+		RateLimitOperator, RetryOperator, RetryRateLimitOperator, RetryRateBurstOperator, RetryAttemptOperator,
+		ConcurrencyLimitOperator, InFlightOperator, HedgeOperator,
 		FailoverOperator, ResponseStatusCodeOperator,
 		ResponseBytesSentOperator, ResponseBytesReceivedOperator:
 		return false
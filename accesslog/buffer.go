@@ -0,0 +1,354 @@
+package accesslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gotemplates/core/runtime"
+	"github.com/gotemplates/host/accessdata"
+	"github.com/gotemplates/host/messaging"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	ResourceUri = "urn:host:accesslog"
+
+	DefaultBufferSize    = 4096
+	DefaultBatchSize     = 256
+	DefaultFlushInterval = time.Second
+	DefaultSampleRate    = 10
+)
+
+// Overflow - behaviour when an entry is written to a full buffer
+type Overflow int
+
+const (
+	DropOverflow   Overflow = iota // The entry is dropped
+	BlockOverflow                  // The request goroutine waits until there is space
+	SampleOverflow                 // 1 in SampleRate entries replaces the oldest buffered entry, the others are dropped
+)
+
+func (o Overflow) String() string {
+	switch o {
+	case DropOverflow:
+		return "drop"
+	case BlockOverflow:
+		return "block"
+	case SampleOverflow:
+		return "sample"
+	}
+	return fmt.Sprintf("overflow(%v)", int(o))
+}
+
+// BufferConfig - configuration for the BufferedOutputHandler, a value <= 0 is the default
+type BufferConfig struct {
+	Size       int           // Entries in the ring buffer
+	BatchSize  int           // Entries written together, a full batch is written without waiting for the interval
+	Interval   time.Duration // Maximum wait before a partial batch is written
+	Overflow   Overflow
+	SampleRate int       // Used by SampleOverflow
+	Writer     io.Writer // A batch is one Write of newline terminated entries, nil is the standard logger
+}
+
+func NewBufferConfig(size, batchSize int, interval time.Duration, overflow Overflow) *BufferConfig {
+	c := new(BufferConfig)
+	c.Size = size
+	c.BatchSize = batchSize
+	c.Interval = interval
+	c.Overflow = overflow
+	return c
+}
+
+// BufferedOutputHandler - output to the standard logger, or a configured writer, from a background goroutine. Entries
+// are formatted when they are written, so an entry must not be changed after it is logged
+type BufferedOutputHandler struct{}
+
+func (BufferedOutputHandler) Write(items []accessdata.Operator, data *accessdata.Entry, formatter accessdata.Formatter) {
+	b := currentBuffer()
+	if !b.enqueue(bufferEntry{items: items, data: data, formatter: formatter}) {
+		// The buffer is closed
+		b.write([]bufferEntry{{items: items, data: data, formatter: formatter}})
+	}
+}
+
+// SetBufferConfig - configure the BufferedOutputHandler, a nil configuration is the default. Entries buffered with the
+// previous configuration are written first
+func SetBufferConfig(config *BufferConfig) error {
+	b, err := newBuffer(config)
+	if err != nil {
+		return err
+	}
+	bufferMu.Lock()
+	prev := buf
+	buf = b
+	bufferMu.Unlock()
+	go b.run()
+	registerResource()
+	if prev != nil {
+		prev.close()
+	}
+	return nil
+}
+
+// Flush - write the entries in the buffer of the BufferedOutputHandler
+func Flush() error {
+	bufferMu.Lock()
+	b := buf
+	bufferMu.Unlock()
+	if b == nil {
+		return nil
+	}
+	return b.flushBuffer()
+}
+
+// Close - write the entries in the buffer and stop the background goroutine, entries written after are written on
+// the request goroutine. The registered file is then closed. A messaging.Shutdown also closes, calling Close after
+// messaging.Shutdown waits until the entries are written
+func Close() error {
+	bufferMu.Lock()
+	b := buf
+	bufferMu.Unlock()
+	if b != nil {
+		b.close()
+	}
+	return closeFileOutput()
+}
+
+// Dropped - entries dropped because the buffer was full
+func Dropped() uint64 {
+	bufferMu.Lock()
+	b := buf
+	bufferMu.Unlock()
+	if b == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&b.dropped)
+}
+
+var (
+	bufferMu     sync.Mutex
+	buf          *buffer
+	registerOnce sync.Once
+)
+
+func currentBuffer() *buffer {
+	bufferMu.Lock()
+	defer bufferMu.Unlock()
+	if buf == nil {
+		buf, _ = newBuffer(nil)
+		go buf.run()
+		registerResource()
+	}
+	return buf
+}
+
+// registerResource - register the package as a messaging resource, so that the buffer and file are closed by
+// messaging.Shutdown
+func registerResource() {
+	registerOnce.Do(func() {
+		c := make(chan messaging.Message, 1)
+		messaging.RegisterResource(ResourceUri, c)
+		go receive(c)
+	})
+}
+
+func receive(c chan messaging.Message) {
+	for msg := range c {
+		switch msg.Event {
+		case messaging.StartupEvent:
+			messaging.ReplyTo(msg, runtime.NewStatusOK())
+		case messaging.ShutdownEvent:
+			Close()
+		}
+	}
+}
+
+type bufferEntry struct {
+	items     []accessdata.Operator
+	data      *accessdata.Entry
+	formatter accessdata.Formatter
+}
+
+// buffer - a bounded ring buffer of entries, written in batches by a background goroutine
+type buffer struct {
+	config  BufferConfig
+	writeMu sync.Mutex // Entries are also written on request goroutines once the buffer is closed
+	mu      sync.Mutex
+	notFull *sync.Cond
+	entries []bufferEntry
+	head    int
+	count   int
+	closed  bool
+	sampled uint64
+	dropped uint64
+	signal  chan struct{}
+	flush   chan chan struct{}
+	done    chan struct{}
+	exited  chan struct{}
+	once    sync.Once
+}
+
+func newBuffer(config *BufferConfig) (*buffer, error) {
+	b := new(buffer)
+	if config != nil {
+		b.config = *config
+	}
+	if b.config.Size <= 0 {
+		b.config.Size = DefaultBufferSize
+	}
+	if b.config.BatchSize <= 0 {
+		b.config.BatchSize = DefaultBatchSize
+	}
+	if b.config.BatchSize > b.config.Size {
+		b.config.BatchSize = b.config.Size
+	}
+	if b.config.Interval <= 0 {
+		b.config.Interval = DefaultFlushInterval
+	}
+	if b.config.SampleRate <= 0 {
+		b.config.SampleRate = DefaultSampleRate
+	}
+	if b.config.Overflow < DropOverflow || b.config.Overflow > SampleOverflow {
+		return nil, errors.New(fmt.Sprintf("invalid configuration: buffer overflow is invalid [%v]", int(b.config.Overflow)))
+	}
+	b.notFull = sync.NewCond(&b.mu)
+	b.entries = make([]bufferEntry, b.config.Size)
+	b.signal = make(chan struct{}, 1)
+	b.flush = make(chan chan struct{})
+	b.done = make(chan struct{})
+	b.exited = make(chan struct{})
+	return b, nil
+}
+
+// enqueue - add an entry, false if the buffer is closed
+func (b *buffer) enqueue(e bufferEntry) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for !b.closed && b.count == len(b.entries) {
+		switch b.config.Overflow {
+		case BlockOverflow:
+			b.notFull.Wait()
+			continue
+		case SampleOverflow:
+			atomic.AddUint64(&b.dropped, 1)
+			b.sampled++
+			if b.sampled%uint64(b.config.SampleRate) != 0 {
+				return true
+			}
+			// The oldest entry is dropped instead
+			b.entries[b.head] = bufferEntry{}
+			b.head = (b.head + 1) % len(b.entries)
+			b.count--
+		default:
+			atomic.AddUint64(&b.dropped, 1)
+			return true
+		}
+	}
+	if b.closed {
+		return false
+	}
+	b.entries[(b.head+b.count)%len(b.entries)] = e
+	b.count++
+	if b.count >= b.config.BatchSize {
+		select {
+		case b.signal <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+// take - remove at most n of the oldest entries
+func (b *buffer) take(n int) []bufferEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n > b.count {
+		n = b.count
+	}
+	if n == 0 {
+		return nil
+	}
+	batch := make([]bufferEntry, n)
+	for i := range batch {
+		batch[i] = b.entries[b.head]
+		b.entries[b.head] = bufferEntry{}
+		b.head = (b.head + 1) % len(b.entries)
+	}
+	b.count -= n
+	b.notFull.Broadcast()
+	return batch
+}
+
+func (b *buffer) run() {
+	defer close(b.exited)
+	ticker := time.NewTicker(b.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.signal:
+			b.write(b.take(b.config.BatchSize))
+		case <-ticker.C:
+			b.drain()
+		case reply := <-b.flush:
+			b.drain()
+			close(reply)
+		case <-b.done:
+			b.drain()
+			return
+		}
+	}
+}
+
+// drain - write all buffered entries, in batches
+func (b *buffer) drain() {
+	for batch := b.take(b.config.BatchSize); len(batch) > 0; batch = b.take(b.config.BatchSize) {
+		b.write(batch)
+	}
+}
+
+func (b *buffer) write(batch []bufferEntry) {
+	if len(batch) == 0 {
+		return
+	}
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+	if b.config.Writer == nil {
+		for _, e := range batch {
+			log.Println(e.formatter.Format(e.items, e.data))
+		}
+		return
+	}
+	var out bytes.Buffer
+	for _, e := range batch {
+		out.WriteString(e.formatter.Format(e.items, e.data))
+		out.WriteByte('\n')
+	}
+	b.config.Writer.Write(out.Bytes())
+}
+
+func (b *buffer) flushBuffer() error {
+	reply := make(chan struct{})
+	select {
+	case b.flush <- reply:
+	case <-b.exited:
+		return nil
+	}
+	<-reply
+	return nil
+}
+
+// close - stop accepting entries, write the buffered entries, and wait for the goroutine to exit
+func (b *buffer) close() {
+	b.once.Do(func() {
+		b.mu.Lock()
+		b.closed = true
+		b.notFull.Broadcast()
+		b.mu.Unlock()
+		close(b.done)
+	})
+	<-b.exited
+}
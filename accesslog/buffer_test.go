@@ -0,0 +1,96 @@
+package accesslog
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gotemplates/host/accessdata"
+	"github.com/gotemplates/host/messaging"
+	"strings"
+	"time"
+)
+
+func testBufferEntry(msg string) bufferEntry {
+	return bufferEntry{items: []accessdata.Operator{{Name: errorName, Value: msg}}, data: accessdata.NewEmptyEntry(), formatter: accessdata.JsonFormatter{}}
+}
+
+func testBatch(batch []bufferEntry) []string {
+	var s []string
+	for _, e := range batch {
+		s = append(s, e.formatter.Format(e.items, e.data))
+	}
+	return s
+}
+
+func Example_buffer_enqueue() {
+	// Buffers without a background goroutine
+	b, _ := newBuffer(NewBufferConfig(2, 0, 0, DropOverflow))
+	for i := 0; i < 5; i++ {
+		b.enqueue(testBufferEntry(fmt.Sprintf("entry-%v", i)))
+	}
+	fmt.Printf("test: enqueue(drop) -> [dropped:%v] %v\n", b.dropped, testBatch(b.take(10)))
+
+	config := NewBufferConfig(2, 0, 0, SampleOverflow)
+	config.SampleRate = 2
+	b, _ = newBuffer(config)
+	for i := 0; i < 5; i++ {
+		b.enqueue(testBufferEntry(fmt.Sprintf("entry-%v", i)))
+	}
+	fmt.Printf("test: enqueue(sample) -> [dropped:%v] %v\n", b.dropped, testBatch(b.take(10)))
+
+	b, _ = newBuffer(NewBufferConfig(1, 0, 0, BlockOverflow))
+	b.enqueue(testBufferEntry("entry-0"))
+	done := make(chan bool)
+	go func() { done <- b.enqueue(testBufferEntry("entry-1")) }()
+	time.Sleep(time.Millisecond * 20)
+	fmt.Printf("test: enqueue(block) -> [blocked:%v]\n", len(done) == 0)
+	fmt.Printf("test: take() -> %v\n", testBatch(b.take(10)))
+	fmt.Printf("test: enqueue(block) -> [ok:%v] [dropped:%v] %v\n", <-done, b.dropped, testBatch(b.take(10)))
+
+	_, err := newBuffer(NewBufferConfig(0, 0, 0, Overflow(5)))
+	fmt.Printf("test: newBuffer(overflow) -> [err:%v]\n", err)
+
+	//Output:
+	//test: enqueue(drop) -> [dropped:3] [{"error":"entry-0"} {"error":"entry-1"}]
+	//test: enqueue(sample) -> [dropped:3] [{"error":"entry-1"} {"error":"entry-3"}]
+	//test: enqueue(block) -> [blocked:true]
+	//test: take() -> [{"error":"entry-0"}]
+	//test: enqueue(block) -> [ok:true] [dropped:0] [{"error":"entry-1"}]
+	//test: newBuffer(overflow) -> [err:invalid configuration: buffer overflow is invalid [5]]
+
+}
+
+func ExampleBufferedOutputHandler() {
+	var out bytes.Buffer
+	config := NewBufferConfig(16, 4, time.Hour, DropOverflow)
+	config.Writer = &out
+	SetBufferConfig(config)
+
+	start := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	InitEgressOperators([]accessdata.Operator{{Value: accessdata.TrafficOperator}, {Value: accessdata.RouteNameOperator}})
+	for i := 0; i < 3; i++ {
+		Write[BufferedOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, 0, nil, nil, "", map[string]string{accessdata.ControllerName: fmt.Sprintf("route-%v", i)}))
+	}
+	fmt.Printf("test: Write() -> [written:%v]\n", out.Len() > 0)
+	Flush()
+	fmt.Printf("test: Flush() -> [dropped:%v]\n%v", Dropped(), out.String())
+
+	// A shutdown closes the buffer, entries are then written on the request goroutine
+	out.Reset()
+	Write[BufferedOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, 0, nil, nil, "", map[string]string{accessdata.ControllerName: "route-3"}))
+	messaging.Shutdown()
+	Close()
+	fmt.Printf("test: Shutdown() -> %v\n", strings.Split(strings.TrimSpace(out.String()), "\n"))
+	Write[BufferedOutputHandler, accessdata.JsonFormatter](accessdata.NewEgressEntry(start, 0, nil, nil, "", map[string]string{accessdata.ControllerName: "route-4"}))
+	fmt.Printf("test: Write(closed) -> %v\n", strings.Split(strings.TrimSpace(out.String()), "\n"))
+	InitEgressOperators(nil)
+
+	//Output:
+	//test: Write() -> [written:false]
+	//test: Flush() -> [dropped:0]
+	//{"traffic":"egress","route_name":"route-0"}
+	//{"traffic":"egress","route_name":"route-1"}
+	//{"traffic":"egress","route_name":"route-2"}
+	//test: Shutdown() -> [{"traffic":"egress","route_name":"route-3"}]
+	//test: Write(closed) -> [{"traffic":"egress","route_name":"route-3"} {"traffic":"egress","route_name":"route-4"}]
+
+}
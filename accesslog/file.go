@@ -0,0 +1,315 @@
+package accesslog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/gotemplates/host/accessdata"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultMaxFileSize = 100 * 1024 * 1024
+	DefaultMaxBackups  = 7
+
+	rotateTimeFormat = "20060102T150405.000000000"
+	compressedExt    = ".gz"
+)
+
+// FileConfig - configuration for a FileWriter, a value <= 0 is the default
+type FileConfig struct {
+	Path           string
+	MaxSize        int64         // Bytes written before the file is rotated
+	RotateInterval time.Duration // Time after the file is opened before it is rotated, 0 is no time based rotation
+	MaxBackups     int           // Rotated files kept, the oldest are removed
+	Compress       bool          // Rotated files are gzip compressed
+}
+
+func NewFileConfig(path string, maxSize int64, rotateInterval time.Duration, maxBackups int) *FileConfig {
+	c := new(FileConfig)
+	c.Path = path
+	c.MaxSize = maxSize
+	c.RotateInterval = rotateInterval
+	c.MaxBackups = maxBackups
+	c.Compress = true
+	return c
+}
+
+// FileOutputHandler - output to the file registered with RegisterFileOutput, entries are written to the standard
+// logger if there is no file, or the write fails
+type FileOutputHandler struct{}
+
+func (FileOutputHandler) Write(items []accessdata.Operator, data *accessdata.Entry, formatter accessdata.Formatter) {
+	s := formatter.Format(items, data)
+	fileMu.RLock()
+	w := fileOutput
+	fileMu.RUnlock()
+	if w != nil {
+		if _, err := w.Write([]byte(s + "\n")); err == nil {
+			return
+		}
+	}
+	log.Println(s)
+}
+
+var (
+	fileMu     sync.RWMutex
+	fileOutput *FileWriter
+	hupOnce    sync.Once
+)
+
+// RegisterFileOutput - configure the file of the FileOutputHandler, the previous file is closed. The file is reopened
+// on a SIGHUP, and is closed by Close and messaging.Shutdown
+func RegisterFileOutput(config *FileConfig) error {
+	w, err := NewFileWriter(config)
+	if err != nil {
+		return err
+	}
+	fileMu.Lock()
+	prev := fileOutput
+	fileOutput = w
+	fileMu.Unlock()
+	if prev != nil {
+		prev.Close()
+	}
+	registerResource()
+	hupOnce.Do(func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGHUP)
+		go reopenOnSignal(c)
+	})
+	return nil
+}
+
+func reopenOnSignal(c chan os.Signal) {
+	for range c {
+		fileMu.RLock()
+		w := fileOutput
+		fileMu.RUnlock()
+		if w == nil {
+			continue
+		}
+		if err := w.Reopen(); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func closeFileOutput() error {
+	fileMu.Lock()
+	w := fileOutput
+	fileOutput = nil
+	fileMu.Unlock()
+	if w == nil {
+		return nil
+	}
+	return w.Close()
+}
+
+// FileWriter - an io.Writer for a file that is rotated by size and time, it can also be the Writer of a
+// BufferConfig
+type FileWriter struct {
+	config   FileConfig
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	opened   time.Time
+	compress sync.WaitGroup
+	// Rotated files are compressed, and the oldest removed, one rotation at a time
+	compressMu sync.Mutex
+}
+
+var now = time.Now
+
+func NewFileWriter(config *FileConfig) (*FileWriter, error) {
+	if config == nil {
+		return nil, errors.New("invalid argument: file configuration is nil")
+	}
+	if config.Path == "" {
+		return nil, errors.New("invalid configuration: file path is empty")
+	}
+	w := new(FileWriter)
+	w.config = *config
+	if w.config.MaxSize <= 0 {
+		w.config.MaxSize = DefaultMaxFileSize
+	}
+	if w.config.RotateInterval < 0 {
+		w.config.RotateInterval = 0
+	}
+	if w.config.MaxBackups <= 0 {
+		w.config.MaxBackups = DefaultMaxBackups
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write - write to the file, the file is rotated first if the write would exceed the maximum size, or the rotate
+// interval has elapsed
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, errors.New(fmt.Sprintf("invalid state: file is closed [%v]", w.config.Path))
+	}
+	if w.size > 0 && (w.size+int64(len(p)) > w.config.MaxSize || (w.config.RotateInterval > 0 && now().Sub(w.opened) >= w.config.RotateInterval)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Reopen - close and open the file, used when the file has been moved by an external tool
+func (w *FileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
+	return w.open()
+}
+
+// Rotate - rotate the file
+func (w *FileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return errors.New(fmt.Sprintf("invalid state: file is closed [%v]", w.config.Path))
+	}
+	return w.rotate()
+}
+
+// Close - close the file, and wait for rotated files to be compressed
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mu.Unlock()
+	w.compress.Wait()
+	return err
+}
+
+func (w *FileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.config.Path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	w.opened = now()
+	return nil
+}
+
+// rotate - rename the file with the rotation time and open a new file. Compression and removal of the oldest
+// rotated files are done in the background. If the file cannot be rotated, the file is opened again so that writes
+// continue to the same file
+func (w *FileWriter) rotate() error {
+	err := w.file.Close()
+	w.file = nil
+	name := w.config.Path + "." + now().UTC().Format(rotateTimeFormat)
+	if err == nil {
+		err = os.Rename(w.config.Path, name)
+		if err == nil {
+			if err = w.open(); err != nil {
+				os.Rename(name, w.config.Path)
+			}
+		}
+	}
+	if err != nil {
+		if err1 := w.open(); err1 != nil {
+			log.Println(err1)
+		}
+		return err
+	}
+	w.compress.Add(1)
+	go func() {
+		defer w.compress.Done()
+		w.compressMu.Lock()
+		defer w.compressMu.Unlock()
+		if w.config.Compress {
+			if err := compressFile(name); err != nil {
+				log.Println(err)
+			}
+		}
+		w.removeBackups()
+	}()
+	return nil
+}
+
+func compressFile(name string) error {
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(name+compressedExt, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	if _, err = io.Copy(gz, in); err == nil {
+		err = gz.Close()
+	}
+	if err1 := out.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		os.Remove(name + compressedExt)
+		return err
+	}
+	in.Close()
+	return os.Remove(name)
+}
+
+// removeBackups - remove the oldest rotated files, so that MaxBackups are kept
+func (w *FileWriter) removeBackups() {
+	backups := w.Backups()
+	for len(backups) > w.config.MaxBackups {
+		os.Remove(backups[0])
+		backups = backups[1:]
+	}
+}
+
+// Backups - the rotated files, oldest first. Files still being compressed are included with their uncompressed name
+func (w *FileWriter) Backups() []string {
+	matches, _ := filepath.Glob(w.config.Path + ".*")
+	seen := make(map[string]bool)
+	var backups []string
+	for _, name := range matches {
+		key := strings.TrimSuffix(name, compressedExt)
+		if _, err := time.Parse(rotateTimeFormat, strings.TrimPrefix(key, w.config.Path+".")); err != nil || seen[key] {
+			continue
+		}
+		seen[key] = true
+		backups = append(backups, name)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return strings.TrimSuffix(backups[i], compressedExt) < strings.TrimSuffix(backups[j], compressedExt)
+	})
+	return backups
+}
@@ -0,0 +1,166 @@
+package accesslog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"github.com/gotemplates/host/accessdata"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func testClock() func() {
+	t := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time {
+		t = t.Add(time.Second)
+		return t
+	}
+	return func() { now = time.Now }
+}
+
+func testBackups(w *FileWriter) []string {
+	var names []string
+	for _, name := range w.Backups() {
+		names = append(names, filepath.Base(name))
+	}
+	return names
+}
+
+func testReadFile(name string) string {
+	f, err := os.Open(name)
+	if err != nil {
+		return err.Error()
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if filepath.Ext(name) == compressedExt {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err.Error()
+		}
+		r = gz
+	}
+	buf, _ := io.ReadAll(r)
+	return fmt.Sprintf("%q", buf)
+}
+
+func ExampleFileWriter_Write() {
+	defer testClock()()
+	dir, _ := os.MkdirTemp("", "accesslog")
+	defer os.RemoveAll(dir)
+
+	// Rotated by size, the 2 newest rotated files are kept
+	w, err := NewFileWriter(NewFileConfig(filepath.Join(dir, "access.log"), 20, 0, 2))
+	fmt.Printf("test: NewFileWriter() -> [err:%v]\n", err)
+	for i := 0; i < 8; i++ {
+		w.Write([]byte(fmt.Sprintf("line-%04v\n", i)))
+	}
+	w.Close()
+	backups := testBackups(w)
+	fmt.Printf("test: Write() -> %v\n", backups)
+	fmt.Printf("test: Write() -> [current:%v] [newest:%v]\n", testReadFile(filepath.Join(dir, "access.log")), testReadFile(filepath.Join(dir, backups[1])))
+
+	_, err = w.Write([]byte("closed\n"))
+	fmt.Printf("test: Write(closed) -> [err:%v]\n", err != nil)
+
+	//Output:
+	//test: NewFileWriter() -> [err:<nil>]
+	//test: Write() -> [access.log.20230301T120004.000000000.gz access.log.20230301T120006.000000000.gz]
+	//test: Write() -> [current:"line-0006\nline-0007\n"] [newest:"line-0004\nline-0005\n"]
+	//test: Write(closed) -> [err:true]
+
+}
+
+func ExampleFileWriter_Write_interval() {
+	defer testClock()()
+	dir, _ := os.MkdirTemp("", "accesslog")
+	defer os.RemoveAll(dir)
+
+	// Rotated every 2 seconds of the test clock, without compression
+	config := NewFileConfig(filepath.Join(dir, "access.log"), 0, time.Second*2, 0)
+	config.Compress = false
+	w, _ := NewFileWriter(config)
+	for i := 0; i < 3; i++ {
+		w.Write([]byte(fmt.Sprintf("line-%04v\n", i)))
+	}
+	w.Close()
+	backups := testBackups(w)
+	fmt.Printf("test: Write() -> %v [oldest:%v]\n", backups, testReadFile(filepath.Join(dir, backups[0])))
+
+	//Output:
+	//test: Write() -> [access.log.20230301T120004.000000000] [oldest:"line-0000\nline-0001\n"]
+
+}
+
+func ExampleFileWriter_Reopen() {
+	dir, _ := os.MkdirTemp("", "accesslog")
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "access.log")
+
+	w, _ := NewFileWriter(NewFileConfig(name, 0, 0, 0))
+	w.Write([]byte("before\n"))
+
+	// An external tool moves the file, writes continue to the moved file until it is reopened
+	os.Rename(name, name+".moved")
+	w.Write([]byte("moved\n"))
+	err := w.Reopen()
+	w.Write([]byte("after\n"))
+	w.Close()
+	fmt.Printf("test: Reopen() -> [err:%v] [moved:%v] [current:%v]\n", err, testReadFile(name+".moved"), testReadFile(name))
+
+	_, err = NewFileWriter(NewFileConfig("", 0, 0, 0))
+	fmt.Printf("test: NewFileWriter(\"\") -> [err:%v]\n", err)
+
+	//Output:
+	//test: Reopen() -> [err:<nil>] [moved:"before\nmoved\n"] [current:"after\n"]
+	//test: NewFileWriter("") -> [err:invalid configuration: file path is empty]
+
+}
+
+func ExampleFileOutputHandler() {
+	dir, _ := os.MkdirTemp("", "accesslog")
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "logs", "access.log")
+
+	err := RegisterFileOutput(NewFileConfig(name, 0, 0, 0))
+	fmt.Printf("test: RegisterFileOutput() -> [err:%v]\n", err)
+
+	start := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	InitIngressOperators([]accessdata.Operator{{Value: accessdata.TrafficOperator}, {Value: accessdata.RouteNameOperator}})
+	Write[FileOutputHandler, accessdata.JsonFormatter](accessdata.NewIngressEntry(start, 0, nil, nil, "", map[string]string{accessdata.ControllerName: "route-0"}))
+	Write[FileOutputHandler, accessdata.TextFormatter](accessdata.NewIngressEntry(start, 0, nil, nil, "", map[string]string{accessdata.ControllerName: "route-1"}))
+	Close()
+	InitIngressOperators(nil)
+	fmt.Printf("test: Write() -> [%v]\n", testReadFile(name))
+
+	//Output:
+	//test: RegisterFileOutput() -> [err:<nil>]
+	//test: Write() -> ["{\"traffic\":\"ingress\",\"route_name\":\"route-0\"}\ningress,route-1\n"]
+
+}
+
+func ExampleFileWriter_Rotate() {
+	defer testClock()()
+	dir, _ := os.MkdirTemp("", "accesslog")
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "access.log")
+
+	w, _ := NewFileWriter(NewFileConfig(name, 0, 0, 0))
+	w.Write([]byte("before\n"))
+
+	// The rotated name is a directory that is not empty, so the rename fails and the file is opened again
+	rotated := name + "." + time.Date(2023, 3, 1, 12, 0, 2, 0, time.UTC).Format(rotateTimeFormat)
+	os.MkdirAll(filepath.Join(rotated, "dir"), 0755)
+	err := w.Rotate()
+	fmt.Printf("test: Rotate() -> [err:%v]\n", err != nil)
+
+	_, err = w.Write([]byte("after\n"))
+	w.Close()
+	fmt.Printf("test: Write() -> [err:%v] [current:%v]\n", err, testReadFile(name))
+
+	//Output:
+	//test: Rotate() -> [err:true]
+	//test: Write() -> [err:<nil>] [current:"before\nafter\n"]
+
+}
@@ -4,6 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/gotemplates/host/accessdata"
+	"github.com/gotemplates/host/decode"
+)
+
+const (
+	OperatorsTableName = "operators"
 )
 
 // InitIngressOperators - allows configuration of access accesslog attributes for ingress traffic
@@ -62,3 +67,26 @@ func ReadOperators(buf []byte) ([]accessdata.Operator, error) {
 	err1 := json.Unmarshal(buf, &operators)
 	return operators, err1
 }
+
+// ReadOperatorsYAML - read the operators from a YAML sequence, errors include the line and column of the operator
+func ReadOperatorsYAML(buf []byte) ([]accessdata.Operator, error) {
+	doc, err := decode.YAML(buf)
+	if err != nil {
+		return nil, err
+	}
+	var operators []accessdata.Operator
+	err = doc.Decode("", &operators)
+	return operators, err
+}
+
+// ReadOperatorsTOML - read the operators from a TOML array of tables named operators, [[operators]], errors
+// include the line and column of the operator
+func ReadOperatorsTOML(buf []byte) ([]accessdata.Operator, error) {
+	doc, err := decode.TOML(buf)
+	if err != nil {
+		return nil, err
+	}
+	var operators []accessdata.Operator
+	err = doc.Decode(OperatorsTableName, &operators)
+	return operators, err
+}
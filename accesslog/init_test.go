@@ -0,0 +1,31 @@
+package accesslog
+
+import (
+	"fmt"
+)
+
+func ExampleReadOperatorsYAML() {
+	operators, err := ReadOperatorsYAML([]byte("- value: '%START_TIME%'\n- name: duration_ms\n  value: '%DURATION%'\n"))
+	fmt.Printf("test: ReadOperatorsYAML() -> [err:%v] %v\n", err, operators)
+
+	_, err = ReadOperatorsYAML([]byte("- value: '%START_TIME%'\n- name: [duration_ms]\n"))
+	fmt.Printf("test: ReadOperatorsYAML(name) -> [err:%v]\n", err)
+
+	//Output:
+	//test: ReadOperatorsYAML() -> [err:<nil>] [{ %START_TIME%} {duration_ms %DURATION%}]
+	//test: ReadOperatorsYAML(name) -> [err:line 2, column 3: cannot unmarshal array into [1].name of type string]
+
+}
+
+func ExampleReadOperatorsTOML() {
+	operators, err := ReadOperatorsTOML([]byte("[[operators]]\nValue = \"%START_TIME%\"\n\n[[operators]]\nName = \"duration_ms\"\nValue = \"%DURATION%\"\n"))
+	fmt.Printf("test: ReadOperatorsTOML() -> [err:%v] %v\n", err, operators)
+
+	_, err = ReadOperatorsTOML([]byte("[[operators]]\nValue = 5\n"))
+	fmt.Printf("test: ReadOperatorsTOML(value) -> [err:%v]\n", err)
+
+	//Output:
+	//test: ReadOperatorsTOML() -> [err:<nil>] [{ %START_TIME%} {duration_ms %DURATION%}]
+	//test: ReadOperatorsTOML(value) -> [err:line 2, column 1: cannot unmarshal number into operators[0].Value of type string]
+
+}
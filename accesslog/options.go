@@ -10,6 +10,11 @@ func SetEgressLogStatus(enabled bool) {
 	opt.egress = enabled
 }
 
+// SetMirrorLogStatus - enable/disable mirror logging
+func SetMirrorLogStatus(enabled bool) {
+	opt.mirror = enabled
+}
+
 // SetPingLogStatus - enable/disable ping logging
 func SetPingLogStatus(enabled bool) {
 	opt.ping = enabled
@@ -19,6 +24,7 @@ type options struct {
 	ingress bool
 	egress  bool
 	ping    bool
+	mirror  bool
 }
 
 var opt options
@@ -27,4 +33,5 @@ func init() {
 	opt.ingress = true
 	opt.egress = true
 	opt.ping = true
+	opt.mirror = true
 }
@@ -32,8 +32,11 @@ func Write[O OutputHandler, F accessdata.Formatter](entry *accessdata.Entry) {
 			return
 		}
 		operators = ingressOperators
-	case accessdata.EgressTraffic:
-		if !opt.egress {
+	case accessdata.EgressTraffic, accessdata.MirrorTraffic:
+		if entry.Traffic == accessdata.EgressTraffic && !opt.egress {
+			return
+		}
+		if entry.Traffic == accessdata.MirrorTraffic && !opt.mirror {
 			return
 		}
 		operators = egressOperators
@@ -187,3 +187,25 @@ func _Example_Log_State() {
 	//Output:
 	//fail
 }
+
+func ExampleLog_Mirror() {
+	start := time.Now()
+
+	err := InitEgressOperators([]accessdata.Operator{{Value: accessdata.StartTimeOperator}, {Name: "duration", Value: accessdata.DurationOperator},
+		{Value: accessdata.TrafficOperator}, {Value: accessdata.RouteNameOperator}})
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+	var start1 time.Time
+	entry := accessdata.NewEntry(accessdata.MirrorTraffic, start1, time.Since(start), nil, nil, "", map[string]string{accessdata.ControllerName: "handler-route"})
+	Write[TestOutputHandler, accessdata.JsonFormatter](entry)
+
+	SetMirrorLogStatus(false)
+	Write[TestOutputHandler, accessdata.JsonFormatter](entry)
+	SetMirrorLogStatus(true)
+
+	//Output:
+	//test: Write() -> [{"start_time":"0001-01-01 00:00:00.000000","duration":0,"traffic":"mirror","route_name":"handler-route"}]
+
+}
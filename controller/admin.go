@@ -0,0 +1,331 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/time/rate"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	AdminRoutesPath   = "/routes"
+	AdminMaxPatchSize = 64 * 1024
+)
+
+// AdminAuthorize - determine if a request can change a controller, and the user recorded in the audit log
+type AdminAuthorize func(req *http.Request) (user string, ok bool)
+
+// RouteState - a route and the state of its controllers
+type RouteState struct {
+	Traffic string
+	Name    string
+	State   map[string]string
+}
+
+// RoutePatch - changes to the controllers of a route, a nil field is not changed
+type RoutePatch struct {
+	Timeout     *TimeoutPatch
+	RateLimiter *RateLimiterPatch
+	Retry       *RetryPatch
+	Proxy       *EnablePatch
+	Failover    *EnablePatch
+}
+
+type TimeoutPatch struct {
+	Duration string
+}
+
+type RateLimiterPatch struct {
+	Limit *rate.Limit
+	Burst *int
+}
+
+type RetryPatch struct {
+	Enabled *bool
+	Limit   *rate.Limit
+	Burst   *int
+}
+
+type EnablePatch struct {
+	Enabled bool
+}
+
+type adminHandler struct {
+	tables    map[string]*table
+	authorize AdminAuthorize
+}
+
+// NewAdminHandler - an http.Handler for the routes of the IngressTable and EgressTable. The handler should be
+// mounted with http.StripPrefix so that request paths start with AdminRoutesPath:
+//
+//	GET   /routes                   - all routes
+//	GET   /routes/{traffic}         - the routes of the ingress or egress table
+//	GET   /routes/{traffic}/{name}  - a route
+//	PATCH /routes/{traffic}/{name}  - change a route with a RoutePatch, only if authorized
+//
+// A nil authorize function allows reads only. Each change is recorded with the audit function.
+func NewAdminHandler(authorize AdminAuthorize) http.Handler {
+	return newAdminHandler(IngressTable.(*table), EgressTable.(*table), authorize)
+}
+
+func newAdminHandler(ingress, egress *table, authorize AdminAuthorize) *adminHandler {
+	h := new(adminHandler)
+	h.tables = map[string]*table{IngressTraffic: ingress, EgressTraffic: egress}
+	h.authorize = authorize
+	return h
+}
+
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	if path != AdminRoutesPath && !strings.HasPrefix(path, AdminRoutesPath+"/") {
+		writeAdminError(w, http.StatusNotFound, errors.New(fmt.Sprintf("invalid argument: path is not found [%v]", req.URL.Path)))
+		return
+	}
+	traffic, name, _ := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(path, AdminRoutesPath), "/"), "/")
+	if traffic != "" && h.tables[traffic] == nil {
+		writeAdminError(w, http.StatusNotFound, errors.New(fmt.Sprintf("invalid argument: traffic is invalid [%v]", traffic)))
+		return
+	}
+	switch req.Method {
+	case http.MethodGet:
+		h.get(w, traffic, name)
+	case http.MethodPatch:
+		if name == "" {
+			w.Header().Set("Allow", http.MethodGet)
+			writeAdminError(w, http.StatusMethodNotAllowed, errors.New(fmt.Sprintf("invalid argument: method is not allowed [%v]", req.Method)))
+			return
+		}
+		h.patch(w, req, traffic, name)
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPatch)
+		writeAdminError(w, http.StatusMethodNotAllowed, errors.New(fmt.Sprintf("invalid argument: method is not allowed [%v]", req.Method)))
+	}
+}
+
+func (h *adminHandler) get(w http.ResponseWriter, traffic, name string) {
+	if name != "" {
+		ctrl := h.tables[traffic].controller(name)
+		if ctrl == nil {
+			writeAdminError(w, http.StatusNotFound, errors.New(fmt.Sprintf("invalid argument: route is not found [%v]", name)))
+			return
+		}
+		writeAdminJson(w, http.StatusOK, RouteState{Traffic: traffic, Name: name, State: adminState(ctrl)})
+		return
+	}
+	routes := []RouteState{}
+	for _, t := range []string{IngressTraffic, EgressTraffic} {
+		if traffic != "" && traffic != t {
+			continue
+		}
+		for _, ctrl := range h.tables[t].routes() {
+			routes = append(routes, RouteState{Traffic: t, Name: ctrl.name, State: adminState(ctrl)})
+		}
+	}
+	writeAdminJson(w, http.StatusOK, routes)
+}
+
+func (h *adminHandler) patch(w http.ResponseWriter, req *http.Request, traffic, name string) {
+	if h.authorize == nil {
+		writeAdminError(w, http.StatusForbidden, errors.New("invalid argument: changes are not enabled"))
+		return
+	}
+	user, ok := h.authorize(req)
+	if !ok {
+		writeAdminError(w, http.StatusForbidden, errors.New("invalid argument: request is not authorized"))
+		return
+	}
+	ctrl := h.tables[traffic].controller(name)
+	if ctrl == nil {
+		writeAdminError(w, http.StatusNotFound, errors.New(fmt.Sprintf("invalid argument: route is not found [%v]", name)))
+		return
+	}
+	var patch RoutePatch
+	dec := json.NewDecoder(http.MaxBytesReader(w, req.Body, AdminMaxPatchSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&patch); err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	changes, errs := newChanges(ctrl, patch)
+	if len(errs) > 0 {
+		writeAdminError(w, http.StatusBadRequest, errs...)
+		return
+	}
+	// All changes are validated before any are applied
+	now := time.Now()
+	for _, c := range changes {
+		c.apply()
+		defaultAuditFn(AuditEntry{Time: now, User: user, RequestId: req.Header.Get(RequestIdHeaderName), Traffic: traffic,
+			Route: name, Field: c.field, Old: c.old, New: c.new})
+	}
+	writeAdminJson(w, http.StatusOK, RouteState{Traffic: traffic, Name: name, State: adminState(h.tables[traffic].controller(name))})
+}
+
+// change - a validated change to a controller
+type change struct {
+	field string
+	old   string
+	new   string
+	apply func()
+}
+
+func newChanges(ctrl *controller, patch RoutePatch) ([]change, []error) {
+	var changes []change
+	var errs []error
+	if patch.Timeout != nil {
+		duration, err := ConvertDuration(patch.Timeout.Duration)
+		switch {
+		case ctrl.timeout == nil:
+			errs = append(errs, errors.New("invalid argument: route does not have a timeout"))
+		case err != nil:
+			errs = append(errs, errors.New(fmt.Sprintf("invalid argument: timeout duration is invalid [%v]", err)))
+		case duration <= 0:
+			errs = append(errs, errors.New("invalid argument: timeout duration is <= 0"))
+		case duration != ctrl.timeout.Duration():
+			t := ctrl.timeout
+			changes = append(changes, change{field: "timeout.duration", old: t.Duration().String(), new: duration.String(), apply: func() { t.SetTimeout(duration) }})
+		}
+	}
+	if p := patch.RateLimiter; p != nil {
+		switch {
+		case ctrl.rateLimiter == nil:
+			errs = append(errs, errors.New("invalid argument: route does not have a rate limiter"))
+		case p.Limit != nil && *p.Limit < 0:
+			errs = append(errs, errors.New("invalid argument: rate limiter limit is < 0"))
+		case p.Burst != nil && *p.Burst < 0:
+			errs = append(errs, errors.New("invalid argument: rate limiter burst is < 0"))
+		default:
+			r := ctrl.rateLimiter
+			limit, burst := r.LimitAndBurst()
+			newLimit, newBurst := patchLimiter(limit, burst, p.Limit, p.Burst)
+			if newLimit != limit || newBurst != burst {
+				changes = append(changes, change{field: "rateLimiter", old: fmtLimiter(limit, burst), new: fmtLimiter(newLimit, newBurst), apply: func() { r.SetRateLimiter(newLimit, newBurst) }})
+			}
+		}
+	}
+	if p := patch.Retry; p != nil {
+		switch {
+		case ctrl.retry == nil:
+			errs = append(errs, errors.New("invalid argument: route does not have a retry"))
+		case p.Limit != nil && *p.Limit < 0:
+			errs = append(errs, errors.New("invalid argument: retry limit is < 0"))
+		case p.Burst != nil && *p.Burst < 0:
+			errs = append(errs, errors.New("invalid argument: retry burst is < 0"))
+		default:
+			r := ctrl.retry
+			if p.Enabled != nil && *p.Enabled != r.IsEnabled() {
+				changes = append(changes, enableChange("retry.enabled", *p.Enabled, r.Enable, r.Disable))
+			}
+			limit, burst := r.LimitAndBurst()
+			newLimit, newBurst := patchLimiter(limit, burst, p.Limit, p.Burst)
+			if newLimit != limit || newBurst != burst {
+				changes = append(changes, change{field: "retry.rateLimiter", old: fmtLimiter(limit, burst), new: fmtLimiter(newLimit, newBurst), apply: func() { r.SetRateLimiter(newLimit, newBurst) }})
+			}
+		}
+	}
+	if patch.Proxy != nil {
+		switch {
+		case ctrl.proxy == nil:
+			errs = append(errs, errors.New("invalid argument: route does not have a proxy"))
+		case patch.Proxy.Enabled != ctrl.proxy.IsEnabled():
+			changes = append(changes, enableChange("proxy.enabled", patch.Proxy.Enabled, ctrl.proxy.Enable, ctrl.proxy.Disable))
+		}
+	}
+	if patch.Failover != nil {
+		switch {
+		case ctrl.failover == nil:
+			errs = append(errs, errors.New("invalid argument: route does not have a failover"))
+		case patch.Failover.Enabled != ctrl.failover.IsEnabled():
+			changes = append(changes, enableChange("failover.enabled", patch.Failover.Enabled, ctrl.failover.Enable, ctrl.failover.Disable))
+		}
+	}
+	return changes, errs
+}
+
+func enableChange(field string, enabled bool, enable, disable func()) change {
+	c := change{field: field, old: strconv.FormatBool(!enabled), new: strconv.FormatBool(enabled), apply: disable}
+	if enabled {
+		c.apply = enable
+	}
+	return c
+}
+
+func patchLimiter(limit rate.Limit, burst int, newLimit *rate.Limit, newBurst *int) (rate.Limit, int) {
+	if newLimit != nil {
+		limit = *newLimit
+	}
+	if newBurst != nil {
+		burst = *newBurst
+	}
+	return limit, burst
+}
+
+func fmtLimiter(limit rate.Limit, burst int) string {
+	if limit == rate.Inf {
+		limit = RateLimitInfValue
+	}
+	return fmt.Sprintf("%v/%v", limit, burst)
+}
+
+// adminState - the controller state, with the state of the controllers that is otherwise only logged for egress
+// traffic. Retry is the enablement of the retry controller
+func adminState(c *controller) map[string]string {
+	state := c.state()
+	failoverState(state, c.failover)
+	proxyState(state, c.proxy)
+	circuitBreakerState(state, c.circuitBreaker)
+	retryState(state, c.retry, false)
+	if c.retry != nil {
+		state[RetryName] = strconv.FormatBool(c.retry.IsEnabled())
+	}
+	priorityState(state, c.priority, nil)
+	return state
+}
+
+// routes - the named controllers of the table, sorted by name
+func (t *table) routes() []*controller {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ctrls := make([]*controller, 0, len(t.controllers))
+	for _, ctrl := range t.controllers {
+		ctrls = append(ctrls, ctrl)
+	}
+	sort.Slice(ctrls, func(i, j int) bool { return ctrls[i].name < ctrls[j].name })
+	return ctrls
+}
+
+// controller - a named controller, without the default controller of LookupByName
+func (t *table) controller(name string) *controller {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.controllers[name]
+}
+
+func writeAdminJson(w http.ResponseWriter, statusCode int, v any) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	// Error messages include comparisons, such as <= 0
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		statusCode = http.StatusInternalServerError
+		buf.Reset()
+		enc.Encode(struct{ Errors []string }{[]string{err.Error()}})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(buf.Bytes())
+}
+
+func writeAdminError(w http.ResponseWriter, statusCode int, errs ...error) {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	writeAdminJson(w, statusCode, struct{ Errors []string }{msgs})
+}
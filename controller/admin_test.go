@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func adminRequest(h http.Handler, method, uri, body string) string {
+	req := httptest.NewRequest(method, uri, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer admin-token")
+	req.Header.Set(RequestIdHeaderName, "123-456")
+	if method == http.MethodGet {
+		req.Header.Del("Authorization")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return fmt.Sprintf("[status:%v] %v", rec.Code, strings.TrimSpace(rec.Body.String()))
+}
+
+func Example_AdminHandler() {
+	ingress := newTable(false, true)
+	egress := newTable(true, true)
+	errs := egress.AddController(newRoute("google-search", NewTimeoutConfig(time.Millisecond*500, 0), NewRateLimiterConfig(100, 10, 0),
+		NewRetryConfig([]int{503}, 10, 5, 0), NewProxyConfig(false, "http://localhost:8080"), NewFailoverConfig(failoverFn)))
+	fmt.Printf("test: AddController(egress) -> %v\n", errs)
+	errs = ingress.AddController(newRoute("twitter", NewRateLimiterConfig(50, 5, 0)))
+	fmt.Printf("test: AddController(ingress) -> %v\n", errs)
+
+	SetAuditFn(func(entry AuditEntry) {
+		fmt.Printf("test: Audit() -> [user:%v] [request-id:%v] [%v/%v] [%v] [old:%v] [new:%v]\n", entry.User, entry.RequestId, entry.Traffic, entry.Route, entry.Field, entry.Old, entry.New)
+	})
+	h := newAdminHandler(ingress, egress, func(req *http.Request) (string, bool) {
+		return "admin", req.Header.Get("Authorization") == "Bearer admin-token"
+	})
+
+	fmt.Printf("test: GET /routes -> %v\n", adminRequest(h, http.MethodGet, "/routes", ""))
+	fmt.Printf("test: GET /routes/ingress/twitter -> %v\n", adminRequest(h, http.MethodGet, "/routes/ingress/twitter", ""))
+	fmt.Printf("test: GET /routes/egress/twitter -> %v\n", adminRequest(h, http.MethodGet, "/routes/egress/twitter", ""))
+	fmt.Printf("test: GET /routes/both -> %v\n", adminRequest(h, http.MethodGet, "/routes/both", ""))
+
+	patch := `{"Timeout":{"Duration":"1s"},"RateLimiter":{"Burst":20},"Retry":{"Enabled":false},"Proxy":{"Enabled":true},"Failover":{"Enabled":true}}`
+	fmt.Printf("test: PATCH /routes/egress/google-search -> %v\n", adminRequest(h, http.MethodPatch, "/routes/egress/google-search", patch))
+
+	c := egress.controller("google-search")
+	limit, burst := c.rateLimiter.LimitAndBurst()
+	fmt.Printf("test: controller(google-search) -> [timeout:%v] [limit:%v] [burst:%v] [retry:%v] [proxy:%v] [failover:%v]\n",
+		c.timeout.Duration(), limit, burst, c.retry.IsEnabled(), c.proxy.IsEnabled(), c.failover.IsEnabled())
+
+	patch = `{"Timeout":{"Duration":"-1s"},"RateLimiter":{"Limit":-1},"Proxy":{"Enabled":false}}`
+	fmt.Printf("test: PATCH(invalid) -> %v\n", adminRequest(h, http.MethodPatch, "/routes/egress/google-search", patch))
+	fmt.Printf("test: PATCH(no timeout) -> %v\n", adminRequest(h, http.MethodPatch, "/routes/ingress/twitter", `{"Timeout":{"Duration":"1s"}}`))
+	fmt.Printf("test: PATCH(unknown field) -> %v\n", adminRequest(h, http.MethodPatch, "/routes/ingress/twitter", `{"RateLimiter":{"Rate":1}}`))
+
+	req := httptest.NewRequest(http.MethodPatch, "/routes/ingress/twitter", strings.NewReader(`{"RateLimiter":{"Limit":1}}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	fmt.Printf("test: PATCH(unauthorized) -> [status:%v] %v\n", rec.Code, strings.TrimSpace(rec.Body.String()))
+
+	fmt.Printf("test: DELETE /routes/ingress/twitter -> %v\n", adminRequest(h, http.MethodDelete, "/routes/ingress/twitter", ""))
+	fmt.Printf("test: PATCH(read only) -> %v\n", adminRequest(newAdminHandler(ingress, egress, nil), http.MethodPatch, "/routes/ingress/twitter", `{}`))
+
+	//Output:
+	//test: AddController(egress) -> []
+	//test: AddController(ingress) -> []
+	//test: GET /routes -> [status:200] [{"Traffic":"ingress","Name":"twitter","State":{"burst":"5","circuitBreaker":"","concurrencyLimit":"-1","failover":"","inFlight":"-1","name":"twitter","priority":"","proxy":"","rateLimit":"50","retry":"","retryBurst":"-1","retryRateLimit":"-1","timeout":"-1"}},{"Traffic":"egress","Name":"google-search","State":{"burst":"10","circuitBreaker":"","concurrencyLimit":"-1","failover":"false","inFlight":"-1","name":"google-search","priority":"","proxy":"false","rateLimit":"100","retry":"true","retryBurst":"5","retryRateLimit":"10","timeout":"500"}}]
+	//test: GET /routes/ingress/twitter -> [status:200] {"Traffic":"ingress","Name":"twitter","State":{"burst":"5","circuitBreaker":"","concurrencyLimit":"-1","failover":"","inFlight":"-1","name":"twitter","priority":"","proxy":"","rateLimit":"50","retry":"","retryBurst":"-1","retryRateLimit":"-1","timeout":"-1"}}
+	//test: GET /routes/egress/twitter -> [status:404] {"Errors":["invalid argument: route is not found [twitter]"]}
+	//test: GET /routes/both -> [status:404] {"Errors":["invalid argument: traffic is invalid [both]"]}
+	//test: Audit() -> [user:admin] [request-id:123-456] [egress/google-search] [timeout.duration] [old:500ms] [new:1s]
+	//test: Audit() -> [user:admin] [request-id:123-456] [egress/google-search] [rateLimiter] [old:100/10] [new:100/20]
+	//test: Audit() -> [user:admin] [request-id:123-456] [egress/google-search] [retry.enabled] [old:true] [new:false]
+	//test: Audit() -> [user:admin] [request-id:123-456] [egress/google-search] [proxy.enabled] [old:false] [new:true]
+	//test: Audit() -> [user:admin] [request-id:123-456] [egress/google-search] [failover.enabled] [old:false] [new:true]
+	//test: PATCH /routes/egress/google-search -> [status:200] {"Traffic":"egress","Name":"google-search","State":{"burst":"20","circuitBreaker":"","concurrencyLimit":"-1","failover":"true","inFlight":"-1","name":"google-search","priority":"","proxy":"true","rateLimit":"100","retry":"false","retryBurst":"5","retryRateLimit":"10","timeout":"1000"}}
+	//test: controller(google-search) -> [timeout:1s] [limit:100] [burst:20] [retry:false] [proxy:true] [failover:true]
+	//test: PATCH(invalid) -> [status:400] {"Errors":["invalid argument: timeout duration is <= 0","invalid argument: rate limiter limit is < 0"]}
+	//test: PATCH(no timeout) -> [status:400] {"Errors":["invalid argument: route does not have a timeout"]}
+	//test: PATCH(unknown field) -> [status:400] {"Errors":["json: unknown field \"Rate\""]}
+	//test: PATCH(unauthorized) -> [status:403] {"Errors":["invalid argument: request is not authorized"]}
+	//test: DELETE /routes/ingress/twitter -> [status:405] {"Errors":["invalid argument: method is not allowed [DELETE]"]}
+	//test: PATCH(read only) -> [status:403] {"Errors":["invalid argument: changes are not enabled"]}
+
+}
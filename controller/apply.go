@@ -9,6 +9,8 @@ import (
 
 const (
 	StatusDeadlineExceeded = 4
+	StatusInternal         = 13
+	StatusUnavailable      = 14
 	StatusRateLimited      = 94
 )
 
@@ -25,6 +27,28 @@ func EgressApply(ctx context.Context, statusCode func() int, uri, requestId, met
 		limited = true
 		statusFlags = RateLimitFlag
 	}
+	cbc, breaker := act.CircuitBreaker()
+	probe := false
+	if !limited && breaker {
+		var ok bool
+		if ok, probe = cbc.Allow(); !ok {
+			limited = true
+			statusFlags = CircuitOpenFlag
+		}
+	}
+	acquired := false
+	if bhc, ok := act.Bulkhead(); ok && !limited {
+		if acquired = bhc.Acquire(ctx); !acquired {
+			limited = true
+			statusFlags = BulkheadFlag
+			if breaker {
+				cbc.Cancel(probe)
+			}
+		}
+	}
+	// The method policy is decided before the call, a failure that would be retried is flagged as not idempotent
+	rc := act.t().retry
+	retryDenied := rc != nil && rc.IsEnabled() && !rc.IsRetryableMethod(method, "")
 	if !limited {
 		if toc, ok := act.Timeout(); ok {
 			newCtx, cancelCtx = context.WithTimeout(ctx, toc.Duration())
@@ -34,11 +58,28 @@ func EgressApply(ctx context.Context, statusCode func() int, uri, requestId, met
 		if cancelCtx != nil {
 			cancelCtx()
 		}
+		if acquired {
+			bhc, _ := act.Bulkhead()
+			bhc.Release()
+		}
 		//code := (*status).Code()
 		code := statusCode()
 		if code == StatusDeadlineExceeded {
 			statusFlags = UpstreamTimeoutFlag
 		}
+		if retryDenied && !limited && rc.isRetryableCause(code, nil) {
+			statusFlags = NotIdempotentFlag
+		}
+		if breaker && !limited {
+			cbc.Record(probe, !isFailure(code))
+		}
+		if fc, ok := act.Failover(); ok && !limited {
+			fc.Record(isFailure(code), code == StatusDeadlineExceeded)
+		}
 		act.LogEgress(start, time.Since(start), code, uri, requestId, method, statusFlags)
 	}, newCtx, limited
 }
+
+func isFailure(code int) bool {
+	return code == StatusDeadlineExceeded || code == StatusInternal || code == StatusUnavailable
+}
@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	RoundRobinStrategy     = "round-robin"
+	RandomStrategy         = "random"
+	LeastRequestStrategy   = "least-request"
+	ConsistentHashStrategy = "consistent-hash"
+
+	PrimaryBranch = "primary"
+	CanaryBranch  = "canary"
+
+	DefaultMaxFailures  = 5
+	DefaultEjectionTime = time.Second * 30
+
+	hashReplicas = 100
+)
+
+// Endpoint - upstream endpoint, the Url is a proxy pattern
+type Endpoint struct {
+	Url    string
+	Weight int // 0 is a weight of 1
+}
+
+type endpointState struct {
+	Endpoint
+	inFlight     int
+	failures     int
+	ejectedUntil time.Time
+	current      int // smooth weighted round-robin
+}
+
+// balancer - endpoint state, shared between clones so that configuration updates do not reset ejections
+type balancer struct {
+	mu        sync.Mutex
+	endpoints []*endpointState
+	ring      []hashPoint
+	rand      *rand.Rand
+}
+
+type hashPoint struct {
+	hash  uint32
+	index int
+}
+
+func newBalancer(endpoints []Endpoint) *balancer {
+	b := new(balancer)
+	b.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i, e := range endpoints {
+		if e.Weight <= 0 {
+			e.Weight = 1
+		}
+		b.endpoints = append(b.endpoints, &endpointState{Endpoint: e})
+		for r := 0; r < hashReplicas*e.Weight; r++ {
+			b.ring = append(b.ring, hashPoint{hash: hash(e.Url + "#" + strconv.Itoa(r)), index: i})
+		}
+	}
+	sort.Slice(b.ring, func(i, j int) bool { return b.ring[i].hash < b.ring[j].hash })
+	return b
+}
+
+func validateEndpoints(endpoints []Endpoint, strategy, hashHeader string) error {
+	for i, e := range endpoints {
+		if _, err := url.Parse(e.Url); err != nil || e.Url == "" {
+			return newFieldError(fmt.Sprintf("endpoints[%v].url", i), errors.New(fmt.Sprintf("invalid configuration: Proxy endpoint is invalid [%v]", e.Url)))
+		}
+		if e.Weight < 0 {
+			return newFieldError(fmt.Sprintf("endpoints[%v].weight", i), errors.New(fmt.Sprintf("invalid configuration: Proxy endpoint weight is < 0 [%v]", e.Url)))
+		}
+	}
+	switch strategy {
+	case "", RoundRobinStrategy, RandomStrategy, LeastRequestStrategy:
+	case ConsistentHashStrategy:
+		if hashHeader == "" {
+			return newFieldError("hashHeader", errors.New("invalid configuration: Proxy hash header is empty for consistent-hash strategy"))
+		}
+	default:
+		return newFieldError("strategy", errors.New(fmt.Sprintf("invalid configuration: Proxy strategy is invalid [%v]", strategy)))
+	}
+	return nil
+}
+
+func hash(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
+
+func (b *balancer) intn(n int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rand.Intn(n)
+}
+
+// selectEndpoint - select a healthy endpoint, all endpoints are candidates when every endpoint is ejected. The index
+// of the endpoint is returned
+func (b *balancer) selectEndpoint(strategy, key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	var healthy []int
+	for i, e := range b.endpoints {
+		if now.After(e.ejectedUntil) {
+			healthy = append(healthy, i)
+		}
+	}
+	if len(healthy) == 0 {
+		for i := range b.endpoints {
+			healthy = append(healthy, i)
+		}
+	}
+	var i int
+	switch strategy {
+	case RandomStrategy:
+		i = b.random(healthy)
+	case LeastRequestStrategy:
+		i = b.leastRequest(healthy)
+	case ConsistentHashStrategy:
+		if key == "" {
+			i = b.random(healthy)
+		} else {
+			i = b.consistentHash(healthy, key)
+		}
+	default:
+		i = b.roundRobin(healthy)
+	}
+	b.endpoints[i].inFlight++
+	return i
+}
+
+// roundRobin - https://github.com/phusion/nginx/commit/27e94984486058d73157038f7950a0a36ecc6e35
+func (b *balancer) roundRobin(healthy []int) int {
+	best := -1
+	total := 0
+	for _, i := range healthy {
+		e := b.endpoints[i]
+		e.current += e.Weight
+		total += e.Weight
+		if best == -1 || e.current > b.endpoints[best].current {
+			best = i
+		}
+	}
+	b.endpoints[best].current -= total
+	return best
+}
+
+func (b *balancer) random(healthy []int) int {
+	total := 0
+	for _, i := range healthy {
+		total += b.endpoints[i].Weight
+	}
+	n := b.rand.Intn(total)
+	for _, i := range healthy {
+		n -= b.endpoints[i].Weight
+		if n < 0 {
+			return i
+		}
+	}
+	return healthy[0]
+}
+
+func (b *balancer) leastRequest(healthy []int) int {
+	best := -1
+	for _, i := range healthy {
+		e := b.endpoints[i]
+		if best == -1 || e.inFlight*b.endpoints[best].Weight < b.endpoints[best].inFlight*e.Weight {
+			best = i
+		}
+	}
+	return best
+}
+
+// consistentHash - the first healthy endpoint clockwise from the key on the ring
+func (b *balancer) consistentHash(healthy []int, key string) int {
+	ok := make(map[int]bool, len(healthy))
+	for _, i := range healthy {
+		ok[i] = true
+	}
+	h := hash(key)
+	start := sort.Search(len(b.ring), func(i int) bool { return b.ring[i].hash >= h })
+	for n := 0; n < len(b.ring); n++ {
+		p := b.ring[(start+n)%len(b.ring)]
+		if ok[p.index] {
+			return p.index
+		}
+	}
+	return healthy[0]
+}
+
+// done - record the outcome of a request sent to the endpoint at index, ejecting the endpoint after maxFailures
+// consecutive failures
+func (b *balancer) done(index int, success bool, maxFailures int, ejectionTime time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if index < 0 || index >= len(b.endpoints) {
+		return
+	}
+	e := b.endpoints[index]
+	if e.inFlight > 0 {
+		e.inFlight--
+	}
+	if success {
+		e.failures = 0
+		return
+	}
+	e.failures++
+	if e.failures >= maxFailures {
+		e.failures = 0
+		e.ejectedUntil = time.Now().Add(ejectionTime)
+	}
+}
+
+// ejected - endpoints that are currently ejected
+func (b *balancer) ejected() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var urls []string
+	now := time.Now()
+	for _, e := range b.endpoints {
+		if now.Before(e.ejectedUntil) {
+			urls = append(urls, e.Url)
+		}
+	}
+	return urls
+}
+
+func hashKey(req *http.Request, header string) string {
+	if req == nil || header == "" {
+		return ""
+	}
+	return req.Header.Get(header)
+}
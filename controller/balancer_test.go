@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var testEndpoints = []Endpoint{{Url: "http://host-a:8080", Weight: 3}, {Url: "http://host-b:8080", Weight: 1}}
+
+func countSelected(p *proxy, req *http.Request, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		_, endpoint, _ := p.Select(req)
+		p.Done(endpoint, true)
+		counts[p.endpoints[endpoint].Url]++
+	}
+	return counts
+}
+
+func Example_Proxy_Select() {
+	t := newTable(true, false)
+	req, _ := http.NewRequest("GET", "https://www.google.com/search?q=test", nil)
+
+	p := newProxy("test-route", t, &ProxyConfig{Enabled: true, Endpoints: testEndpoints})
+	uri, endpoint, _ := p.Select(req)
+	p.Done(endpoint, true)
+	fmt.Printf("test: Select() -> [uri:%v] [endpoint:%v]\n", uri, p.endpoints[endpoint].Url)
+	fmt.Printf("test: Select(round-robin) -> %v\n", countSelected(p, req, 8))
+
+	p = newProxy("test-route", t, &ProxyConfig{Enabled: true, Endpoints: testEndpoints, Strategy: RandomStrategy})
+	counts := countSelected(p, req, 1000)
+	fmt.Printf("test: Select(random) -> [weighted:%v]\n", counts["http://host-a:8080"] > counts["http://host-b:8080"])
+
+	p = newProxy("test-route", t, &ProxyConfig{Enabled: true, Endpoints: testEndpoints, Strategy: LeastRequestStrategy})
+	_, e1, _ := p.Select(req)
+	_, e2, _ := p.Select(req)
+	_, e3, _ := p.Select(req)
+	_, e4, _ := p.Select(req)
+	fmt.Printf("test: Select(least-request) -> [%v] [%v] [%v] [%v]\n", e1, e2, e3, e4)
+
+	p = newProxy("test-route", t, &ProxyConfig{Enabled: true, Endpoints: []Endpoint{{Url: "http://host-a:8080"}, {Url: "http://host-a:8080"}}, MaxFailures: 1})
+	_, e1, _ = p.Select(req)
+	_, e2, _ = p.Select(req)
+	p.Done(e2, false)
+	p.Done(e1, true)
+	_, e3, _ = p.Select(req)
+	fmt.Printf("test: Done(duplicate-url) -> [selected:%v %v] [next:%v]\n", e1, e2, e3)
+
+	p = newProxy("test-route", t, &ProxyConfig{Enabled: true, Endpoints: testEndpoints, Strategy: ConsistentHashStrategy, HashHeader: "X-User"})
+	req.Header.Set("X-User", "user-1")
+	counts = countSelected(p, req, 10)
+	fmt.Printf("test: Select(consistent-hash) -> [endpoints:%v]\n", len(counts))
+
+	//Output:
+	//test: Select() -> [uri:http://host-a:8080/search?q=test] [endpoint:http://host-a:8080]
+	//test: Select(round-robin) -> map[http://host-a:8080:6 http://host-b:8080:2]
+	//test: Select(random) -> [weighted:true]
+	//test: Select(least-request) -> [0] [1] [0] [0]
+	//test: Done(duplicate-url) -> [selected:0 1] [next:0]
+	//test: Select(consistent-hash) -> [endpoints:1]
+
+}
+
+func Example_Proxy_Ejection() {
+	t := newTable(true, false)
+	req, _ := http.NewRequest("GET", "https://www.google.com/search", nil)
+	p := newProxy("test-route", t, &ProxyConfig{Enabled: true, Endpoints: testEndpoints, MaxFailures: 2, EjectionTime: time.Millisecond * 50})
+
+	p.Done(0, false)
+	p.Done(0, false)
+	fmt.Printf("test: Done(failure) -> [ejected:%v]\n", p.Ejected())
+	fmt.Printf("test: Select(ejected) -> %v\n", countSelected(p, req, 4))
+
+	p.Done(1, false)
+	p.Done(1, false)
+	fmt.Printf("test: Select(all-ejected) -> [endpoints:%v]\n", len(countSelected(p, req, 4)))
+
+	time.Sleep(time.Millisecond * 60)
+	fmt.Printf("test: Ejected(expired) -> [ejected:%v]\n", p.Ejected())
+
+	//Output:
+	//test: Done(failure) -> [ejected:[http://host-a:8080]]
+	//test: Select(ejected) -> map[http://host-b:8080:4]
+	//test: Select(all-ejected) -> [endpoints:2]
+	//test: Ejected(expired) -> [ejected:[]]
+
+}
+
+func Example_Proxy_ValidateEndpoints() {
+	t := newTable(true, false)
+	p := newProxy("test-route", t, &ProxyConfig{Enabled: true, Endpoints: testEndpoints, Strategy: ConsistentHashStrategy})
+	fmt.Printf("test: validate(consistent-hash) -> [%v]\n", p.validate())
+
+	p = newProxy("test-route", t, &ProxyConfig{Enabled: true, Endpoints: testEndpoints, Strategy: "fastest"})
+	fmt.Printf("test: validate(fastest) -> [%v]\n", p.validate())
+
+	p = newProxy("test-route", t, &ProxyConfig{Enabled: true, Endpoints: []Endpoint{{Url: "http://host-a", Weight: -1}}})
+	fmt.Printf("test: validate(weight) -> [%v]\n", p.validate())
+
+	//Output:
+	//test: validate(consistent-hash) -> [hashHeader: invalid configuration: Proxy hash header is empty for consistent-hash strategy]
+	//test: validate(fastest) -> [strategy: invalid configuration: Proxy strategy is invalid [fastest]]
+	//test: validate(weight) -> [endpoints[0].weight: invalid configuration: Proxy endpoint weight is < 0 [http://host-a]]
+
+}
@@ -0,0 +1,214 @@
+package controller
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Bulkhead - interface for capping concurrent requests
+type Bulkhead interface {
+	IsEnabled() bool
+	Enable()
+	Disable()
+	Acquire(ctx context.Context) bool
+	Release()
+	Active() int
+	Queued() int
+	StatusCode() int
+	SetBulkhead(maxConcurrent, maxQueue int)
+}
+
+type BulkheadConfig struct {
+	MaxConcurrent int
+	MaxQueue      int           // Requests that can wait for a slot, 0 rejects immediately
+	QueueTimeout  time.Duration // Longest wait for a slot, 0 waits until the request context is done
+	StatusCode    int
+}
+
+func NewBulkheadConfig(maxConcurrent, maxQueue int, queueTimeout time.Duration, statusCode int) *BulkheadConfig {
+	c := new(BulkheadConfig)
+	c.MaxConcurrent = maxConcurrent
+	c.MaxQueue = maxQueue
+	c.QueueTimeout = queueTimeout
+	if statusCode <= 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.StatusCode = statusCode
+	return c
+}
+
+// bulkheadState - shared between clones, requests acquired before a resize release against the same state
+type bulkheadState struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	maxQueue      int
+	active        int
+	waiters       *list.List
+}
+
+type bulkhead struct {
+	table   *table
+	name    string
+	enabled bool
+	config  BulkheadConfig
+	state   *bulkheadState
+}
+
+func cloneBulkhead(curr *bulkhead) *bulkhead {
+	t := new(bulkhead)
+	*t = *curr
+	return t
+}
+
+func newBulkhead(name string, table *table, config *BulkheadConfig) *bulkhead {
+	t := new(bulkhead)
+	t.table = table
+	t.name = name
+	t.enabled = true
+	if config != nil {
+		t.config = *config
+	}
+	t.state = &bulkheadState{maxConcurrent: t.config.MaxConcurrent, maxQueue: t.config.MaxQueue, waiters: list.New()}
+	return t
+}
+
+func (b *bulkhead) validate() error {
+	if b.config.MaxConcurrent <= 0 {
+		return newFieldError("maxConcurrent", errors.New("invalid configuration: Bulkhead max concurrent is <= 0"))
+	}
+	if b.config.MaxQueue < 0 {
+		return newFieldError("maxQueue", errors.New("invalid configuration: Bulkhead max queue is < 0"))
+	}
+	if b.config.QueueTimeout < 0 {
+		return newFieldError("queueTimeout", errors.New("invalid configuration: Bulkhead queue timeout is < 0"))
+	}
+	return nil
+}
+
+func (b *bulkhead) IsEnabled() bool { return b.enabled }
+
+func (b *bulkhead) Disable() {
+	if !b.IsEnabled() {
+		return
+	}
+	b.table.enableBulkhead(b.name, false)
+}
+
+func (b *bulkhead) Enable() {
+	if b.IsEnabled() {
+		return
+	}
+	b.table.enableBulkhead(b.name, true)
+}
+
+func (b *bulkhead) StatusCode() int {
+	return b.config.StatusCode
+}
+
+func (b *bulkhead) SetBulkhead(maxConcurrent, maxQueue int) {
+	if maxConcurrent <= 0 || maxQueue < 0 {
+		return
+	}
+	if b.config.MaxConcurrent == maxConcurrent && b.config.MaxQueue == maxQueue {
+		return
+	}
+	b.table.setBulkhead(b.name, maxConcurrent, maxQueue)
+}
+
+func (b *bulkhead) Active() int {
+	b.state.mu.Lock()
+	defer b.state.mu.Unlock()
+	return b.state.active
+}
+
+func (b *bulkhead) Queued() int {
+	b.state.mu.Lock()
+	defer b.state.mu.Unlock()
+	return b.state.waiters.Len()
+}
+
+// Acquire - determine if a request can proceed, waiting in the queue for a slot if the bulkhead is full. A
+// successful Acquire must be followed by a Release
+func (b *bulkhead) Acquire(ctx context.Context) bool {
+	s := b.state
+	s.mu.Lock()
+	if !b.IsEnabled() || s.active < s.maxConcurrent {
+		s.active++
+		s.mu.Unlock()
+		return true
+	}
+	if s.waiters.Len() >= s.maxQueue {
+		s.mu.Unlock()
+		return false
+	}
+	ready := make(chan struct{})
+	e := s.waiters.PushBack(ready)
+	s.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if b.config.QueueTimeout > 0 {
+		timer := time.NewTimer(b.config.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	select {
+	case <-ready:
+		return true
+	case <-timeout:
+	case <-ctx.Done():
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-ready:
+		// The slot was handed over while timing out
+		s.release()
+	default:
+		s.waiters.Remove(e)
+	}
+	return false
+}
+
+// Release - release a slot acquired by Acquire
+func (b *bulkhead) Release() {
+	s := b.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.release()
+}
+
+// release - hand the slot to the next waiter, the active count is unchanged when a slot is handed over
+func (s *bulkheadState) release() {
+	if s.active <= s.maxConcurrent {
+		if e := s.waiters.Front(); e != nil {
+			s.waiters.Remove(e)
+			close(e.Value.(chan struct{}))
+			return
+		}
+	}
+	if s.active > 0 {
+		s.active--
+	}
+}
+
+// resize - update the limits, and admit waiters if the bulkhead has grown
+func (s *bulkheadState) resize(maxConcurrent, maxQueue int) {
+	s.maxConcurrent = maxConcurrent
+	s.maxQueue = maxQueue
+	for s.active < s.maxConcurrent {
+		e := s.waiters.Front()
+		if e == nil {
+			break
+		}
+		s.waiters.Remove(e)
+		s.active++
+		close(e.Value.(chan struct{}))
+	}
+}
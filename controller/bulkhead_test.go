@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func Example_newBulkhead() {
+	t := newBulkhead("test-route", newTable(true, false), NewBulkheadConfig(2, 1, time.Millisecond*10, 0))
+	fmt.Printf("test: newBulkhead() -> [name:%v] [config:%v] [active:%v] [queued:%v]\n", t.name, t.config, t.Active(), t.Queued())
+
+	t.config.MaxConcurrent = 0
+	fmt.Printf("test: validate() -> [%v]\n", t.validate())
+
+	c, _ := newController(newRoute("test-route", NewBulkheadConfig(2, 0, 0, 0)), newTable(false, false))
+	fmt.Printf("test: validate(ingress) -> [%v]\n", c.validate(false))
+
+	//Output:
+	//test: newBulkhead() -> [name:test-route] [config:{2 1 10ms 503}] [active:0] [queued:0]
+	//test: validate() -> [maxConcurrent: invalid configuration: Bulkhead max concurrent is <= 0]
+	//test: validate(ingress) -> [<nil>]
+
+}
+
+func Example_Bulkhead_Queue() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewBulkheadConfig(1, 1, time.Millisecond*50, 0)))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	b, _ := t.LookupByName(name).Bulkhead()
+	fmt.Printf("test: Acquire() -> [%v] [active:%v]\n", b.Acquire(context.Background()), b.Active())
+
+	// Queue timeout
+	fmt.Printf("test: Acquire(queue-timeout) -> [%v] [queued:%v]\n", b.Acquire(context.Background()), b.Queued())
+
+	// Queued request is admitted on release
+	result := make(chan bool)
+	go func() { result <- b.Acquire(context.Background()) }()
+	time.Sleep(time.Millisecond * 10)
+	fmt.Printf("test: Acquire(queue-full) -> [%v] [queued:%v]\n", b.Acquire(context.Background()), b.Queued())
+	b.Release()
+	fmt.Printf("test: Release() -> [queued-acquire:%v] [active:%v] [queued:%v]\n", <-result, b.Active(), b.Queued())
+
+	// Cancelled request context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fmt.Printf("test: Acquire(cancelled) -> [%v]\n", b.Acquire(ctx))
+
+	b.Release()
+	fmt.Printf("test: Release() -> [active:%v]\n", b.Active())
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Acquire() -> [true] [active:1]
+	//test: Acquire(queue-timeout) -> [false] [queued:0]
+	//test: Acquire(queue-full) -> [false] [queued:1]
+	//test: Release() -> [queued-acquire:true] [active:1] [queued:0]
+	//test: Acquire(cancelled) -> [false]
+	//test: Release() -> [active:0]
+
+}
+
+func Example_SetBulkhead() {
+	name := "test-route"
+	t := newTable(true, false)
+	t.AddController(newRoute(name, NewBulkheadConfig(1, 1, 0, 0)))
+
+	b, _ := t.LookupByName(name).Bulkhead()
+	b.Acquire(context.Background())
+	result := make(chan bool)
+	go func() { result <- b.Acquire(context.Background()) }()
+	time.Sleep(time.Millisecond * 10)
+
+	b.SetBulkhead(2, 1)
+	b, _ = t.LookupByName(name).Bulkhead()
+	fmt.Printf("test: SetBulkhead(2,1) -> [queued-acquire:%v] [active:%v] [config:%v]\n", <-result, b.Active(), t.LookupByName(name).t().bulkhead.config)
+
+	b.SetBulkhead(1, 0)
+	b.Release()
+	fmt.Printf("test: SetBulkhead(1,0) -> [active:%v] [acquire:%v]\n", b.Active(), b.Acquire(context.Background()))
+
+	//Output:
+	//test: SetBulkhead(2,1) -> [queued-acquire:true] [active:2] [config:{2 1 0s 503}]
+	//test: SetBulkhead(1,0) -> [active:1] [acquire:false]
+
+}
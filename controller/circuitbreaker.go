@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// CircuitBreaker - interface for circuit breaking
+type CircuitBreaker interface {
+	IsEnabled() bool
+	Enable()
+	Disable()
+	Allow() (ok bool, probe bool)
+	Record(probe, success bool)
+	Cancel(probe bool)
+	State() string
+	StatusCode() int
+	SetCircuitBreaker(config CircuitBreakerConfig)
+}
+
+type CircuitBreakerConfig struct {
+	ErrorRatio          float64 // ratio of failures, over the last MinRequests, that trips the breaker
+	MinRequests         int
+	ConsecutiveFailures int
+	CoolDown            time.Duration
+	StatusCode          int
+}
+
+func NewCircuitBreakerConfig(errorRatio float64, minRequests, consecutiveFailures int, coolDown time.Duration, statusCode int) *CircuitBreakerConfig {
+	c := new(CircuitBreakerConfig)
+	c.ErrorRatio = errorRatio
+	c.MinRequests = minRequests
+	c.ConsecutiveFailures = consecutiveFailures
+	c.CoolDown = coolDown
+	if statusCode <= 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.StatusCode = statusCode
+	return c
+}
+
+// breakerState - shared between clones so that a configuration update does not reset the circuit
+type breakerState struct {
+	mu          sync.Mutex
+	state       string
+	openedAt    time.Time
+	probing     bool
+	consecutive int
+	outcomes    []bool
+	next        int
+	count       int
+	failures    int
+}
+
+type circuitBreaker struct {
+	table   *table
+	name    string
+	enabled bool
+	config  CircuitBreakerConfig
+	state   *breakerState
+}
+
+func cloneCircuitBreaker(curr *circuitBreaker) *circuitBreaker {
+	t := new(circuitBreaker)
+	*t = *curr
+	return t
+}
+
+func newCircuitBreaker(name string, table *table, config *CircuitBreakerConfig) *circuitBreaker {
+	t := new(circuitBreaker)
+	t.table = table
+	t.name = name
+	t.enabled = true
+	if config != nil {
+		t.config = *config
+	}
+	t.state = newBreakerState(t.config.MinRequests)
+	return t
+}
+
+func newBreakerState(size int) *breakerState {
+	s := new(breakerState)
+	s.state = CircuitClosed
+	if size > 0 {
+		s.outcomes = make([]bool, size)
+	}
+	return s
+}
+
+func (c *circuitBreaker) validate() error {
+	if c.config.ErrorRatio < 0 || c.config.ErrorRatio > 1 {
+		return newFieldError("errorRatio", errors.New("invalid configuration: CircuitBreaker error ratio is not between 0 and 1"))
+	}
+	if c.config.ErrorRatio > 0 && c.config.MinRequests <= 0 {
+		return newFieldError("minRequests", errors.New("invalid configuration: CircuitBreaker minimum requests is <= 0"))
+	}
+	if c.config.ErrorRatio == 0 && c.config.ConsecutiveFailures <= 0 {
+		return newFieldError("errorRatio", errors.New("invalid configuration: CircuitBreaker error ratio and consecutive failures are not configured"))
+	}
+	if c.config.CoolDown <= 0 {
+		return newFieldError("coolDown", errors.New("invalid configuration: CircuitBreaker cool down is <= 0"))
+	}
+	return nil
+}
+
+func circuitBreakerState(m map[string]string, c *circuitBreaker) {
+	if c == nil {
+		m[CircuitBreakerName] = ""
+	} else {
+		m[CircuitBreakerName] = c.State()
+	}
+}
+
+func (c *circuitBreaker) IsEnabled() bool { return c.enabled }
+
+func (c *circuitBreaker) Disable() {
+	if !c.IsEnabled() {
+		return
+	}
+	c.table.enableCircuitBreaker(c.name, false)
+}
+
+func (c *circuitBreaker) Enable() {
+	if c.IsEnabled() {
+		return
+	}
+	c.table.enableCircuitBreaker(c.name, true)
+}
+
+func (c *circuitBreaker) StatusCode() int {
+	return c.config.StatusCode
+}
+
+func (c *circuitBreaker) SetCircuitBreaker(config CircuitBreakerConfig) {
+	if config.StatusCode <= 0 {
+		config.StatusCode = c.config.StatusCode
+	}
+	if c.config == config {
+		return
+	}
+	c.table.setCircuitBreaker(c.name, config)
+}
+
+func (c *circuitBreaker) State() string {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state == CircuitOpen && time.Since(s.openedAt) >= c.config.CoolDown {
+		return CircuitHalfOpen
+	}
+	return s.state
+}
+
+// Allow - determine if a request can proceed, once the cool down has elapsed a single probe request is allowed.
+// The probe flag is passed to Record with the outcome of the request
+func (c *circuitBreaker) Allow() (bool, bool) {
+	if !c.IsEnabled() {
+		return true, false
+	}
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch s.state {
+	case CircuitClosed:
+		return true, false
+	case CircuitOpen:
+		if time.Since(s.openedAt) < c.config.CoolDown {
+			return false, false
+		}
+		s.state = CircuitHalfOpen
+		s.probing = true
+		return true, true
+	}
+	// Half open, only one probe at a time
+	if s.probing {
+		return false, false
+	}
+	s.probing = true
+	return true, true
+}
+
+// Record - record the outcome of a request. While the circuit is half open only the outcome of the probe decides
+// the state, requests admitted before the circuit opened are ignored
+func (c *circuitBreaker) Record(probe, success bool) {
+	if !c.IsEnabled() {
+		return
+	}
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state == CircuitHalfOpen {
+		if !probe {
+			return
+		}
+		s.probing = false
+		if success {
+			s.reset(CircuitClosed)
+		} else {
+			s.trip()
+		}
+		return
+	}
+	if s.state == CircuitOpen || probe {
+		return
+	}
+	if success {
+		s.consecutive = 0
+	} else {
+		s.consecutive++
+	}
+	s.add(success)
+	if c.tripped() {
+		s.trip()
+	}
+}
+
+// Cancel - release a request allowed by Allow that was not sent, so that another request can be the probe
+func (c *circuitBreaker) Cancel(probe bool) {
+	if !probe {
+		return
+	}
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state == CircuitHalfOpen {
+		s.probing = false
+	}
+}
+
+func (c *circuitBreaker) tripped() bool {
+	s := c.state
+	if c.config.ConsecutiveFailures > 0 && s.consecutive >= c.config.ConsecutiveFailures {
+		return true
+	}
+	if c.config.ErrorRatio > 0 && s.count >= c.config.MinRequests {
+		return float64(s.failures)/float64(s.count) >= c.config.ErrorRatio
+	}
+	return false
+}
+
+func (s *breakerState) add(success bool) {
+	if len(s.outcomes) == 0 {
+		return
+	}
+	if s.count == len(s.outcomes) {
+		if !s.outcomes[s.next] {
+			s.failures--
+		}
+	} else {
+		s.count++
+	}
+	s.outcomes[s.next] = success
+	if !success {
+		s.failures++
+	}
+	s.next = (s.next + 1) % len(s.outcomes)
+}
+
+func (s *breakerState) trip() {
+	s.reset(CircuitOpen)
+	s.openedAt = time.Now()
+}
+
+func (s *breakerState) reset(state string) {
+	s.state = state
+	s.probing = false
+	s.consecutive = 0
+	s.next = 0
+	s.count = 0
+	s.failures = 0
+}
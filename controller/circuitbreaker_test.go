@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+)
+
+// allow - the circuit breaker allows a request, whether it is a probe is reported with the outcome
+func allow(cb CircuitBreaker) bool {
+	ok, _ := cb.Allow()
+	return ok
+}
+
+func Example_newCircuitBreaker() {
+	t := newTable(true, false)
+	c := newCircuitBreaker("test-route", t, NewCircuitBreakerConfig(0.5, 10, 0, time.Second, 0))
+	fmt.Printf("test: newCircuitBreaker() -> [name:%v] [enabled:%v] [state:%v] [statusCode:%v] [validate:%v]\n", c.name, c.enabled, c.State(), c.StatusCode(), c.validate())
+
+	c = newCircuitBreaker("test-route", t, NewCircuitBreakerConfig(0.5, 0, 0, time.Second, 0))
+	fmt.Printf("test: newCircuitBreaker() -> [validate:%v]\n", c.validate())
+
+	c = newCircuitBreaker("test-route", t, NewCircuitBreakerConfig(0, 0, 0, time.Second, 0))
+	fmt.Printf("test: newCircuitBreaker() -> [validate:%v]\n", c.validate())
+
+	c = newCircuitBreaker("test-route", t, NewCircuitBreakerConfig(0, 0, 5, 0, 0))
+	fmt.Printf("test: newCircuitBreaker() -> [validate:%v]\n", c.validate())
+
+	c2 := cloneCircuitBreaker(c)
+	c2.enabled = false
+	fmt.Printf("test: cloneCircuitBreaker() -> [prev-enabled:%v] [curr-enabled:%v] [shared-state:%v]\n", c.enabled, c2.enabled, c.state == c2.state)
+
+	m := make(map[string]string, 16)
+	circuitBreakerState(m, nil)
+	fmt.Printf("test: circuitBreakerState(map,nil) -> %v\n", m)
+	circuitBreakerState(m, c)
+	fmt.Printf("test: circuitBreakerState(map,c) -> %v\n", m)
+
+	//Output:
+	//test: newCircuitBreaker() -> [name:test-route] [enabled:true] [state:closed] [statusCode:503] [validate:<nil>]
+	//test: newCircuitBreaker() -> [validate:minRequests: invalid configuration: CircuitBreaker minimum requests is <= 0]
+	//test: newCircuitBreaker() -> [validate:errorRatio: invalid configuration: CircuitBreaker error ratio and consecutive failures are not configured]
+	//test: newCircuitBreaker() -> [validate:coolDown: invalid configuration: CircuitBreaker cool down is <= 0]
+	//test: cloneCircuitBreaker() -> [prev-enabled:true] [curr-enabled:false] [shared-state:true]
+	//test: circuitBreakerState(map,nil) -> map[circuitBreaker:]
+	//test: circuitBreakerState(map,c) -> map[circuitBreaker:closed]
+
+}
+
+func Example_CircuitBreaker_Consecutive() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewCircuitBreakerConfig(0, 0, 3, time.Millisecond*100, 0)))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	cb, _ := t.LookupByName(name).CircuitBreaker()
+	cb.Record(false, false)
+	cb.Record(false, false)
+	cb.Record(false, true)
+	cb.Record(false, false)
+	cb.Record(false, false)
+	fmt.Printf("test: Record(false,false,true,false,false) -> [state:%v] [allow:%v]\n", cb.State(), allow(cb))
+
+	cb.Record(false, false)
+	fmt.Printf("test: Record(false) -> [state:%v] [allow:%v]\n", cb.State(), allow(cb))
+
+	time.Sleep(time.Millisecond * 150)
+	fmt.Printf("test: State() -> [state:%v]\n", cb.State())
+	ok, probe := cb.Allow()
+	fmt.Printf("test: Allow() -> [ok:%v] [probe:%v] [next:%v]\n", ok, probe, allow(cb))
+
+	// A request admitted before the circuit opened does not decide the state, only the probe does
+	cb.Record(false, true)
+	fmt.Printf("test: Record(not-probe,true) -> [state:%v]\n", cb.State())
+	cb.Record(probe, false)
+	fmt.Printf("test: Record(false) -> [state:%v] [allow:%v]\n", cb.State(), allow(cb))
+
+	// A probe that was not sent is cancelled, so that the next request is the probe
+	time.Sleep(time.Millisecond * 150)
+	_, probe = cb.Allow()
+	cb.Cancel(probe)
+	fmt.Printf("test: Cancel(probe) -> [state:%v]\n", cb.State())
+	fmt.Printf("test: Allow() -> [probe:%v]\n", allow(cb))
+	cb.Record(true, true)
+	fmt.Printf("test: Record(true) -> [state:%v] [allow:%v]\n", cb.State(), allow(cb))
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Record(false,false,true,false,false) -> [state:closed] [allow:true]
+	//test: Record(false) -> [state:open] [allow:false]
+	//test: State() -> [state:half-open]
+	//test: Allow() -> [ok:true] [probe:true] [next:false]
+	//test: Record(not-probe,true) -> [state:half-open]
+	//test: Record(false) -> [state:open] [allow:false]
+	//test: Cancel(probe) -> [state:half-open]
+	//test: Allow() -> [probe:true]
+	//test: Record(true) -> [state:closed] [allow:true]
+
+}
+
+func Example_CircuitBreaker_ErrorRatio() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewCircuitBreakerConfig(0.5, 4, 0, time.Minute, 0)))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	cb, _ := t.LookupByName(name).CircuitBreaker()
+	cb.Record(false, false)
+	cb.Record(false, true)
+	cb.Record(false, false)
+	fmt.Printf("test: Record(false,true,false) -> [state:%v]\n", cb.State())
+
+	cb.Record(false, true)
+	fmt.Printf("test: Record(true) -> [state:%v]\n", cb.State())
+
+	cb.Disable()
+	cb, _ = t.LookupByName(name).CircuitBreaker()
+	fmt.Printf("test: Disable() -> [enabled:%v] [allow:%v]\n", cb.IsEnabled(), allow(cb))
+
+	cb.Enable()
+	cb, _ = t.LookupByName(name).CircuitBreaker()
+	fmt.Printf("test: Enable() -> [enabled:%v] [allow:%v]\n", cb.IsEnabled(), allow(cb))
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Record(false,true,false) -> [state:closed]
+	//test: Record(true) -> [state:open]
+	//test: Disable() -> [enabled:false] [allow:true]
+	//test: Enable() -> [enabled:true] [allow:false]
+
+}
+
+func Example_CircuitBreaker_Set() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewCircuitBreakerConfig(0, 0, 1, time.Minute, 0)))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	cb, _ := t.LookupByName(name).CircuitBreaker()
+	cb.Record(false, false)
+	fmt.Printf("test: Record(false) -> [state:%v]\n", cb.State())
+
+	cb.SetCircuitBreaker(CircuitBreakerConfig{ErrorRatio: 0.25, MinRequests: 8, CoolDown: time.Millisecond})
+	ctrl := t.LookupByName(name)
+	time.Sleep(time.Millisecond * 5)
+	fmt.Printf("test: SetCircuitBreaker() -> [config:%v] [state:%v]\n", ctrl.t().circuitBreaker.config, ctrl.t().circuitBreaker.State())
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Record(false) -> [state:open]
+	//test: SetCircuitBreaker() -> [config:{0.25 8 0 1ms 503}] [state:half-open]
+
+}
@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	AIMDAlgorithm     = "aimd"
+	GradientAlgorithm = "gradient"
+
+	DefaultConcurrencyBackoff   = 0.9
+	DefaultConcurrencySmoothing = 0.2
+	DefaultConcurrencyTolerance = 1.5
+
+	baselineSmoothing = 0.05
+)
+
+// https://github.com/Netflix/concurrency-limits
+
+// ConcurrencyLimiter - interface for adaptive concurrency limiting
+type ConcurrencyLimiter interface {
+	IsEnabled() bool
+	Enable()
+	Disable()
+	Acquire() bool
+	Release(latency time.Duration, success bool)
+	Cancel()
+	Limit() int
+	InFlight() int
+	StatusCode() int
+	SetConcurrencyLimiter(config ConcurrencyLimiterConfig)
+}
+
+type ConcurrencyLimiterConfig struct {
+	Algorithm    string // aimd or gradient
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	Backoff      float64       // Multiplicative decrease on a failed or timed out request, 0 is DefaultConcurrencyBackoff
+	Timeout      time.Duration // Latency above which a request is treated as failed, 0 is disabled
+	Smoothing    float64       // Gradient only, weight of a new limit, 0 is DefaultConcurrencySmoothing
+	Tolerance    float64       // Gradient only, allowed ratio of latency to the baseline, 0 is DefaultConcurrencyTolerance
+	StatusCode   int
+}
+
+func NewConcurrencyLimiterConfig(algorithm string, initialLimit, minLimit, maxLimit int, statusCode int) *ConcurrencyLimiterConfig {
+	c := new(ConcurrencyLimiterConfig)
+	if algorithm == "" {
+		algorithm = AIMDAlgorithm
+	}
+	c.Algorithm = algorithm
+	c.InitialLimit = initialLimit
+	c.MinLimit = minLimit
+	c.MaxLimit = maxLimit
+	if statusCode <= 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.StatusCode = statusCode
+	return c
+}
+
+// limiterState - shared between clones so that a configuration update does not reset the in-flight count
+type limiterState struct {
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	baseline time.Duration // gradient, slowly smoothed latency
+}
+
+type concurrencyLimiter struct {
+	table   *table
+	name    string
+	enabled bool
+	config  ConcurrencyLimiterConfig
+	state   *limiterState
+}
+
+func cloneConcurrencyLimiter(curr *concurrencyLimiter) *concurrencyLimiter {
+	t := new(concurrencyLimiter)
+	*t = *curr
+	return t
+}
+
+func newConcurrencyLimiter(name string, table *table, config *ConcurrencyLimiterConfig) *concurrencyLimiter {
+	t := new(concurrencyLimiter)
+	t.table = table
+	t.name = name
+	t.enabled = true
+	if config != nil {
+		t.config = *config
+	}
+	t.state = new(limiterState)
+	t.state.limit = float64(t.config.InitialLimit)
+	return t
+}
+
+func (c *concurrencyLimiter) validate() error {
+	switch c.config.Algorithm {
+	case AIMDAlgorithm, GradientAlgorithm:
+	default:
+		return newFieldError("algorithm", errors.New(fmt.Sprintf("invalid configuration: ConcurrencyLimiter algorithm is invalid [%v]", c.config.Algorithm)))
+	}
+	if c.config.MinLimit <= 0 {
+		return newFieldError("minLimit", errors.New("invalid configuration: ConcurrencyLimiter minimum limit is <= 0"))
+	}
+	if c.config.MaxLimit < c.config.MinLimit {
+		return newFieldError("maxLimit", errors.New("invalid configuration: ConcurrencyLimiter maximum limit is < minimum limit"))
+	}
+	if c.config.InitialLimit < c.config.MinLimit || c.config.InitialLimit > c.config.MaxLimit {
+		return newFieldError("initialLimit", errors.New("invalid configuration: ConcurrencyLimiter initial limit is not between the minimum and maximum limits"))
+	}
+	if c.config.Backoff < 0 || c.config.Backoff >= 1 {
+		return newFieldError("backoff", errors.New("invalid configuration: ConcurrencyLimiter backoff is not between 0 and 1"))
+	}
+	if c.config.Smoothing < 0 || c.config.Smoothing > 1 {
+		return newFieldError("smoothing", errors.New("invalid configuration: ConcurrencyLimiter smoothing is not between 0 and 1"))
+	}
+	if c.config.Tolerance != 0 && c.config.Tolerance < 1 {
+		return newFieldError("tolerance", errors.New("invalid configuration: ConcurrencyLimiter tolerance is < 1"))
+	}
+	return nil
+}
+
+func concurrencyLimiterState(m map[string]string, c *concurrencyLimiter) {
+	limit := -1
+	inFlight := -1
+	if c != nil {
+		limit = c.Limit()
+		inFlight = c.InFlight()
+	}
+	m[ConcurrencyLimitName] = strconv.Itoa(limit)
+	m[InFlightName] = strconv.Itoa(inFlight)
+}
+
+func (c *concurrencyLimiter) IsEnabled() bool { return c.enabled }
+
+func (c *concurrencyLimiter) Disable() {
+	if !c.IsEnabled() {
+		return
+	}
+	c.table.enableConcurrencyLimiter(c.name, false)
+}
+
+func (c *concurrencyLimiter) Enable() {
+	if c.IsEnabled() {
+		return
+	}
+	c.table.enableConcurrencyLimiter(c.name, true)
+}
+
+func (c *concurrencyLimiter) StatusCode() int {
+	return c.config.StatusCode
+}
+
+func (c *concurrencyLimiter) SetConcurrencyLimiter(config ConcurrencyLimiterConfig) {
+	if config.StatusCode <= 0 {
+		config.StatusCode = c.config.StatusCode
+	}
+	if c.config == config {
+		return
+	}
+	c.table.setConcurrencyLimiter(c.name, config)
+}
+
+func (c *concurrencyLimiter) Limit() int {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	return int(c.state.limit)
+}
+
+func (c *concurrencyLimiter) InFlight() int {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	return c.state.inFlight
+}
+
+// Acquire - determine if a request can proceed, a successful Acquire must be followed by a Release
+func (c *concurrencyLimiter) Acquire() bool {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.IsEnabled() && s.inFlight >= int(s.limit) {
+		return false
+	}
+	s.inFlight++
+	return true
+}
+
+// Release - record the outcome of a request and adjust the limit
+func (c *concurrencyLimiter) Release(latency time.Duration, success bool) {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inFlight := s.inFlight
+	if s.inFlight > 0 {
+		s.inFlight--
+	}
+	if !c.IsEnabled() {
+		return
+	}
+	if !success || (c.config.Timeout > 0 && latency > c.config.Timeout) {
+		s.limit = c.clamp(s.limit * c.backoff())
+		return
+	}
+	if c.config.Algorithm == GradientAlgorithm {
+		s.limit = c.clamp(c.gradient(s, latency))
+		return
+	}
+	// Only increase the limit when it is being used
+	if inFlight*2 >= int(s.limit) {
+		s.limit = c.clamp(s.limit + 1)
+	}
+}
+
+// Cancel - release a request admitted by Acquire that was not sent, the limit is not adjusted
+func (c *concurrencyLimiter) Cancel() {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight > 0 {
+		s.inFlight--
+	}
+}
+
+// gradient - increase the limit while latency is close to the baseline, and decrease it as latency grows
+func (c *concurrencyLimiter) gradient(s *limiterState, latency time.Duration) float64 {
+	smoothing := c.config.Smoothing
+	if smoothing == 0 {
+		smoothing = DefaultConcurrencySmoothing
+	}
+	tolerance := c.config.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultConcurrencyTolerance
+	}
+	if s.baseline == 0 {
+		s.baseline = latency
+	} else {
+		s.baseline = time.Duration(float64(s.baseline)*(1-baselineSmoothing) + float64(latency)*baselineSmoothing)
+	}
+	if latency <= 0 {
+		return s.limit
+	}
+	gradient := math.Max(0.5, math.Min(1.0, tolerance*float64(s.baseline)/float64(latency)))
+	newLimit := s.limit*gradient + math.Sqrt(s.limit)
+	return s.limit*(1-smoothing) + newLimit*smoothing
+}
+
+func (c *concurrencyLimiter) backoff() float64 {
+	if c.config.Backoff == 0 {
+		return DefaultConcurrencyBackoff
+	}
+	return c.config.Backoff
+}
+
+func (c *concurrencyLimiter) clamp(limit float64) float64 {
+	return math.Max(float64(c.config.MinLimit), math.Min(float64(c.config.MaxLimit), limit))
+}
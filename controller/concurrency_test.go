@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+)
+
+func Example_newConcurrencyLimiter() {
+	t := newConcurrencyLimiter("test-route", newTable(true, false), NewConcurrencyLimiterConfig("", 10, 1, 100, 0))
+	fmt.Printf("test: newConcurrencyLimiter() -> [name:%v] [config:%v] [limit:%v] [in-flight:%v]\n", t.name, t.config, t.Limit(), t.InFlight())
+
+	t.config.Algorithm = "vegas"
+	fmt.Printf("test: validate() -> [%v]\n", t.validate())
+
+	m := make(map[string]string)
+	concurrencyLimiterState(m, nil)
+	fmt.Printf("test: concurrencyLimiterState(nil) -> %v\n", m)
+
+	//Output:
+	//test: newConcurrencyLimiter() -> [name:test-route] [config:{aimd 10 1 100 0 0s 0 0 503}] [limit:10] [in-flight:0]
+	//test: validate() -> [algorithm: invalid configuration: ConcurrencyLimiter algorithm is invalid [vegas]]
+	//test: concurrencyLimiterState(nil) -> map[concurrencyLimit:-1 inFlight:-1]
+
+}
+
+func Example_ConcurrencyLimiter_AIMD() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewConcurrencyLimiterConfig(AIMDAlgorithm, 2, 1, 4, 0)))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	c, _ := t.LookupByName(name).ConcurrencyLimiter()
+	fmt.Printf("test: Acquire() -> [%v] [%v] [%v] [in-flight:%v]\n", c.Acquire(), c.Acquire(), c.Acquire(), c.InFlight())
+
+	c.Release(time.Millisecond, true)
+	c.Release(time.Millisecond, true)
+	fmt.Printf("test: Release(success) -> [limit:%v] [in-flight:%v]\n", c.Limit(), c.InFlight())
+
+	c.Acquire()
+	c.Release(time.Millisecond, false)
+	fmt.Printf("test: Release(failure) -> [limit:%v] [in-flight:%v]\n", c.Limit(), c.InFlight())
+
+	c.Acquire()
+	c.Cancel()
+	fmt.Printf("test: Cancel() -> [limit:%v] [in-flight:%v]\n", c.Limit(), c.InFlight())
+
+	c.Disable()
+	c, _ = t.LookupByName(name).ConcurrencyLimiter()
+	c.Acquire()
+	c.Acquire()
+	c.Acquire()
+	fmt.Printf("test: Disable() -> [enabled:%v] [limit:%v] [in-flight:%v]\n", c.IsEnabled(), c.Limit(), c.InFlight())
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Acquire() -> [true] [true] [false] [in-flight:2]
+	//test: Release(success) -> [limit:3] [in-flight:0]
+	//test: Release(failure) -> [limit:2] [in-flight:0]
+	//test: Cancel() -> [limit:2] [in-flight:0]
+	//test: Disable() -> [enabled:false] [limit:2] [in-flight:3]
+
+}
+
+func Example_ConcurrencyLimiter_Gradient() {
+	c := newConcurrencyLimiter("test-route", newTable(true, false), NewConcurrencyLimiterConfig(GradientAlgorithm, 20, 5, 100, 0))
+	for i := 0; i < 20; i++ {
+		c.Acquire()
+		c.Release(time.Millisecond*10, true)
+	}
+	steady := c.Limit()
+	fmt.Printf("test: Release(steady) -> [increased:%v]\n", steady > 20)
+
+	for i := 0; i < 20; i++ {
+		c.Acquire()
+		c.Release(time.Millisecond*100, true)
+	}
+	fmt.Printf("test: Release(slow) -> [decreased:%v]\n", c.Limit() < steady)
+
+	//Output:
+	//test: Release(steady) -> [increased:true]
+	//test: Release(slow) -> [decreased:true]
+
+}
+
+func Example_SetConcurrencyLimiter() {
+	name := "test-route"
+	t := newTable(true, false)
+	t.AddController(newRoute(name, NewConcurrencyLimiterConfig(AIMDAlgorithm, 50, 1, 100, 0)))
+
+	c, _ := t.LookupByName(name).ConcurrencyLimiter()
+	c.Acquire()
+	c.SetConcurrencyLimiter(*NewConcurrencyLimiterConfig(AIMDAlgorithm, 10, 1, 20, 0))
+	c, _ = t.LookupByName(name).ConcurrencyLimiter()
+	fmt.Printf("test: SetConcurrencyLimiter() -> [limit:%v] [in-flight:%v]\n", c.Limit(), c.InFlight())
+
+	//Output:
+	//test: SetConcurrencyLimiter() -> [limit:20] [in-flight:1]
+
+}
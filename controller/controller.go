@@ -3,7 +3,9 @@ package controller
 import (
 	"errors"
 	"github.com/google/uuid"
+	"github.com/gotemplates/host/tracing"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -19,23 +21,60 @@ const (
 	UpstreamTimeoutFlag = "UT"
 	HostTimeoutFlag     = "HT"
 	NotEnabledFlag      = "NE"
+	CircuitOpenFlag     = "CO"
+	RetryBudgetFlag     = "RB"
+	NotReplayableFlag   = "NR"
+	NotIdempotentFlag   = "NI"
+	ConcurrencyFlag     = "CL"
+	BulkheadFlag        = "BH"
+	ShedFlag            = "LS"
+
+	RouteNameAttribute   = "route.name"
+	StatusFlagsAttribute = "controller.statusFlags"
+	StatusCodeAttribute  = "http.status_code"
+	StateAttributePrefix = "controller."
+
+	RetrySkipNotEnabled    = "not-enabled"
+	RetrySkipNotIdempotent = "not-idempotent"
+	RetrySkipNotReplayable = "not-replayable"
+	RetrySkipRateLimited   = "rate-limited"
+	RetrySkipBudget        = "budget"
 )
 
 // Controller - definition for properties of a controller
 type Controller interface {
 	Name() string
+	State() map[string]string
 	Timeout() (Timeout, bool)
 	RateLimiter() (RateLimiter, bool)
 	Retry() (Retry, bool)
 	Failover() (Failover, bool)
 	Proxy() (Proxy, bool)
+	CircuitBreaker() (CircuitBreaker, bool)
+	ConcurrencyLimiter() (ConcurrencyLimiter, bool)
+	Bulkhead() (Bulkhead, bool)
+	Hedge() (Hedge, bool)
+	Mirror() (Mirror, bool)
+	Priority() (Priority, bool)
 	UpdateHeaders(req *http.Request)
 	LogHttpIngress(start time.Time, duration time.Duration, req *http.Request, statusCode int, written int64, statusFlags string)
-	LogHttpEgress(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, statusFlags string, retry bool)
+	LogHttpEgress(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, statusFlags string, status EgressStatus)
 	LogEgress(start time.Time, duration time.Duration, statusCode int, uri, requestId, method, statusFlags string)
+	LogHttpMirror(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, statusFlags string)
 	t() *controller
 }
 
+// EgressStatus - per request egress outcomes that are logged with the controller state
+type EgressStatus struct {
+	Attempt     int       // Attempt number, 1 is the original request
+	RetryStatus string    // Status flag of a retry that was not made
+	Hedged      bool      // Response is from the hedged request
+	Endpoint    string    // Proxy endpoint selected for the request
+	Branch      string    // Proxy branch, primary or canary
+	Begin       time.Time // Start of the request, for the latency metric, the zero value is the start of the attempt
+	Retried     bool      // Another attempt follows, only the final attempt is recorded in the route metrics
+}
+
 // Configuration - configuration for actuators
 type Configuration interface {
 	SetHttpMatcher(fn HttpMatcher)
@@ -43,6 +82,7 @@ type Configuration interface {
 	SetDefaultController(route Route) []error
 	SetHostController(route Route) []error
 	AddController(route Route) []error
+	Reload(buf []byte) []error
 }
 
 // Controllers - public interface
@@ -67,16 +107,22 @@ var IngressTable = NewIngressTable()
 var EgressTable = NewEgressTable()
 
 type controller struct {
-	name        string
-	ping        bool
-	timeout     *timeout
-	rateLimiter *rateLimiter
-	failover    *failover
-	retry       *retry
-	proxy       *proxy
+	name           string
+	ping           bool
+	timeout        *timeout
+	rateLimiter    *rateLimiter
+	failover       *failover
+	retry          *retry
+	proxy          *proxy
+	circuitBreaker *circuitBreaker
+	concurrency    *concurrencyLimiter
+	bulkhead       *bulkhead
+	hedge          *hedge
+	mirror         *mirror
+	priority       *priority
 }
 
-func cloneController[T *timeout | *rateLimiter | *retry | *proxy | *failover](curr *controller, item T) *controller {
+func cloneController[T *timeout | *rateLimiter | *retry | *proxy | *failover | *circuitBreaker | *concurrencyLimiter | *bulkhead | *hedge | *mirror](curr *controller, item T) *controller {
 	newC := new(controller)
 	*newC = *curr
 	switch i := any(item).(type) {
@@ -90,6 +136,16 @@ func cloneController[T *timeout | *rateLimiter | *retry | *proxy | *failover](cu
 		newC.proxy = i
 	case *retry:
 		newC.retry = i
+	case *circuitBreaker:
+		newC.circuitBreaker = i
+	case *concurrencyLimiter:
+		newC.concurrency = i
+	case *bulkhead:
+		newC.bulkhead = i
+	case *hedge:
+		newC.hedge = i
+	case *mirror:
+		newC.mirror = i
 	default:
 	}
 	return newC
@@ -105,35 +161,77 @@ func newController(route Route, t *table) (*controller, []error) {
 		ctrl.timeout = newTimeout(route.Name, t, route.Timeout)
 		err = ctrl.timeout.validate()
 		if err != nil {
-			errs = append(errs, err)
+			errs = append(errs, withPath("timeout", err))
 		}
 	}
 	if route.RateLimiter != nil {
 		ctrl.rateLimiter = newRateLimiter(route.Name, t, route.RateLimiter)
 		err = ctrl.rateLimiter.validate()
 		if err != nil {
-			errs = append(errs, err)
+			errs = append(errs, withPath("rateLimiter", err))
 		}
 	}
 	if route.Retry != nil {
 		ctrl.retry = newRetry(route.Name, t, route.Retry)
 		err = ctrl.retry.validate()
 		if err != nil {
-			errs = append(errs, err)
+			errs = append(errs, withPath("retry", err))
 		}
 	}
 	if route.Failover != nil {
 		ctrl.failover = newFailover(route.Name, t, route.Failover)
 		err = ctrl.failover.validate()
 		if err != nil {
-			errs = append(errs, err)
+			errs = append(errs, withPath("failover", err))
 		}
 	}
 	if route.Proxy != nil {
 		ctrl.proxy = newProxy(route.Name, t, route.Proxy)
 		err = ctrl.proxy.validate()
 		if err != nil {
-			errs = append(errs, err)
+			errs = append(errs, withPath("proxy", err))
+		}
+	}
+	if route.CircuitBreaker != nil {
+		ctrl.circuitBreaker = newCircuitBreaker(route.Name, t, route.CircuitBreaker)
+		err = ctrl.circuitBreaker.validate()
+		if err != nil {
+			errs = append(errs, withPath("circuitBreaker", err))
+		}
+	}
+	if route.ConcurrencyLimiter != nil {
+		ctrl.concurrency = newConcurrencyLimiter(route.Name, t, route.ConcurrencyLimiter)
+		err = ctrl.concurrency.validate()
+		if err != nil {
+			errs = append(errs, withPath("concurrencyLimiter", err))
+		}
+	}
+	if route.Bulkhead != nil {
+		ctrl.bulkhead = newBulkhead(route.Name, t, route.Bulkhead)
+		err = ctrl.bulkhead.validate()
+		if err != nil {
+			errs = append(errs, withPath("bulkhead", err))
+		}
+	}
+	if route.Hedge != nil {
+		ctrl.hedge = newHedge(route.Name, t, route.Hedge)
+		err = ctrl.hedge.validate()
+		if err != nil {
+			errs = append(errs, withPath("hedge", err))
+		}
+	}
+	if route.Mirror != nil {
+		ctrl.mirror = newMirror(route.Name, t, route.Mirror)
+		err = ctrl.mirror.validate()
+		if err != nil {
+			errs = append(errs, withPath("mirror", err))
+		}
+	}
+	if route.Priority != nil {
+		ctrl.priority = newPriority(route.Name, route.Priority)
+		err = ctrl.priority.validate()
+		if err != nil {
+			errs = append(errs, withPath("priority", err))
 		}
 	}
 	return ctrl, errs
@@ -150,20 +248,28 @@ func newNilController(name string) *controller {
 func (c *controller) validate(egress bool) error {
 	if !egress {
 		if c.failover != nil {
-			return errors.New("invalid configuration: Failover is not valid for ingress traffic")
+			return newFieldError("failover", errors.New("invalid configuration: Failover is not valid for ingress traffic"))
 		}
 		if c.retry != nil {
-			return errors.New("invalid configuration: Retry is not valid for ingress traffic")
+			return newFieldError("retry", errors.New("invalid configuration: Retry is not valid for ingress traffic"))
+		}
+		if c.circuitBreaker != nil {
+			return newFieldError("circuitBreaker", errors.New("invalid configuration: CircuitBreaker is not valid for ingress traffic"))
+		}
+		if c.hedge != nil {
+			return newFieldError("hedge", errors.New("invalid configuration: Hedge is not valid for ingress traffic"))
 		}
-		if c.name == HostControllerName {
-			if c.timeout != nil {
-				return errors.New("invalid configuration: Timeout is not valid for host controller")
-			}
-		} else {
-			if c.rateLimiter != nil {
-				return errors.New("invalid configuration: RateLimiter is not valid for ingress traffic")
-			}
+		if c.mirror != nil {
+			return newFieldError("mirror", errors.New("invalid configuration: Mirror is not valid for ingress traffic"))
 		}
+		if c.proxy != nil {
+			return newFieldError("proxy", errors.New("invalid configuration: Proxy is not valid for ingress traffic"))
+		}
+		if c.name == HostControllerName && c.timeout != nil {
+			return newFieldError("timeout", errors.New("invalid configuration: Timeout is not valid for host controller"))
+		}
+	} else if c.priority != nil {
+		return newFieldError("priority", errors.New("invalid configuration: Priority is not valid for egress traffic"))
 	}
 	return nil
 }
@@ -207,10 +313,57 @@ func (c *controller) Proxy() (Proxy, bool) {
 	return c.proxy, true
 }
 
+func (c *controller) CircuitBreaker() (CircuitBreaker, bool) {
+	if c.circuitBreaker == nil {
+		return nil, false
+	}
+	return c.circuitBreaker, true
+}
+
+func (c *controller) ConcurrencyLimiter() (ConcurrencyLimiter, bool) {
+	if c.concurrency == nil {
+		return nil, false
+	}
+	return c.concurrency, true
+}
+
+func (c *controller) Bulkhead() (Bulkhead, bool) {
+	if c.bulkhead == nil {
+		return nil, false
+	}
+	return c.bulkhead, true
+}
+
+func (c *controller) Hedge() (Hedge, bool) {
+	if c.hedge == nil {
+		return nil, false
+	}
+	return c.hedge, true
+}
+
+func (c *controller) Mirror() (Mirror, bool) {
+	if c.mirror == nil {
+		return nil, false
+	}
+	return c.mirror, true
+}
+
+func (c *controller) Priority() (Priority, bool) {
+	if c.priority == nil {
+		return nil, false
+	}
+	return c.priority, true
+}
+
 func (c *controller) t() *controller {
 	return c
 }
 
+// State - the controller state that is logged for every request
+func (c *controller) State() map[string]string {
+	return c.state()
+}
+
 func (c *controller) state() map[string]string {
 	state := make(map[string]string, 12)
 	state[ControllerName] = c.Name()
@@ -219,6 +372,7 @@ func (c *controller) state() map[string]string {
 	}
 	timeoutState(state, c.timeout)
 	rateLimiterState(state, c.rateLimiter)
+	concurrencyLimiterState(state, c.concurrency)
 	return state
 }
 
@@ -239,24 +393,50 @@ func (c *controller) LogHttpIngress(start time.Time, duration time.Duration, req
 	resp := new(http.Response)
 	resp.StatusCode = statusCode
 	resp.ContentLength = written
-	defaultLogFn("ingress", start, duration, req, resp, statusFlags, c.state())
+	state := c.state()
+	rateLimitKeyState(state, c.rateLimiter, req, statusFlags)
+	priorityState(state, c.priority, req)
+	defaultMetrics.record(IngressTraffic, state[ControllerName], duration, statusCode, statusFlags, 0)
+	traceState(req, statusCode, statusFlags, state)
+	defaultLogFn("ingress", start, duration, req, resp, statusFlags, state)
 }
 
-func (c *controller) LogHttpEgress(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, statusFlags string, retry bool) {
+func (c *controller) LogHttpEgress(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, statusFlags string, status EgressStatus) {
 	if c.name == NilControllerName {
 		return
 	}
 	state := c.state()
+	rateLimitKeyState(state, c.rateLimiter, req, statusFlags)
 	failoverState(state, c.failover)
-	retryState(state, c.retry, retry)
+	retryState(state, c.retry, status.Attempt > 1)
+	retryAttemptState(state, c.retry, status.Attempt)
+	retrySkipState(state, c.retry, status.RetryStatus)
+	hedgeState(state, c.hedge, status.Hedged)
+	proxyEndpointState(state, c.proxy, status.Endpoint)
+	proxyBranchState(state, c.proxy, status.Branch)
 	proxyState(state, c.proxy)
+	circuitBreakerState(state, c.circuitBreaker)
 
+	if !status.Retried {
+		latency := duration
+		if !status.Begin.IsZero() {
+			latency = start.Add(duration).Sub(status.Begin)
+		}
+		defaultMetrics.record(EgressTraffic, state[ControllerName], latency, responseStatusCode(resp), statusFlags, status.Attempt-1)
+	}
+	traceState(req, responseStatusCode(resp), statusFlags, state)
 	defaultLogFn(EgressTraffic, start, duration, req, resp, statusFlags, state)
 }
 
 func (c *controller) LogEgress(start time.Time, duration time.Duration, statusCode int, uri, requestId, method, statusFlags string) {
 	state := c.state()
 	failoverState(state, c.failover)
+	circuitBreakerState(state, c.circuitBreaker)
+	retryStatus := ""
+	if statusFlags == NotIdempotentFlag {
+		retryStatus = statusFlags
+	}
+	retrySkipState(state, c.retry, retryStatus)
 	//retryState(state, c.retry, false)
 	//proxyState(state, c.proxy)
 
@@ -265,5 +445,43 @@ func (c *controller) LogEgress(start time.Time, duration time.Duration, statusCo
 
 	resp := new(http.Response)
 	resp.StatusCode = statusCode
+	defaultMetrics.record(EgressTraffic, state[ControllerName], duration, statusCode, statusFlags, 0)
 	defaultLogFn(EgressTraffic, start, duration, req, resp, statusFlags, state)
 }
+
+// LogHttpMirror - log a mirrored request, the response has been discarded
+func (c *controller) LogHttpMirror(start time.Time, duration time.Duration, req *http.Request, resp *http.Response, statusFlags string) {
+	if c.name == NilControllerName {
+		return
+	}
+	state := c.state()
+	defaultMetrics.record(MirrorTraffic, state[ControllerName], duration, responseStatusCode(resp), statusFlags, 0)
+	defaultLogFn(MirrorTraffic, start, duration, req, resp, statusFlags, state)
+}
+
+// traceState - tag the span of the request with the route name and the controller state that is logged
+func traceState(req *http.Request, statusCode int, statusFlags string, state map[string]string) {
+	if req == nil {
+		return
+	}
+	span := tracing.FromContext(req.Context())
+	if span == nil {
+		return
+	}
+	span.SetAttribute(RouteNameAttribute, state[ControllerName])
+	span.SetAttribute(StatusFlagsAttribute, statusFlags)
+	span.SetAttribute(StatusCodeAttribute, strconv.Itoa(statusCode))
+	span.SetError(statusCode >= http.StatusInternalServerError)
+	for k, v := range state {
+		if k != ControllerName {
+			span.SetAttribute(StateAttributePrefix+k, v)
+		}
+	}
+}
+
+func responseStatusCode(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
@@ -80,9 +80,9 @@ func ExampleController_newController_Error() {
 
 	//Output:
 	//test: newController() -> [errs:[]]
-	//test: newController() -> [errs:[invalid configuration: Retry status codes are empty]]
-	//test: newController() -> [errs:[invalid configuration: Timeout duration is <= 0]]
-	//test: newController() -> [errs:[invalid configuration: Failover FailureInvoke function is nil]]
-	//test: newController() -> [errs:[invalid configuration: RateLimiter limit is < 0]]
+	//test: newController() -> [errs:[retry.codes: invalid configuration: Retry status codes are empty]]
+	//test: newController() -> [errs:[timeout.duration: invalid configuration: Timeout duration is <= 0]]
+	//test: newController() -> [errs:[failover.invoke: invalid configuration: Failover FailureInvoke function is nil]]
+	//test: newController() -> [errs:[rateLimiter.limit: invalid configuration: RateLimiter limit is < 0]]
 
 }
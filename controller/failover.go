@@ -2,7 +2,17 @@ package controller
 
 import (
 	"errors"
+	"github.com/gotemplates/core/runtime"
+	"github.com/gotemplates/host/messaging"
 	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultFailoverWindow = time.Second * 30
+
+	failoverBuckets = 10
 )
 
 type FailoverInvoke func(name string, failover bool)
@@ -13,22 +23,58 @@ type Failover interface {
 	Enable()
 	Disable()
 	Invoke(failover bool)
+	Record(failure, timeout bool)
 }
 
 type FailoverConfig struct {
-	Enabled bool
-	invoke  FailoverInvoke
+	Enabled      bool
+	ErrorRatio   float64       // ratio of failures, including timeouts, over the window that triggers failover, 0 is disabled
+	TimeoutRatio float64       // ratio of timeouts over the window that triggers failover, 0 is disabled
+	MinRequests  int           // requests in the window before a ratio is evaluated
+	Window       time.Duration // 0 is DefaultFailoverWindow
+	invoke       FailoverInvoke
 }
 
 func NewFailoverConfig(invoke FailoverInvoke) *FailoverConfig {
 	return &FailoverConfig{invoke: invoke}
 }
 
+// NewAutoFailoverConfig - failover is triggered, and recovered, by the observed health of the route, the invoke
+// function can be nil
+func NewAutoFailoverConfig(invoke FailoverInvoke, errorRatio, timeoutRatio float64, minRequests int, window time.Duration) *FailoverConfig {
+	c := NewFailoverConfig(invoke)
+	c.ErrorRatio = errorRatio
+	c.TimeoutRatio = timeoutRatio
+	c.MinRequests = minRequests
+	c.Window = window
+	return c
+}
+
+type failoverBucket struct {
+	id       int64
+	requests int
+	failures int
+	timeouts int
+}
+
+// failoverStats - sliding window of outcomes, shared between clones so that a transition is only made once. The
+// transitions that have not been dispatched are pending, and are dispatched in order
+type failoverStats struct {
+	mu          sync.Mutex
+	active      bool
+	since       time.Time
+	buckets     [failoverBuckets]failoverBucket
+	pending     []bool
+	dispatching bool
+}
+
 type failover struct {
 	table   *table
 	name    string
 	enabled bool
 	invoke  FailoverInvoke
+	config  FailoverConfig
+	state   *failoverStats
 }
 
 func cloneFailover(curr *failover) *failover {
@@ -43,18 +89,38 @@ func newFailover(name string, table *table, config *FailoverConfig) *failover {
 	t.name = name
 	if config != nil {
 		t.invoke = config.invoke
+		t.config = *config
+	}
+	if t.config.Window <= 0 {
+		t.config.Window = DefaultFailoverWindow
 	}
 	t.enabled = false
+	t.state = new(failoverStats)
 	return t
 }
 
+// validate - an automatic failover does not need an invoke function, the transition is broadcast as a
+// messaging.FailoverEvent
 func (f *failover) validate() error {
-	if f.invoke == nil {
-		return errors.New("invalid configuration: Failover FailureInvoke function is nil")
+	if f.invoke == nil && !f.isAuto() {
+		return newFieldError("invoke", errors.New("invalid configuration: Failover FailureInvoke function is nil"))
+	}
+	if f.config.ErrorRatio < 0 || f.config.ErrorRatio > 1 {
+		return newFieldError("errorRatio", errors.New("invalid configuration: Failover error ratio is not between 0 and 1"))
+	}
+	if f.config.TimeoutRatio < 0 || f.config.TimeoutRatio > 1 {
+		return newFieldError("timeoutRatio", errors.New("invalid configuration: Failover timeout ratio is not between 0 and 1"))
+	}
+	if f.isAuto() && f.config.MinRequests <= 0 {
+		return newFieldError("minRequests", errors.New("invalid configuration: Failover minimum requests is <= 0"))
 	}
 	return nil
 }
 
+func (f *failover) isAuto() bool {
+	return f.config.ErrorRatio > 0 || f.config.TimeoutRatio > 0
+}
+
 func failoverState(m map[string]string, f *failover) {
 	if f == nil {
 		m[FailoverName] = ""
@@ -69,6 +135,7 @@ func (f *failover) Disable() {
 	if !f.IsEnabled() {
 		return
 	}
+	f.state.set(false)
 	f.table.enableFailover(f.name, false)
 }
 
@@ -76,6 +143,7 @@ func (f *failover) Enable() {
 	if f.IsEnabled() {
 		return
 	}
+	f.state.set(true)
 	f.table.enableFailover(f.name, true)
 }
 
@@ -85,3 +153,104 @@ func (f *failover) Invoke(failover bool) {
 	}
 	f.invoke(f.name, failover)
 }
+
+// Record - record the outcome of a request. Failover is triggered when the error or timeout ratio over the window
+// reaches the configured ratio, and recovered once failover has been active for the window and the ratios are
+// below the configured ratios, or there are too few requests to evaluate them.
+func (f *failover) Record(failure, timeout bool) {
+	if !f.isAuto() {
+		return
+	}
+	active, changed := f.state.record(f.config, failure || timeout, timeout, time.Now())
+	if !changed {
+		return
+	}
+	f.table.enableFailover(f.name, active)
+	// The invoke function and the broadcast are not run on the request goroutine
+	f.state.dispatch(active, f.notify)
+}
+
+// notify - invoke the failover function, and broadcast the transition
+func (f *failover) notify(active bool) {
+	f.Invoke(active)
+	messaging.Broadcast(messaging.Message{From: f.name, Event: messaging.FailoverEvent, Status: runtime.NewStatusOK(),
+		Content: []any{messaging.FailoverStatus{Name: f.name, Failover: active}}})
+}
+
+// dispatch - run fn once for a transition, transitions are run in order by a single goroutine
+func (s *failoverStats) dispatch(active bool, fn func(active bool)) {
+	s.mu.Lock()
+	s.pending = append(s.pending, active)
+	if s.dispatching {
+		s.mu.Unlock()
+		return
+	}
+	s.dispatching = true
+	s.mu.Unlock()
+	go func() {
+		for {
+			s.mu.Lock()
+			if len(s.pending) == 0 {
+				s.dispatching = false
+				s.mu.Unlock()
+				return
+			}
+			active := s.pending[0]
+			s.pending = s.pending[1:]
+			s.mu.Unlock()
+			fn(active)
+		}
+	}()
+}
+
+func (s *failoverStats) set(active bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active != active {
+		s.active = active
+		s.since = time.Now()
+	}
+}
+
+func (s *failoverStats) record(config FailoverConfig, failure, timeout bool, now time.Time) (active, changed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	width := int64(config.Window / failoverBuckets)
+	if width <= 0 {
+		width = 1
+	}
+	id := now.UnixNano() / width
+	b := &s.buckets[id%failoverBuckets]
+	if b.id != id {
+		*b = failoverBucket{id: id}
+	}
+	b.requests++
+	if failure {
+		b.failures++
+	}
+	if timeout {
+		b.timeouts++
+	}
+	requests, failures, timeouts := 0, 0, 0
+	for _, b := range s.buckets {
+		if b.id > id-failoverBuckets {
+			requests += b.requests
+			failures += b.failures
+			timeouts += b.timeouts
+		}
+	}
+	unhealthy := requests >= config.MinRequests &&
+		((config.ErrorRatio > 0 && float64(failures) >= config.ErrorRatio*float64(requests)) ||
+			(config.TimeoutRatio > 0 && float64(timeouts) >= config.TimeoutRatio*float64(requests)))
+	if !s.active && unhealthy {
+		s.active = true
+		s.since = now
+		return true, true
+	}
+	if s.active && !unhealthy && now.Sub(s.since) >= config.Window {
+		s.active = false
+		s.since = now
+		return false, true
+	}
+	return s.active, false
+}
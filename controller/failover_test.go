@@ -1,6 +1,10 @@
 package controller
 
-import "fmt"
+import (
+	"fmt"
+	"github.com/gotemplates/host/messaging"
+	"time"
+)
 
 var failoverFn FailoverInvoke = func(name string, failover bool) { fmt.Printf("test: Invoke(%v,%v)\n", name, failover) }
 
@@ -10,6 +14,9 @@ func Example_newFailover() {
 	f := newFailover(name, nil, nil)
 	fmt.Printf("test: newFailover(nil) -> [enabled:%v] [validate:%v]\n", f.enabled, f.validate())
 
+	f = newFailover(name, nil, NewAutoFailoverConfig(nil, 0.5, 0, 10, 0))
+	fmt.Printf("test: newFailover(auto,nil) -> [enabled:%v] [validate:%v]\n", f.enabled, f.validate())
+
 	f = newFailover(name, nil, NewFailoverConfig(failoverFn))
 	fmt.Printf("test: newFailover(testFn) -> [enabled:%v] [validate:%v]\n", f.enabled, f.validate())
 
@@ -29,7 +36,8 @@ func Example_newFailover() {
 	fmt.Printf("test: failoverState(map,f2) -> %v\n", m)
 
 	//Output:
-	//test: newFailover(nil) -> [enabled:false] [validate:invalid configuration: Failover FailureInvoke function is nil]
+	//test: newFailover(nil) -> [enabled:false] [validate:invoke: invalid configuration: Failover FailureInvoke function is nil]
+	//test: newFailover(auto,nil) -> [enabled:false] [validate:<nil>]
 	//test: newFailover(testFn) -> [enabled:false] [validate:<nil>]
 	//test: cloneFailover(f1) -> [f2-enabled:true] [f2-validate:<nil>]
 	//test: failoverState(map,nil) -> map[failover:]
@@ -99,3 +107,68 @@ func Example_Failover_Invoke() {
 	//test: Invoke(failover-test,false)
 	//test: Invoke(false) -> []
 }
+
+func Example_Failover_Record() {
+	name := "failover-auto"
+	t := newTable(true, false)
+	c := make(chan messaging.Message, 4)
+	messaging.RegisterResource("urn:failover-test", c)
+
+	err := t.AddController(newRoute(name, NewAutoFailoverConfig(failoverFn, 0.5, 0, 4, time.Hour)))
+	fmt.Printf("test: Add() -> [error:%v] [count:%v]\n", err, t.count())
+
+	f, _ := t.LookupByName(name).Failover()
+	f.Record(false, false)
+	f.Record(true, false)
+	f.Record(false, false)
+	fmt.Printf("test: Record() -> [enabled:%v]\n", t.LookupByName(name).t().failover.IsEnabled())
+
+	// The invoke function and the broadcast are dispatched asynchronously, the invoke function is first
+	f.Record(true, true)
+	msg := <-c
+	fmt.Printf("test: Record() -> [enabled:%v]\n", t.LookupByName(name).t().failover.IsEnabled())
+	status, _ := messaging.AccessFailoverStatus(&msg)
+	fmt.Printf("test: Broadcast() -> [from:%v] [event:%v] [status:%v]\n", msg.From, msg.Event, status)
+
+	//Output:
+	//test: Add() -> [error:[]] [count:1]
+	//test: Record() -> [enabled:false]
+	//test: Invoke(failover-auto,true)
+	//test: Record() -> [enabled:true]
+	//test: Broadcast() -> [from:failover-auto] [event:event:failover] [status:{failover-auto true}]
+}
+
+func Example_Failover_Window() {
+	config := FailoverConfig{TimeoutRatio: 0.5, MinRequests: 2, Window: time.Second * 10}
+	s := new(failoverStats)
+	now := time.Now()
+
+	active, changed := s.record(config, true, true, now)
+	fmt.Printf("test: record(timeout) -> [active:%v] [changed:%v]\n", active, changed)
+	active, changed = s.record(config, false, false, now)
+	fmt.Printf("test: record(success) -> [active:%v] [changed:%v]\n", active, changed)
+
+	// Healthy, but failover has not been active for the window
+	now = now.Add(time.Second * 5)
+	s.record(config, false, false, now)
+	active, changed = s.record(config, false, false, now)
+	fmt.Printf("test: record(+5s) -> [active:%v] [changed:%v]\n", active, changed)
+
+	// The failed requests are no longer in the window
+	now = now.Add(time.Second * 6)
+	active, changed = s.record(config, false, false, now)
+	fmt.Printf("test: record(+11s) -> [active:%v] [changed:%v]\n", active, changed)
+
+	f := newFailover("test", nil, NewAutoFailoverConfig(failoverFn, 1.5, 0, 1, 0))
+	fmt.Printf("test: validate() -> %v\n", f.validate())
+	f = newFailover("test", nil, NewAutoFailoverConfig(failoverFn, 0.5, 0, 0, 0))
+	fmt.Printf("test: validate() -> %v\n", f.validate())
+
+	//Output:
+	//test: record(timeout) -> [active:false] [changed:false]
+	//test: record(success) -> [active:true] [changed:true]
+	//test: record(+5s) -> [active:true] [changed:false]
+	//test: record(+11s) -> [active:false] [changed:true]
+	//test: validate() -> errorRatio: invalid configuration: Failover error ratio is not between 0 and 1
+	//test: validate() -> minRequests: invalid configuration: Failover minimum requests is <= 0
+}
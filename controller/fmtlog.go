@@ -61,3 +61,23 @@ func FmtLog(traffic string, start time.Time, duration time.Duration, req *http.R
 
 	return s
 }
+
+func FmtAudit(entry AuditEntry) string {
+	return fmt.Sprintf("start:%v, "+
+		"traffic:%v, "+
+		"route:%v, "+
+		"user:%v, "+
+		"request-id:%v, "+
+		"field:%v, "+
+		"old:%v, "+
+		"new:%v",
+		FmtTimestamp(entry.Time),
+		entry.Traffic,
+		entry.Route,
+		entry.User,
+		entry.RequestId,
+		entry.Field,
+		entry.Old,
+		entry.New,
+	)
+}
@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHedgeBudget = 10
+
+	hedgeSamples    = 128
+	hedgeMinSamples = 20
+	hedgeRecompute  = 16 // Samples recorded before the percentile delay is recomputed
+)
+
+// https://research.google/pubs/pub40801/ - The Tail at Scale
+
+// Hedge - interface for hedged requests
+type Hedge interface {
+	IsEnabled() bool
+	Enable()
+	Disable()
+	Delay() time.Duration
+	Allow() bool
+	IsHedgeableMethod(method string) bool
+	Request()
+	Record(latency time.Duration)
+	SetDelay(delay time.Duration)
+}
+
+type HedgeConfig struct {
+	Delay      time.Duration // Wait before sending the hedged request, used until there are enough samples for Percentile
+	Percentile float64       // Send the hedged request once the original request is slower than this percentile, 0 is disabled
+	Budget     float64       // Hedged requests as a percentage of recent requests, 0 is DefaultHedgeBudget
+}
+
+func NewHedgeConfig(delay time.Duration, percentile, budget float64) *HedgeConfig {
+	c := new(HedgeConfig)
+	c.Delay = delay
+	c.Percentile = percentile
+	c.Budget = budget
+	return c
+}
+
+// hedgeStats - latency samples and budget, shared between clones. The percentile delay is cached, and recomputed
+// from the samples every hedgeRecompute samples
+type hedgeStats struct {
+	mu         sync.Mutex
+	latencies  [hedgeSamples]time.Duration
+	next       int
+	count      int
+	stale      int
+	percentile float64
+	delay      time.Duration
+	budget     retryBudget
+}
+
+// update - recompute the percentile delay, the lock must be held
+func (s *hedgeStats) update(percentile float64) {
+	samples := make([]time.Duration, s.count)
+	copy(samples, s.latencies[:s.count])
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	s.delay = samples[int(float64(len(samples))*percentile/100)]
+	s.percentile = percentile
+	s.stale = 0
+}
+
+type hedge struct {
+	table   *table
+	name    string
+	enabled bool
+	config  HedgeConfig
+	state   *hedgeStats
+}
+
+func cloneHedge(curr *hedge) *hedge {
+	t := new(hedge)
+	*t = *curr
+	return t
+}
+
+func newHedge(name string, table *table, config *HedgeConfig) *hedge {
+	t := new(hedge)
+	t.table = table
+	t.name = name
+	t.enabled = true
+	if config != nil {
+		t.config = *config
+	}
+	t.state = new(hedgeStats)
+	return t
+}
+
+func (h *hedge) validate() error {
+	if h.config.Delay <= 0 {
+		return newFieldError("delay", errors.New("invalid configuration: Hedge delay is <= 0"))
+	}
+	if h.config.Percentile < 0 || h.config.Percentile >= 100 {
+		return newFieldError("percentile", errors.New("invalid configuration: Hedge percentile is not between 0 and 100"))
+	}
+	if h.config.Budget < 0 || h.config.Budget > 100 {
+		return newFieldError("budget", errors.New("invalid configuration: Hedge budget is not between 0 and 100"))
+	}
+	return nil
+}
+
+func hedgeState(m map[string]string, h *hedge, hedged bool) {
+	if h == nil {
+		m[HedgeName] = ""
+	} else {
+		m[HedgeName] = strconv.FormatBool(hedged)
+	}
+}
+
+func (h *hedge) IsEnabled() bool { return h.enabled }
+
+func (h *hedge) Disable() {
+	if !h.IsEnabled() {
+		return
+	}
+	h.table.enableHedge(h.name, false)
+}
+
+func (h *hedge) Enable() {
+	if h.IsEnabled() {
+		return
+	}
+	h.table.enableHedge(h.name, true)
+}
+
+func (h *hedge) SetDelay(delay time.Duration) {
+	if delay <= 0 || h.config.Delay == delay {
+		return
+	}
+	h.table.setHedgeDelay(h.name, delay)
+}
+
+// Delay - wait before sending the hedged request
+func (h *hedge) Delay() time.Duration {
+	if h.config.Percentile <= 0 {
+		return h.config.Delay
+	}
+	s := h.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count < hedgeMinSamples {
+		return h.config.Delay
+	}
+	// The cached delay is for a different percentile after a configuration change
+	if s.percentile != h.config.Percentile {
+		s.update(h.config.Percentile)
+	}
+	return s.delay
+}
+
+// Allow - determine if a hedged request can be sent within the budget
+func (h *hedge) Allow() bool {
+	if !h.IsEnabled() {
+		return false
+	}
+	budget := h.config.Budget
+	if budget == 0 {
+		budget = DefaultHedgeBudget
+	}
+	return h.state.budget.allow(budget)
+}
+
+// IsHedgeableMethod - determine if a request can be sent twice, only idempotent methods are hedged
+func (h *hedge) IsHedgeableMethod(method string) bool {
+	return isIdempotentMethod(method)
+}
+
+// Request - count a request that can be hedged in the budget
+func (h *hedge) Request() {
+	h.state.budget.request()
+}
+
+// Record - record the latency of the original request, the latency of a hedged request is not recorded
+func (h *hedge) Record(latency time.Duration) {
+	s := h.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latencies[s.next] = latency
+	s.next = (s.next + 1) % hedgeSamples
+	if s.count < hedgeSamples {
+		s.count++
+	}
+	s.stale++
+	if h.config.Percentile > 0 && s.count >= hedgeMinSamples && (s.stale >= hedgeRecompute || s.percentile != h.config.Percentile) {
+		s.update(h.config.Percentile)
+	}
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+)
+
+func Example_newHedge() {
+	t := newHedge("test-route", newTable(true, false), NewHedgeConfig(time.Millisecond*50, 0, 0))
+	fmt.Printf("test: newHedge() -> [name:%v] [config:%v] [delay:%v]\n", t.name, t.config, t.Delay())
+
+	t.config.Percentile = 100
+	fmt.Printf("test: validate() -> [%v]\n", t.validate())
+
+	m := make(map[string]string)
+	hedgeState(m, nil, false)
+	fmt.Printf("test: hedgeState(nil) -> %v\n", m)
+	hedgeState(m, t, true)
+	fmt.Printf("test: hedgeState(t,true) -> %v\n", m)
+
+	//Output:
+	//test: newHedge() -> [name:test-route] [config:{50ms 0 0}] [delay:50ms]
+	//test: validate() -> [percentile: invalid configuration: Hedge percentile is not between 0 and 100]
+	//test: hedgeState(nil) -> map[hedge:]
+	//test: hedgeState(t,true) -> map[hedge:true]
+
+}
+
+func Example_Hedge_Percentile() {
+	t := newHedge("test-route", newTable(true, false), NewHedgeConfig(time.Millisecond*50, 90, 0))
+	for i := 1; i <= hedgeMinSamples-1; i++ {
+		t.Record(time.Millisecond * time.Duration(i))
+	}
+	fmt.Printf("test: Delay(samples:%v) -> [%v]\n", hedgeMinSamples-1, t.Delay())
+
+	for i := hedgeMinSamples; i <= 100; i++ {
+		t.Record(time.Millisecond * time.Duration(i))
+	}
+	fmt.Printf("test: Delay(samples:100) -> [%v]\n", t.Delay())
+
+	// The cached delay is recomputed after hedgeRecompute samples
+	for i := 1; i < hedgeRecompute; i++ {
+		t.Record(time.Second)
+	}
+	fmt.Printf("test: Delay(samples:%v) -> [%v]\n", 100+hedgeRecompute-1, t.Delay())
+	t.Record(time.Second)
+	fmt.Printf("test: Delay(samples:%v) -> [%v]\n", 100+hedgeRecompute, t.Delay())
+
+	//Output:
+	//test: Delay(samples:19) -> [50ms]
+	//test: Delay(samples:100) -> [91ms]
+	//test: Delay(samples:115) -> [91ms]
+	//test: Delay(samples:116) -> [1s]
+
+}
+
+func Example_Hedge_Budget() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewHedgeConfig(time.Millisecond, 0, 20)))
+	fmt.Printf("test: Add() -> [%v] [count:%v]\n", errs, t.count())
+
+	h, _ := t.LookupByName(name).Hedge()
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		h.Request()
+		if h.Allow() {
+			allowed++
+		}
+	}
+	fmt.Printf("test: Allow(budget:20%%) -> [requests:10] [hedges:%v]\n", allowed)
+
+	h.Disable()
+	h, _ = t.LookupByName(name).Hedge()
+	fmt.Printf("test: Disable() -> [enabled:%v] [allow:%v]\n", h.IsEnabled(), h.Allow())
+
+	h.SetDelay(time.Second)
+	h, _ = t.LookupByName(name).Hedge()
+	fmt.Printf("test: SetDelay() -> [delay:%v]\n", h.Delay())
+
+	//Output:
+	//test: Add() -> [[]] [count:1]
+	//test: Allow(budget:20%) -> [requests:10] [hedges:2]
+	//test: Disable() -> [enabled:false] [allow:false]
+	//test: SetDelay() -> [delay:1s]
+
+}
@@ -1,80 +1,120 @@
 package controller
 
 import (
-	"encoding/json"
 	"errors"
+	"github.com/gotemplates/host/decode"
 )
 
 const (
 	DefaultIngressRouteName = "default-ingress"
 	DefaultEgressRouteName  = "default-egress"
+	RoutesTableName         = "routes"
 )
 
-// ReadRoutes - read routes from the []byte representation of a route configuration
+// ReadRoutes - read routes from the []byte representation of a route configuration. Unknown fields are rejected, and
+// the error includes every problem in every route, with its line and column and the path of the field
 func ReadRoutes(buf []byte) ([]Route, error) {
-	var config []RouteConfig
-
 	if buf == nil {
 		return nil, errors.New("invalid argument: buffer is nil")
 	}
-	err1 := json.Unmarshal(buf, &config)
-	if err1 != nil {
-		return nil, err1
+	_, routes, errs := readRoutesJSON(buf)
+	if len(errs) > 0 {
+		return nil, joinErrors(errs)
 	}
-	var routes []Route
-	for _, c := range config {
-		r, err := NewRouteFromConfig(c)
-		if err != nil {
-			return nil, err
-		}
-		routes = append(routes, r)
+	return routes, nil
+}
+
+// ReadRoutesYAML - read routes from a YAML sequence of route configurations, errors include the line and column of
+// the route configuration. Field names are matched as ReadRoutes does, and a JSON configuration is also valid YAML
+func ReadRoutesYAML(buf []byte) ([]Route, error) {
+	doc, err := decode.YAML(buf)
+	if err != nil {
+		return nil, err
+	}
+	routes, errs := readRoutes(doc.Nest(RoutesTableName))
+	if len(errs) > 0 {
+		return nil, joinErrors(errs)
 	}
 	return routes, nil
 }
 
-// AddEgressRoutes - read the routes from the []byte and create the EgressTable controller entries
-func AddEgressRoutes(buf []byte) ([]Route, []error) {
-	routes, err := ReadRoutes(buf)
+// ReadRoutesTOML - read routes from a TOML array of tables named routes, [[routes]], errors include the line and
+// column of the route configuration
+func ReadRoutesTOML(buf []byte) ([]Route, error) {
+	doc, err := decode.TOML(buf)
 	if err != nil {
-		return routes, []error{err}
+		return nil, err
+	}
+	routes, errs := readRoutes(doc)
+	if len(errs) > 0 {
+		return nil, joinErrors(errs)
 	}
-	var errs []error
-	for _, r := range routes {
+	return routes, nil
+}
+
+// readRoutesJSON - read the routes of a JSON route configuration, and the document for the position of errors
+func readRoutesJSON(buf []byte) (*decode.Document, []Route, []error) {
+	doc, err := decode.JSON(buf)
+	if err != nil {
+		return nil, nil, []error{err}
+	}
+	doc = doc.Nest(RoutesTableName)
+	routes, errs := readRoutes(doc)
+	return doc, routes, errs
+}
+
+// AddEgressRoutes - read the routes from the []byte and create the EgressTable controller entries. The errors of
+// every route are returned
+func AddEgressRoutes(buf []byte) ([]Route, []error) {
+	doc, routes, errs := readRoutesJSON(buf)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	for i, r := range routes {
+		var errs1 []error
 		switch r.Name {
 		case DefaultEgressRouteName:
-			errs = EgressTable.SetDefaultController(r)
+			errs1 = EgressTable.SetDefaultController(r)
 		default:
-			errs = EgressTable.AddController(r)
+			errs1 = EgressTable.AddController(r)
 		}
-		if len(errs) > 0 {
-			return nil, errs
+		for _, err := range errs1 {
+			errs = append(errs, withPath(decode.Index("", i), err))
 		}
 	}
+	if len(errs) > 0 {
+		return nil, routeErrors(doc, errs)
+	}
 	return routes, nil
 }
 
-// AddIngressRoutes - read the routes from the []byte and create the IngressTable controller entries
+// AddIngressRoutes - read the routes from the []byte and create the IngressTable controller entries. The errors of
+// every route are returned
 func AddIngressRoutes(buf []byte) ([]Route, []error) {
-	routes, err := ReadRoutes(buf)
-	if err != nil {
-		return nil, []error{err}
+	doc, routes, errs := readRoutesJSON(buf)
+	if len(errs) > 0 {
+		return nil, errs
 	}
-	var errs []error
-	for _, r := range routes {
+	for i, r := range routes {
+		var errs1 []error
 		switch r.Name {
 		case HostControllerName:
-			errs = IngressTable.SetHostController(r)
+			errs1 = IngressTable.SetHostController(r)
 		case DefaultIngressRouteName:
-			errs = IngressTable.SetDefaultController(r)
+			errs1 = IngressTable.SetDefaultController(r)
 		default:
-			errs = IngressTable.AddController(r)
+			errs1 = IngressTable.AddController(r)
 		}
-		if len(errs) > 0 {
-			return nil, errs
+		for _, err := range errs1 {
+			errs = append(errs, withPath(decode.Index("", i), err))
 		}
 	}
+	if len(errs) > 0 {
+		return nil, routeErrors(doc, errs)
+	}
 	return routes, nil
 }
+
 func InitEgressControllers(read func() ([]byte, error), update func(routes []Route) error) []error {
 	if read == nil || update == nil {
 		return []error{errors.New("invalid argument: read or updater function is nil")}
@@ -1 +1,107 @@
 package controller
+
+import (
+	"fmt"
+)
+
+const routesYAML = `
+- name: google-search
+  pattern: google.com/search
+  rateLimiter:
+    limit: 100
+    burst: 25
+  timeout:
+    duration: 500ms
+- name: facebook
+  pattern: facebook.com
+  failover:
+    enabled: true
+  proxy:
+    pattern: http://localhost:8080
+    canaryPercentage: 10
+`
+
+const routesTOML = `
+[[routes]]
+Name = "google-search"
+Pattern = "google.com/search"
+RateLimiter = { Limit = 100, Burst = 25 }
+Timeout = { Duration = "500ms" }
+
+[[routes]]
+Name = "facebook"
+Pattern = "facebook.com"
+
+[routes.Failover]
+Enabled = true
+
+[routes.Proxy]
+Pattern = "http://localhost:8080"
+CanaryPercentage = 10
+`
+
+func ExampleReadRoutes() {
+	routes, err := ReadRoutes([]byte(`[{"Name":"google-search","Pattern":"google.com/search","Timeout":{"Duration":"500ms"}}]`))
+	fmt.Printf("test: ReadRoutes() -> [err:%v] [count:%v] [timeout:%v]\n", err, len(routes), *routes[0].Timeout)
+
+	_, err = ReadRoutes([]byte(`[
+	{"Name":"google-search","Pattern":"google.com/search","Timeout":{"Duration":"500ms","Status":504}},
+	{"Name":"facebook","Pattern":"facebook.com","Timeout":{"Duration":"5x"}}
+]`))
+	fmt.Printf("test: ReadRoutes(invalid) -> [err:%v]\n", err)
+
+	_, err = ReadRoutes(nil)
+	fmt.Printf("test: ReadRoutes(nil) -> [err:%v]\n", err)
+
+	//Output:
+	//test: ReadRoutes() -> [err:<nil>] [count:1] [timeout:{500ms 504}]
+	//test: ReadRoutes(invalid) -> [err:line 2, column 86: routes[0].Timeout.Status: unknown field; line 3, column 57: routes[1].timeout.duration: strconv.Atoi: parsing "5x": invalid syntax]
+	//test: ReadRoutes(nil) -> [err:invalid argument: buffer is nil]
+
+}
+
+func ExampleReadRoutesYAML() {
+	routes, err := ReadRoutesYAML([]byte(routesYAML))
+	fmt.Printf("test: ReadRoutesYAML() -> [err:%v] [count:%v]\n", err, len(routes))
+	fmt.Printf("test: ReadRoutesYAML() -> [%v] [rate-limiter:%v] [timeout:%v]\n", routes[0].Name, *routes[0].RateLimiter, *routes[0].Timeout)
+	fmt.Printf("test: ReadRoutesYAML() -> [%v] [failover:%v] [proxy:%v] [canary:%v]\n", routes[1].Name, routes[1].Failover.Enabled, routes[1].Proxy.Pattern, routes[1].Proxy.CanaryPercentage)
+
+	_, err = ReadRoutesYAML([]byte("- name: google-search\n  rateLimiter:\n    limit: fast\n"))
+	fmt.Printf("test: ReadRoutesYAML(limit) -> [err:%v]\n", err)
+
+	_, err = ReadRoutesYAML([]byte("- name: google-search\n- name: facebook\n  timeout:\n    duration: 5x\n"))
+	fmt.Printf("test: ReadRoutesYAML(duration) -> [err:%v]\n", err)
+
+	routes, err = ReadRoutesYAML([]byte(`[{"Name":"google-search","Pattern":"google.com/search"}]`))
+	fmt.Printf("test: ReadRoutesYAML(json) -> [err:%v] [%v]\n", err, routes[0].Pattern)
+
+	//Output:
+	//test: ReadRoutesYAML() -> [err:<nil>] [count:2]
+	//test: ReadRoutesYAML() -> [google-search] [rate-limiter:{100 25 429  0 0s false false }] [timeout:{500ms 504}]
+	//test: ReadRoutesYAML() -> [facebook] [failover:true] [proxy:http://localhost:8080] [canary:10]
+	//test: ReadRoutesYAML(limit) -> [err:line 3, column 5: cannot unmarshal string into routes[0].rateLimiter.limit of type rate.Limit]
+	//test: ReadRoutesYAML(duration) -> [err:line 4, column 5: routes[1].timeout.duration: strconv.Atoi: parsing "5x": invalid syntax]
+	//test: ReadRoutesYAML(json) -> [err:<nil>] [google.com/search]
+
+}
+
+func ExampleReadRoutesTOML() {
+	routes, err := ReadRoutesTOML([]byte(routesTOML))
+	fmt.Printf("test: ReadRoutesTOML() -> [err:%v] [count:%v]\n", err, len(routes))
+	fmt.Printf("test: ReadRoutesTOML() -> [%v] [rate-limiter:%v] [timeout:%v]\n", routes[0].Name, *routes[0].RateLimiter, *routes[0].Timeout)
+	fmt.Printf("test: ReadRoutesTOML() -> [%v] [failover:%v] [proxy:%v] [canary:%v]\n", routes[1].Name, routes[1].Failover.Enabled, routes[1].Proxy.Pattern, routes[1].Proxy.CanaryPercentage)
+
+	_, err = ReadRoutesTOML([]byte("[[routes]]\nName = \"google-search\"\n\n[routes.Proxy]\nCanaryPercentage = \"ten\"\n"))
+	fmt.Printf("test: ReadRoutesTOML(canary) -> [err:%v]\n", err)
+
+	_, err = ReadRoutesTOML([]byte("[[routes]]\nName = \"google-search\"\nPattern = google.com\n"))
+	fmt.Printf("test: ReadRoutesTOML(syntax) -> [err:%v]\n", err)
+
+	//Output:
+	//test: ReadRoutesTOML() -> [err:<nil>] [count:2]
+	//test: ReadRoutesTOML() -> [google-search] [rate-limiter:{100 25 429  0 0s false false }] [timeout:{500ms 504}]
+	//test: ReadRoutesTOML() -> [facebook] [failover:true] [proxy:http://localhost:8080] [canary:10]
+	//test: ReadRoutesTOML(canary) -> [err:line 5, column 1: cannot unmarshal string into routes[0].Proxy.CanaryPercentage of type int]
+	//test: ReadRoutesTOML(syntax) -> [err:line 3, column 11: incomplete number]
+
+}
@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"golang.org/x/time/rate"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RemoteAddrKey      = "remote-addr"
+	ForwardedForKey    = "x-forwarded-for"
+	ApiKey             = "api-key"
+	HeaderKeyPrefix    = "header:"
+	QueryKeyPrefix     = "query:"
+	ForwardedForHeader = "X-Forwarded-For"
+	ApiKeyHeaderName   = "X-API-Key"
+)
+
+type keyedLimiter struct {
+	key      string
+	limiter  *rate.Limiter
+	lastSeen time.Time
+	status   *StoreStatus // Latest store status of a global limiter
+	statusAt time.Time
+}
+
+// keyedLimiters - token buckets by key, bounded by a least recently used list, shared between clones
+type keyedLimiters struct {
+	mu          sync.Mutex
+	maxKeys     int
+	idleTimeout time.Duration
+	lru         *list.List
+	m           map[string]*list.Element
+}
+
+func newKeyedLimiters(maxKeys int, idleTimeout time.Duration) *keyedLimiters {
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultIdleTimeout
+	}
+	return &keyedLimiters{maxKeys: maxKeys, idleTimeout: idleTimeout, lru: list.New(), m: make(map[string]*list.Element)}
+}
+
+// get - the limiter for a key, keys that have been idle for the idle timeout, or are the least recently used when
+// there are too many keys, are evicted
+func (k *keyedLimiters) get(key string, limit rate.Limit, burst int, now time.Time) *rate.Limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.entry(key, limit, burst, now).limiter
+}
+
+func (k *keyedLimiters) entry(key string, limit rate.Limit, burst int, now time.Time) *keyedLimiter {
+	for e := k.lru.Back(); e != nil; e = k.lru.Back() {
+		if now.Sub(e.Value.(*keyedLimiter).lastSeen) < k.idleTimeout {
+			break
+		}
+		k.remove(e)
+	}
+	if e, ok := k.m[key]; ok {
+		l := e.Value.(*keyedLimiter)
+		l.lastSeen = now
+		k.lru.MoveToFront(e)
+		return l
+	}
+	l := &keyedLimiter{key: key, limiter: rate.NewLimiter(limit, burst), lastSeen: now}
+	k.m[key] = k.lru.PushFront(l)
+	for k.lru.Len() > k.maxKeys {
+		k.remove(k.lru.Back())
+	}
+	return l
+}
+
+// setStatus - record the latest store status of a key, a nil status is a store error
+func (k *keyedLimiters) setStatus(key string, status *StoreStatus, limit rate.Limit, burst int, now time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	l := k.entry(key, limit, burst, now)
+	l.status = status
+	l.statusAt = now
+}
+
+// status - the latest store status of a key, and when it was recorded
+func (k *keyedLimiters) status(key string) (*StoreStatus, time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if e, ok := k.m[key]; ok {
+		l := e.Value.(*keyedLimiter)
+		return l.status, l.statusAt
+	}
+	return nil, time.Time{}
+}
+
+func (k *keyedLimiters) remove(e *list.Element) {
+	k.lru.Remove(e)
+	delete(k.m, e.Value.(*keyedLimiter).key)
+}
+
+// peek - the limiter for a key, without creating it or updating its use
+func (k *keyedLimiters) peek(key string) *rate.Limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if e, ok := k.m[key]; ok {
+		return e.Value.(*keyedLimiter).limiter
+	}
+	return nil
+}
+
+// set - update the limit and burst of all keys
+func (k *keyedLimiters) set(limit rate.Limit, burst int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for e := k.lru.Front(); e != nil; e = e.Next() {
+		l := e.Value.(*keyedLimiter)
+		l.limiter.SetLimit(limit)
+		l.limiter.SetBurst(burst)
+	}
+}
+
+func (k *keyedLimiters) count() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.lru.Len()
+}
+
+func validateKey(key string) error {
+	switch {
+	case key == "", key == RemoteAddrKey, key == ForwardedForKey, key == ApiKey:
+		return nil
+	case strings.HasPrefix(key, HeaderKeyPrefix) && len(key) > len(HeaderKeyPrefix):
+		return nil
+	case strings.HasPrefix(key, QueryKeyPrefix) && len(key) > len(QueryKeyPrefix):
+		return nil
+	}
+	return newFieldError("key", errors.New(fmt.Sprintf("invalid configuration: RateLimiter key is invalid [%v]", key)))
+}
+
+// extractKey - the rate limiter key of a request, the first X-Forwarded-For address is the client address
+func extractKey(key string, req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	switch {
+	case key == RemoteAddrKey:
+		return remoteHost(req)
+	case key == ForwardedForKey:
+		if forwarded := req.Header.Get(ForwardedForHeader); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+		return remoteHost(req)
+	case key == ApiKey:
+		return req.Header.Get(ApiKeyHeaderName)
+	case strings.HasPrefix(key, HeaderKeyPrefix):
+		return req.Header.Get(key[len(HeaderKeyPrefix):])
+	case strings.HasPrefix(key, QueryKeyPrefix):
+		if req.URL == nil {
+			return ""
+		}
+		return req.URL.Query().Get(key[len(QueryKeyPrefix):])
+	}
+	return ""
+}
+
+func remoteHost(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func Example_extractKey() {
+	req, _ := http.NewRequest("GET", "https://www.google.com/search?q=test&tenant=acme", nil)
+	req.RemoteAddr = "10.1.1.1:5000"
+	req.Header.Set(ForwardedForHeader, "192.168.1.1, 10.0.0.1")
+	req.Header.Set(ApiKeyHeaderName, "secret")
+	req.Header.Set("X-User-Id", "user-1")
+
+	for _, key := range []string{RemoteAddrKey, ForwardedForKey, ApiKey, "header:X-User-Id", "query:tenant"} {
+		fmt.Printf("test: extractKey(%v) -> [%v] [validate:%v]\n", key, extractKey(key, req), validateKey(key))
+	}
+	fmt.Printf("test: validateKey(header:) -> %v\n", validateKey("header:"))
+
+	//Output:
+	//test: extractKey(remote-addr) -> [10.1.1.1] [validate:<nil>]
+	//test: extractKey(x-forwarded-for) -> [192.168.1.1] [validate:<nil>]
+	//test: extractKey(api-key) -> [secret] [validate:<nil>]
+	//test: extractKey(header:X-User-Id) -> [user-1] [validate:<nil>]
+	//test: extractKey(query:tenant) -> [acme] [validate:<nil>]
+	//test: validateKey(header:) -> key: invalid configuration: RateLimiter key is invalid [header:]
+
+}
+
+func Example_keyedLimiters() {
+	k := newKeyedLimiters(2, time.Minute)
+	now := time.Now()
+
+	k.get("a", 1, 1, now)
+	k.get("b", 1, 1, now)
+	k.get("a", 1, 1, now)
+	k.get("c", 1, 1, now)
+	_, a := k.m["a"]
+	_, b := k.m["b"]
+	fmt.Printf("test: get(max-keys) -> [count:%v] [a:%v] [b:%v]\n", k.count(), a, b)
+
+	k.get("d", 1, 1, now.Add(time.Minute*2))
+	fmt.Printf("test: get(idle) -> [count:%v]\n", k.count())
+
+	//Output:
+	//test: get(max-keys) -> [count:2] [a:true] [b:false]
+	//test: get(idle) -> [count:1]
+
+}
+
+func Example_RateLimiter_AllowRequest() {
+	name := "test-route"
+	t := newTable(true, false)
+	config := NewRateLimiterConfig(1, 1, 429)
+	config.Key = "header:X-User-Id"
+	errs := t.AddController(newRoute(name, config))
+	fmt.Printf("test: Add() -> [errors:%v]\n", errs)
+
+	rl, _ := t.LookupByName(name).RateLimiter()
+	req1, _ := http.NewRequest("GET", "https://www.google.com/search", nil)
+	req1.Header.Set("X-User-Id", "user-1")
+	req2, _ := http.NewRequest("GET", "https://www.google.com/search", nil)
+	req2.Header.Set("X-User-Id", "user-2")
+
+	ok, key := rl.AllowRequest(req1)
+	fmt.Printf("test: AllowRequest(user-1) -> [ok:%v] [key:%v]\n", ok, key)
+	ok, key = rl.AllowRequest(req1)
+	fmt.Printf("test: AllowRequest(user-1) -> [ok:%v] [key:%v]\n", ok, key)
+	ok, key = rl.AllowRequest(req2)
+	fmt.Printf("test: AllowRequest(user-2) -> [ok:%v] [key:%v]\n", ok, key)
+
+	m := make(map[string]string, 16)
+	rateLimitKeyState(m, rl.(*rateLimiter), req1, RateLimitFlag)
+	fmt.Printf("test: rateLimitKeyState(map,RL) -> %v\n", m)
+	rateLimitKeyState(m, rl.(*rateLimiter), req1, "")
+	fmt.Printf("test: rateLimitKeyState(map) -> %v\n", m)
+
+	//Output:
+	//test: Add() -> [errors:[]]
+	//test: AllowRequest(user-1) -> [ok:true] [key:]
+	//test: AllowRequest(user-1) -> [ok:false] [key:user-1]
+	//test: AllowRequest(user-2) -> [ok:true] [key:]
+	//test: rateLimitKeyState(map,RL) -> map[rateLimitKey:user-1]
+	//test: rateLimitKeyState(map) -> map[rateLimitKey:]
+
+}
@@ -0,0 +1,387 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// Route patterns are of the form : [METHOD[,METHOD...] ]host[/path]
+//
+// host  - a literal host, "*" for any host, or a glob such as "*.google.com"
+// path  - a literal path prefix, or a glob when it contains any of "*?["
+//
+// A pattern starting with "~" is a regular expression matched against host + path, and urn patterns,
+// "urn:postgres:query", are matched against the host and path returned by ParseUri. A literal path prefix
+// matches the path, or the path followed by "/", so "/api" matches "/api/v1" but not "/apiv2", a urn path
+// can also be followed by ".". When more
+// than one literal pattern matches, the longest literal host + path prefix wins, and then the longest
+// literal path prefix for any host. A glob or regular expression that matches a request matched by
+// another pattern is ambiguous, and is an error when the pattern is added.
+
+const (
+	regexPrefix = "~"
+	globChars   = "*?["
+)
+
+type routePattern struct {
+	name    string
+	pattern string
+	methods map[string]bool
+	host    string
+	path    string
+	glob    bool
+	urn     bool
+	re      *regexp.Regexp
+	prog    *syntax.Prog
+}
+
+func newRoutePattern(name, pattern string) (*routePattern, error) {
+	p := new(routePattern)
+	p.name = name
+	p.pattern = pattern
+	s := Trim(pattern)
+	if i := strings.Index(s, " "); i > 0 {
+		for _, m := range strings.Split(s[:i], ",") {
+			m = strings.ToUpper(Trim(m))
+			if !isMethod(m) {
+				return nil, errors.New(fmt.Sprintf("invalid configuration: route pattern method is invalid [%v] [%v]", name, pattern))
+			}
+			if p.methods == nil {
+				p.methods = make(map[string]bool)
+			}
+			p.methods[m] = true
+		}
+		s = Trim(s[i+1:])
+	}
+	if s == "" {
+		return nil, errors.New(fmt.Sprintf("invalid configuration: route pattern is empty [%v]", name))
+	}
+	if strings.HasPrefix(s, regexPrefix) {
+		re, err := regexp.Compile(s[len(regexPrefix):])
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid configuration: route pattern regular expression is invalid [%v] [%v]", name, err))
+		}
+		p.re = re
+		if p.prog, err = p.compileProg(); err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid configuration: route pattern regular expression is invalid [%v] [%v]", name, err))
+		}
+		return p, nil
+	}
+	switch {
+	case strings.HasPrefix(s, "urn:"):
+		_, p.host, p.path = ParseUri(s)
+		p.urn = true
+	case strings.Contains(s, "://"):
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid configuration: route pattern is invalid [%v] [%v]", name, err))
+		}
+		p.host, p.path = u.Host, u.Path
+	default:
+		if i := strings.Index(s, "/"); i == -1 {
+			p.host = s
+		} else {
+			p.host, p.path = s[:i], s[i:]
+		}
+	}
+	p.host = strings.ToLower(p.host)
+	if p.host == "*" {
+		p.host = ""
+	}
+	p.path = normalizePath(p.path)
+	p.glob = strings.ContainsAny(p.host, globChars) || strings.ContainsAny(p.path, globChars)
+	if p.glob {
+		if _, err := path.Match(p.host, ""); err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid configuration: route pattern host glob is invalid [%v] [%v]", name, pattern))
+		}
+		if _, err := path.Match(p.path, ""); err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid configuration: route pattern path glob is invalid [%v] [%v]", name, pattern))
+		}
+	}
+	var err error
+	if p.prog, err = p.compileProg(); err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid configuration: route pattern is invalid [%v] [%v]", name, err))
+	}
+	return p, nil
+}
+
+func isMethod(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizePath(p string) string {
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
+
+func (p *routePattern) allows(method string) bool {
+	if p.methods == nil {
+		return true
+	}
+	if method == "" {
+		method = http.MethodGet
+	}
+	return p.methods[strings.ToUpper(method)]
+}
+
+// overlaps - determine if two patterns allow a common method
+func (p *routePattern) overlaps(p2 *routePattern) bool {
+	if p.methods == nil || p2.methods == nil {
+		return true
+	}
+	for m := range p.methods {
+		if p2.methods[m] {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *routePattern) key() string {
+	if p.re != nil {
+		return regexPrefix + p.re.String()
+	}
+	return p.host + p.path
+}
+
+func (p *routePattern) match(host, path1 string) bool {
+	if p.re != nil {
+		return p.re.MatchString(host + path1)
+	}
+	if p.host != "" {
+		if ok, _ := path.Match(p.host, host); !ok {
+			return false
+		}
+	}
+	if strings.ContainsAny(p.path, globChars) {
+		ok, _ := path.Match(p.path, path1)
+		return ok
+	}
+	return strings.HasPrefix(path1, p.path) && p.atBoundary(p.path[len(p.path)-1], path1[len(p.path):])
+}
+
+// atBoundary - determine if a literal prefix, ending with last, and followed by rest, ends at a segment boundary
+func (p *routePattern) atBoundary(last byte, rest string) bool {
+	if rest == "" || rest[0] == '/' || last == '/' {
+		return true
+	}
+	return p.urn && (rest[0] == '.' || last == '.')
+}
+
+type radixNode struct {
+	prefix   string
+	children []*radixNode
+	entries  []*routePattern
+}
+
+func (n *radixNode) insert(key string, p *routePattern) error {
+	for {
+		if key == "" {
+			for _, e := range n.entries {
+				if e.overlaps(p) {
+					return ambiguousError(e, p)
+				}
+			}
+			n.entries = append(n.entries, p)
+			return nil
+		}
+		var child *radixNode
+		for _, c := range n.children {
+			if c.prefix[0] == key[0] {
+				child = c
+				break
+			}
+		}
+		if child == nil {
+			n.children = append(n.children, &radixNode{prefix: key, entries: []*routePattern{p}})
+			return nil
+		}
+		i := commonPrefix(key, child.prefix)
+		if i < len(child.prefix) {
+			// Split the child
+			split := &radixNode{prefix: child.prefix[i:], children: child.children, entries: child.entries}
+			child.prefix = child.prefix[:i]
+			child.children = []*radixNode{split}
+			child.entries = nil
+		}
+		n = child
+		key = key[i:]
+	}
+}
+
+// lookup - longest prefix match, at a "/" boundary, that allows the method
+func (n *radixNode) lookup(key, method string) *routePattern {
+	var match *routePattern
+	var last byte
+	for n != nil {
+		for _, e := range n.entries {
+			if e.allows(method) && e.atBoundary(last, key) {
+				match = e
+				break
+			}
+		}
+		if key == "" {
+			break
+		}
+		var next *radixNode
+		for _, c := range n.children {
+			if strings.HasPrefix(key, c.prefix) {
+				next = c
+				key = key[len(c.prefix):]
+				last = c.prefix[len(c.prefix)-1]
+				break
+			}
+		}
+		n = next
+	}
+	return match
+}
+
+func (n *radixNode) remove(name string) {
+	var entries []*routePattern
+	for _, e := range n.entries {
+		if e.name != name {
+			entries = append(entries, e)
+		}
+	}
+	n.entries = entries
+	for _, c := range n.children {
+		c.remove(name)
+	}
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+type router struct {
+	hosts    *radixNode
+	any      *radixNode
+	others   []*routePattern
+	literals []*routePattern
+}
+
+func newRouter() *router {
+	return &router{hosts: new(radixNode), any: new(radixNode)}
+}
+
+func (r *router) add(p *routePattern) error {
+	literal := p.re == nil && !p.glob
+	// Literal patterns are ordered by the prefix length, a glob or regular expression is ambiguous with any
+	// pattern matching the same request
+	for _, e := range r.others {
+		if e.overlaps(p) && intersects(e.prog, p.prog) {
+			return ambiguousError(e, p)
+		}
+	}
+	if !literal {
+		for _, e := range r.literals {
+			if e.overlaps(p) && intersects(e.prog, p.prog) {
+				return ambiguousError(e, p)
+			}
+		}
+		r.others = append(r.others, p)
+		return nil
+	}
+	var err error
+	if p.host == "" {
+		err = r.any.insert(p.path, p)
+	} else {
+		err = r.hosts.insert(p.key(), p)
+	}
+	if err == nil {
+		r.literals = append(r.literals, p)
+	}
+	return err
+}
+
+func ambiguousError(e, p *routePattern) error {
+	return errors.New(fmt.Sprintf("invalid configuration: route pattern is ambiguous [%v] [%v] and [%v] [%v]", e.name, e.pattern, p.name, p.pattern))
+}
+
+func (r *router) remove(name string) {
+	r.hosts.remove(name)
+	r.any.remove(name)
+	var others []*routePattern
+	for _, e := range r.others {
+		if e.name != name {
+			others = append(others, e)
+		}
+	}
+	r.others = others
+	var literals []*routePattern
+	for _, e := range r.literals {
+		if e.name != name {
+			literals = append(literals, e)
+		}
+	}
+	r.literals = literals
+}
+
+func (r *router) lookup(host, path, method string) (string, bool) {
+	host = strings.ToLower(host)
+	path = normalizePath(path)
+	if p := r.hosts.lookup(host+path, method); p != nil {
+		return p.name, true
+	}
+	if i := strings.LastIndex(host, ":"); i != -1 {
+		if p := r.hosts.lookup(host[:i]+path, method); p != nil {
+			return p.name, true
+		}
+	}
+	if p := r.any.lookup(path, method); p != nil {
+		return p.name, true
+	}
+	for _, p := range r.others {
+		if p.allows(method) && p.match(host, path) {
+			return p.name, true
+		}
+	}
+	return "", false
+}
+
+func (t *table) matchHttp(req *http.Request) (string, bool) {
+	if req == nil || req.URL == nil {
+		return "", true
+	}
+	host := req.URL.Host
+	if host == "" {
+		host = req.Host
+	}
+	path := req.URL.Path
+	if req.URL.Scheme == "urn" {
+		_, host, path = ParseUri(req.URL.String())
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	name, _ := t.router.lookup(host, path, req.Method)
+	return name, true
+}
+
+func (t *table) matchUri(uri, method string) (string, bool) {
+	_, host, path := ParseUri(uri)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	name, _ := t.router.lookup(host, path, method)
+	return name, true
+}
@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func Example_newRoutePattern() {
+	p, err := newRoutePattern("test-route", "google.com/search")
+	fmt.Printf("test: newRoutePattern(\"google.com/search\") -> [err:%v] [host:%v] [path:%v] [glob:%v] [methods:%v]\n", err, p.host, p.path, p.glob, p.methods)
+
+	p, err = newRoutePattern("test-route", "GET,post *.google.com")
+	fmt.Printf("test: newRoutePattern(\"GET,post *.google.com\") -> [err:%v] [host:%v] [path:%v] [glob:%v] [methods:%v]\n", err, p.host, p.path, p.glob, p.methods)
+
+	p, err = newRoutePattern("test-route", "urn:postgres:query.access-log")
+	fmt.Printf("test: newRoutePattern(\"urn:postgres:query.access-log\") -> [err:%v] [host:%v] [path:%v]\n", err, p.host, p.path)
+
+	p, err = newRoutePattern("test-route", "https://www.twitter.com/home")
+	fmt.Printf("test: newRoutePattern(\"https://www.twitter.com/home\") -> [err:%v] [host:%v] [path:%v]\n", err, p.host, p.path)
+
+	p, err = newRoutePattern("test-route", "~^api\\.google\\.com/v[0-9]+/")
+	fmt.Printf("test: newRoutePattern(\"~^api\\.google\\.com/v[0-9]+/\") -> [err:%v] [regex:%v]\n", err, p.re)
+
+	_, err = newRoutePattern("test-route", "G3T google.com")
+	fmt.Printf("test: newRoutePattern(\"G3T google.com\") -> [err:%v]\n", err)
+
+	_, err = newRoutePattern("test-route", "~google.com/(")
+	fmt.Printf("test: newRoutePattern(\"~google.com/(\") -> [err:%v]\n", err != nil)
+
+	//Output:
+	//test: newRoutePattern("google.com/search") -> [err:<nil>] [host:google.com] [path:/search] [glob:false] [methods:map[]]
+	//test: newRoutePattern("GET,post *.google.com") -> [err:<nil>] [host:*.google.com] [path:/] [glob:true] [methods:map[GET:true POST:true]]
+	//test: newRoutePattern("urn:postgres:query.access-log") -> [err:<nil>] [host:postgres] [path:/query.access-log]
+	//test: newRoutePattern("https://www.twitter.com/home") -> [err:<nil>] [host:www.twitter.com] [path:/home]
+	//test: newRoutePattern("~^api\.google\.com/v[0-9]+/") -> [err:<nil>] [regex:^api\.google\.com/v[0-9]+/]
+	//test: newRoutePattern("G3T google.com") -> [err:invalid configuration: route pattern method is invalid [test-route] [G3T google.com]]
+	//test: newRoutePattern("~google.com/(") -> [err:true]
+
+}
+
+func Example_Router_Lookup() {
+	r := newRouter()
+	for _, s := range [][]string{
+		{"google", "google.com"},
+		{"google-search", "GET,HEAD google.com/search"},
+		{"google-search-post", "POST google.com/search"},
+		{"health", "localhost/health"},
+		{"docs", "google.com/docs"},
+		{"subdomain", "*.google.com"},
+		{"api", "~^api\\.twitter\\.com/v[0-9]+/"},
+		{"postgres", "urn:postgres:query"},
+	} {
+		p, _ := newRoutePattern(s[0], s[1])
+		if err := r.add(p); err != nil {
+			fmt.Printf("test: add() -> [err:%v]\n", err)
+		}
+	}
+	for _, s := range [][]string{
+		{"google.com", "", "GET"},
+		{"google.com", "/search/images", "GET"},
+		{"google.com", "/search", "POST"},
+		{"google.com:443", "/search", "GET"},
+		{"google.community", "/search", "GET"},
+		{"google.com", "/docs/api", "GET"},
+		{"google.com", "/docsv2", "GET"},
+		{"localhost:8080", "/health/liveness", "GET"},
+		{"mail.google.com", "/inbox", "GET"},
+		{"api.twitter.com", "/v2/tweets", "GET"},
+		{"api.twitter.com", "/home", "GET"},
+		{"postgres", "query.access-log", ""},
+	} {
+		name, ok := r.lookup(s[0], s[1], s[2])
+		fmt.Printf("test: lookup(%v,%v,%v) -> [name:%v] [ok:%v]\n", s[0], s[1], s[2], name, ok)
+	}
+
+	r.remove("google-search")
+	name, ok := r.lookup("google.com", "/search/images", "GET")
+	fmt.Printf("test: remove(google-search) -> [name:%v] [ok:%v]\n", name, ok)
+
+	//Output:
+	//test: lookup(google.com,,GET) -> [name:google] [ok:true]
+	//test: lookup(google.com,/search/images,GET) -> [name:google-search] [ok:true]
+	//test: lookup(google.com,/search,POST) -> [name:google-search-post] [ok:true]
+	//test: lookup(google.com:443,/search,GET) -> [name:google-search] [ok:true]
+	//test: lookup(google.community,/search,GET) -> [name:] [ok:false]
+	//test: lookup(google.com,/docs/api,GET) -> [name:docs] [ok:true]
+	//test: lookup(google.com,/docsv2,GET) -> [name:google] [ok:true]
+	//test: lookup(localhost:8080,/health/liveness,GET) -> [name:health] [ok:true]
+	//test: lookup(mail.google.com,/inbox,GET) -> [name:subdomain] [ok:true]
+	//test: lookup(api.twitter.com,/v2/tweets,GET) -> [name:api] [ok:true]
+	//test: lookup(api.twitter.com,/home,GET) -> [name:] [ok:false]
+	//test: lookup(postgres,query.access-log,) -> [name:postgres] [ok:true]
+	//test: remove(google-search) -> [name:google] [ok:true]
+
+}
+
+func Example_Router_Ambiguous() {
+	r := newRouter()
+	for _, s := range [][]string{
+		{"get-search", "GET google.com/search"},
+		{"post-search", "POST google.com/search"},
+		{"search", "google.com/search"},
+		{"get-post-search", "GET,POST google.com/search"},
+		{"subdomain", "*.google.com"},
+		{"subdomain2", "*.google.com/"},
+		{"search-glob", "google.com/search/*"},
+		{"api", "~^api\\.twitter\\.com/v[0-9]+/"},
+		{"api-tweets", "GET api.twitter.com/v2/tweets"},
+		{"api-home", "GET api.twitter.com/home"},
+		{"api-home-regex", "~^api\\.twitter\\.com/home"},
+		{"health", "/health"},
+	} {
+		p, _ := newRoutePattern(s[0], s[1])
+		err := r.add(p)
+		fmt.Printf("test: add(%v) -> [err:%v]\n", s[1], err)
+	}
+
+	//Output:
+	//test: add(GET google.com/search) -> [err:<nil>]
+	//test: add(POST google.com/search) -> [err:<nil>]
+	//test: add(google.com/search) -> [err:invalid configuration: route pattern is ambiguous [get-search] [GET google.com/search] and [search] [google.com/search]]
+	//test: add(GET,POST google.com/search) -> [err:invalid configuration: route pattern is ambiguous [get-search] [GET google.com/search] and [get-post-search] [GET,POST google.com/search]]
+	//test: add(*.google.com) -> [err:<nil>]
+	//test: add(*.google.com/) -> [err:invalid configuration: route pattern is ambiguous [subdomain] [*.google.com] and [subdomain2] [*.google.com/]]
+	//test: add(google.com/search/*) -> [err:invalid configuration: route pattern is ambiguous [get-search] [GET google.com/search] and [search-glob] [google.com/search/*]]
+	//test: add(~^api\.twitter\.com/v[0-9]+/) -> [err:<nil>]
+	//test: add(GET api.twitter.com/v2/tweets) -> [err:invalid configuration: route pattern is ambiguous [api] [~^api\.twitter\.com/v[0-9]+/] and [api-tweets] [GET api.twitter.com/v2/tweets]]
+	//test: add(GET api.twitter.com/home) -> [err:<nil>]
+	//test: add(~^api\.twitter\.com/home) -> [err:invalid configuration: route pattern is ambiguous [api-home] [GET api.twitter.com/home] and [api-home-regex] [~^api\.twitter\.com/home]]
+	//test: add(/health) -> [err:invalid configuration: route pattern is ambiguous [subdomain] [*.google.com] and [health] [/health]]
+
+}
+
+func ExampleTable_Pattern() {
+	t := newTable(true, false)
+	route := NewRoute("google-search", EgressTraffic, "", false)
+	route.Pattern = "google.com/search"
+	errs := t.AddController(route)
+	fmt.Printf("test: AddController(google.com/search) -> [errs:%v]\n", errs)
+
+	route = NewRoute("postgres", EgressTraffic, "", false)
+	route.Pattern = "urn:postgres"
+	errs = t.AddController(route)
+	fmt.Printf("test: AddController(urn:postgres) -> [errs:%v]\n", errs)
+
+	route = NewRoute("duplicate", EgressTraffic, "", false)
+	route.Pattern = "GET google.com/search"
+	errs = t.AddController(route)
+	fmt.Printf("test: AddController(GET google.com/search) -> [errs:%v] [count:%v]\n", errs, t.count())
+
+	req, _ := http.NewRequest("GET", "https://google.com/search?q=test", nil)
+	fmt.Printf("test: LookupHttp(https://google.com/search?q=test) -> [controller:%v]\n", t.LookupHttp(req).Name())
+
+	req, _ = http.NewRequest("GET", "https://www.google.com/search?q=test", nil)
+	fmt.Printf("test: LookupHttp(https://www.google.com/search?q=test) -> [controller:%v]\n", t.LookupHttp(req).Name())
+
+	fmt.Printf("test: LookupUri(urn:postgres:query.access-log) -> [controller:%v]\n", t.LookupUri("urn:postgres:query.access-log", "").Name())
+
+	t.remove("google-search")
+	req, _ = http.NewRequest("GET", "https://google.com/search?q=test", nil)
+	fmt.Printf("test: remove(google-search) -> [controller:%v]\n", t.LookupHttp(req).Name())
+
+	//Output:
+	//test: AddController(google.com/search) -> [errs:[]]
+	//test: AddController(urn:postgres) -> [errs:[]]
+	//test: AddController(GET google.com/search) -> [errs:[invalid configuration: route pattern is ambiguous [google-search] [google.com/search] and [duplicate] [GET google.com/search]]] [count:2]
+	//test: LookupHttp(https://google.com/search?q=test) -> [controller:google-search]
+	//test: LookupHttp(https://www.google.com/search?q=test) -> [controller:*]
+	//test: LookupUri(urn:postgres:query.access-log) -> [controller:postgres]
+	//test: remove(google-search) -> [controller:*]
+
+}
@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+	metricsPrefix      = "host_route_"
+)
+
+// DefaultLatencyBuckets - upper bounds, in seconds, of the request latency histogram
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var statusClasses = []string{"1xx", "2xx", "3xx", "4xx", "5xx", "other"}
+
+// routeKey - the labels of a route
+type routeKey struct {
+	traffic string
+	route   string
+}
+
+// routeMetrics - counters and latency histogram of a route
+type routeMetrics struct {
+	mu           sync.Mutex
+	statusClass  []uint64
+	timeouts     uint64
+	hostTimeouts uint64
+	rateLimited  uint64
+	retries      uint64
+	failovers    uint64
+	buckets      []uint64
+	sum          float64
+	count        uint64
+}
+
+// metrics - per route metrics, recorded once for the outcome of each request that is logged by a controller
+type metrics struct {
+	mu      sync.RWMutex
+	bounds  []float64
+	routes  map[routeKey]*routeMetrics
+	enabled bool
+}
+
+var defaultMetrics = newMetrics(DefaultLatencyBuckets)
+
+func newMetrics(bounds []float64) *metrics {
+	m := new(metrics)
+	m.bounds = bounds
+	m.routes = make(map[routeKey]*routeMetrics)
+	m.enabled = true
+	return m
+}
+
+// SetMetricsEnabled - configuration for recording route metrics, metrics are enabled by default
+func SetMetricsEnabled(enabled bool) {
+	defaultMetrics.mu.Lock()
+	defaultMetrics.enabled = enabled
+	defaultMetrics.mu.Unlock()
+}
+
+// NewMetricsHandler - an http.Handler that writes the route metrics in the Prometheus text format
+func NewMetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", MetricsContentType)
+		WriteMetrics(w)
+	})
+}
+
+// WriteMetrics - write the route metrics in the Prometheus text format
+func WriteMetrics(w io.Writer) error {
+	return defaultMetrics.write(w)
+}
+
+func (m *metrics) route(traffic, route string) *routeMetrics {
+	key := routeKey{traffic: traffic, route: route}
+	m.mu.RLock()
+	r, ok := m.routes[key]
+	enabled := m.enabled
+	m.mu.RUnlock()
+	if !enabled {
+		return nil
+	}
+	if ok {
+		return r
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r, ok = m.routes[key]; !ok {
+		r = &routeMetrics{statusClass: make([]uint64, len(statusClasses)), buckets: make([]uint64, len(m.bounds))}
+		m.routes[key] = r
+	}
+	return r
+}
+
+// record - record the outcome of a request, retries is the number of attempts after the original request
+func (m *metrics) record(traffic, route string, duration time.Duration, statusCode int, statusFlags string, retries int) {
+	r := m.route(traffic, route)
+	if r == nil {
+		return
+	}
+	seconds := duration.Seconds()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statusClass[statusClass(statusCode)]++
+	if strings.Contains(statusFlags, UpstreamTimeoutFlag) {
+		r.timeouts++
+	}
+	if strings.Contains(statusFlags, HostTimeoutFlag) {
+		r.hostTimeouts++
+	}
+	if strings.Contains(statusFlags, RateLimitFlag) {
+		r.rateLimited++
+	}
+	if retries > 0 {
+		r.retries += uint64(retries)
+	}
+	for i, bound := range m.bounds {
+		if seconds <= bound {
+			r.buckets[i]++
+		}
+	}
+	r.sum += seconds
+	r.count++
+}
+
+// failover - record that failover was enabled for a route
+func (m *metrics) failover(traffic, route string) {
+	r := m.route(traffic, route)
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.failovers++
+	r.mu.Unlock()
+}
+
+func statusClass(statusCode int) int {
+	if statusCode < 100 || statusCode > 599 {
+		return len(statusClasses) - 1
+	}
+	return statusCode/100 - 1
+}
+
+// snapshot - a copy of the route metrics, sorted by traffic and route
+func (m *metrics) snapshot() ([]routeKey, []routeMetrics) {
+	m.mu.RLock()
+	keys := make([]routeKey, 0, len(m.routes))
+	for key := range m.routes {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].traffic != keys[j].traffic {
+			return keys[i].traffic < keys[j].traffic
+		}
+		return keys[i].route < keys[j].route
+	})
+	routes := make([]*routeMetrics, 0, len(keys))
+	for _, key := range keys {
+		routes = append(routes, m.routes[key])
+	}
+	m.mu.RUnlock()
+
+	values := make([]routeMetrics, len(routes))
+	for i, r := range routes {
+		r.mu.Lock()
+		values[i] = routeMetrics{statusClass: append([]uint64(nil), r.statusClass...), timeouts: r.timeouts, hostTimeouts: r.hostTimeouts,
+			rateLimited: r.rateLimited, retries: r.retries, failovers: r.failovers, buckets: append([]uint64(nil), r.buckets...), sum: r.sum, count: r.count}
+		r.mu.Unlock()
+	}
+	return keys, values
+}
+
+func (m *metrics) write(w io.Writer) error {
+	keys, values := m.snapshot()
+	bw := bufio.NewWriter(w)
+	counter := func(name, help string, value func(r *routeMetrics) uint64) {
+		writeHeader(bw, name, help, "counter")
+		for i, key := range keys {
+			fmt.Fprintf(bw, "%v%v{%v} %v\n", metricsPrefix, name, labels(key), value(&values[i]))
+		}
+	}
+
+	writeHeader(bw, "requests_total", "Requests by status class.", "counter")
+	for i, key := range keys {
+		for j, class := range statusClasses {
+			if class == "other" && values[i].statusClass[j] == 0 {
+				continue
+			}
+			fmt.Fprintf(bw, "%vrequests_total{%v,status_class=\"%v\"} %v\n", metricsPrefix, labels(key), class, values[i].statusClass[j])
+		}
+	}
+	writeHeader(bw, "timeouts_total", "Requests that timed out, by status flag.", "counter")
+	for i, key := range keys {
+		fmt.Fprintf(bw, "%vtimeouts_total{%v,flag=\"%v\"} %v\n", metricsPrefix, labels(key), UpstreamTimeoutFlag, values[i].timeouts)
+		fmt.Fprintf(bw, "%vtimeouts_total{%v,flag=\"%v\"} %v\n", metricsPrefix, labels(key), HostTimeoutFlag, values[i].hostTimeouts)
+	}
+	counter("rate_limited_total", "Requests rejected by a rate limiter.", func(r *routeMetrics) uint64 { return r.rateLimited })
+	counter("retries_total", "Egress retry attempts.", func(r *routeMetrics) uint64 { return r.retries })
+	counter("failovers_total", "Times failover was enabled.", func(r *routeMetrics) uint64 { return r.failovers })
+
+	writeHeader(bw, "request_duration_seconds", "Request latency.", "histogram")
+	for i, key := range keys {
+		r := &values[i]
+		for j, bound := range m.bounds {
+			fmt.Fprintf(bw, "%vrequest_duration_seconds_bucket{%v,le=\"%v\"} %v\n", metricsPrefix, labels(key), strconv.FormatFloat(bound, 'g', -1, 64), r.buckets[j])
+		}
+		fmt.Fprintf(bw, "%vrequest_duration_seconds_bucket{%v,le=\"+Inf\"} %v\n", metricsPrefix, labels(key), r.count)
+		fmt.Fprintf(bw, "%vrequest_duration_seconds_sum{%v} %v\n", metricsPrefix, labels(key), strconv.FormatFloat(r.sum, 'g', -1, 64))
+		fmt.Fprintf(bw, "%vrequest_duration_seconds_count{%v} %v\n", metricsPrefix, labels(key), r.count)
+	}
+	return bw.Flush()
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %v%v %v\n", metricsPrefix, name, help)
+	fmt.Fprintf(w, "# TYPE %v%v %v\n", metricsPrefix, name, kind)
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labels(key routeKey) string {
+	return fmt.Sprintf("traffic=\"%v\",route=\"%v\"", labelReplacer.Replace(key.traffic), labelReplacer.Replace(key.route))
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func Example_Metrics_Write() {
+	m := newMetrics([]float64{.1, 1})
+	m.record(EgressTraffic, "google-search", time.Millisecond*50, http.StatusOK, "", 0)
+	m.record(EgressTraffic, "google-search", time.Millisecond*500, http.StatusGatewayTimeout, UpstreamTimeoutFlag, 0)
+	m.record(EgressTraffic, "google-search", time.Millisecond*2000, http.StatusOK, "", 2)
+	m.failover(EgressTraffic, "google-search")
+	m.record(IngressTraffic, `twitter "home"`, time.Millisecond, http.StatusTooManyRequests, RateLimitFlag, 0)
+	m.record(IngressTraffic, `twitter "home"`, time.Millisecond, 0, HostTimeoutFlag, 0)
+
+	buf := new(bytes.Buffer)
+	err := m.write(buf)
+	fmt.Printf("test: write() -> [err:%v]\n%v", err, buf.String())
+
+	//Output:
+	//test: write() -> [err:<nil>]
+	//# HELP host_route_requests_total Requests by status class.
+	//# TYPE host_route_requests_total counter
+	//host_route_requests_total{traffic="egress",route="google-search",status_class="1xx"} 0
+	//host_route_requests_total{traffic="egress",route="google-search",status_class="2xx"} 2
+	//host_route_requests_total{traffic="egress",route="google-search",status_class="3xx"} 0
+	//host_route_requests_total{traffic="egress",route="google-search",status_class="4xx"} 0
+	//host_route_requests_total{traffic="egress",route="google-search",status_class="5xx"} 1
+	//host_route_requests_total{traffic="ingress",route="twitter \"home\"",status_class="1xx"} 0
+	//host_route_requests_total{traffic="ingress",route="twitter \"home\"",status_class="2xx"} 0
+	//host_route_requests_total{traffic="ingress",route="twitter \"home\"",status_class="3xx"} 0
+	//host_route_requests_total{traffic="ingress",route="twitter \"home\"",status_class="4xx"} 1
+	//host_route_requests_total{traffic="ingress",route="twitter \"home\"",status_class="5xx"} 0
+	//host_route_requests_total{traffic="ingress",route="twitter \"home\"",status_class="other"} 1
+	//# HELP host_route_timeouts_total Requests that timed out, by status flag.
+	//# TYPE host_route_timeouts_total counter
+	//host_route_timeouts_total{traffic="egress",route="google-search",flag="UT"} 1
+	//host_route_timeouts_total{traffic="egress",route="google-search",flag="HT"} 0
+	//host_route_timeouts_total{traffic="ingress",route="twitter \"home\"",flag="UT"} 0
+	//host_route_timeouts_total{traffic="ingress",route="twitter \"home\"",flag="HT"} 1
+	//# HELP host_route_rate_limited_total Requests rejected by a rate limiter.
+	//# TYPE host_route_rate_limited_total counter
+	//host_route_rate_limited_total{traffic="egress",route="google-search"} 0
+	//host_route_rate_limited_total{traffic="ingress",route="twitter \"home\""} 1
+	//# HELP host_route_retries_total Egress retry attempts.
+	//# TYPE host_route_retries_total counter
+	//host_route_retries_total{traffic="egress",route="google-search"} 2
+	//host_route_retries_total{traffic="ingress",route="twitter \"home\""} 0
+	//# HELP host_route_failovers_total Times failover was enabled.
+	//# TYPE host_route_failovers_total counter
+	//host_route_failovers_total{traffic="egress",route="google-search"} 1
+	//host_route_failovers_total{traffic="ingress",route="twitter \"home\""} 0
+	//# HELP host_route_request_duration_seconds Request latency.
+	//# TYPE host_route_request_duration_seconds histogram
+	//host_route_request_duration_seconds_bucket{traffic="egress",route="google-search",le="0.1"} 1
+	//host_route_request_duration_seconds_bucket{traffic="egress",route="google-search",le="1"} 2
+	//host_route_request_duration_seconds_bucket{traffic="egress",route="google-search",le="+Inf"} 3
+	//host_route_request_duration_seconds_sum{traffic="egress",route="google-search"} 2.55
+	//host_route_request_duration_seconds_count{traffic="egress",route="google-search"} 3
+	//host_route_request_duration_seconds_bucket{traffic="ingress",route="twitter \"home\"",le="0.1"} 2
+	//host_route_request_duration_seconds_bucket{traffic="ingress",route="twitter \"home\"",le="1"} 2
+	//host_route_request_duration_seconds_bucket{traffic="ingress",route="twitter \"home\"",le="+Inf"} 2
+	//host_route_request_duration_seconds_sum{traffic="ingress",route="twitter \"home\""} 0.002
+	//host_route_request_duration_seconds_count{traffic="ingress",route="twitter \"home\""} 2
+
+}
+
+func Example_MetricsHandler() {
+	logFn := defaultLogFn
+	defer SetLogFn(logFn)
+	SetLogFn(func(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, statusFlags string, controllerState map[string]string) {
+	})
+	ctrl, _ := newController(newRoute("metrics-handler-test"), nil)
+	req, _ := http.NewRequest("GET", "https://www.google.com/search", nil)
+	ctrl.LogHttpIngress(time.Now(), time.Millisecond*20, req, http.StatusOK, 0, "")
+
+	SetMetricsEnabled(false)
+	ctrl.LogHttpIngress(time.Now(), time.Millisecond*20, req, http.StatusOK, 0, "")
+	SetMetricsEnabled(true)
+
+	rec := httptest.NewRecorder()
+	NewMetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	fmt.Printf("test: ServeHTTP() -> [status:%v] [content-type:%v]\n", rec.Code, rec.Header().Get("Content-Type"))
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if strings.Contains(line, "metrics-handler-test") && (strings.Contains(line, "2xx") || strings.Contains(line, "_count")) {
+			fmt.Printf("test: ServeHTTP() -> %v\n", line)
+		}
+	}
+
+	//Output:
+	//test: ServeHTTP() -> [status:200] [content-type:text/plain; version=0.0.4; charset=utf-8]
+	//test: ServeHTTP() -> host_route_requests_total{traffic="ingress",route="metrics-handler-test",status_class="2xx"} 1
+	//test: ServeHTTP() -> host_route_request_duration_seconds_count{traffic="ingress",route="metrics-handler-test"} 1
+
+}
+
+func Example_Metrics_Failover() {
+	name := "metrics-failover-test"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewFailoverConfig(failoverFn)))
+	fmt.Printf("test: Add() -> [errs:%v]\n", errs)
+
+	// Failovers are counted when failover is enabled, not for each request while it is enabled
+	f, _ := t.LookupByName(name).Failover()
+	f.Enable()
+	f, _ = t.LookupByName(name).Failover()
+	f.Enable()
+	f.Disable()
+	f, _ = t.LookupByName(name).Failover()
+	f.Enable()
+	fmt.Printf("test: Enable() -> [failovers:%v]\n", defaultMetrics.route(EgressTraffic, name).failovers)
+
+	//Output:
+	//test: Add() -> [errs:[]]
+	//test: Enable() -> [failovers:2]
+
+}
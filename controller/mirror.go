@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultMirrorTimeout       = time.Second * 5
+	DefaultMirrorMaxConcurrent = 100
+)
+
+// Mirror - interface for sending a copy of requests to a secondary url
+type Mirror interface {
+	IsEnabled() bool
+	Enable()
+	Disable()
+	Allow() bool
+	Acquire() bool
+	Release()
+	BuildUrl(uri *url.URL) *url.URL
+	Timeout() time.Duration
+	MaxBodySize() int64
+	Percentage() float64
+	SetPercentage(percentage float64)
+}
+
+type MirrorConfig struct {
+	Pattern     string        // Secondary url, a proxy pattern
+	Percentage  float64       // Percentage of requests that are mirrored
+	Timeout     time.Duration // 0 is DefaultMirrorTimeout
+	MaxBodySize int64         // Largest request body that is mirrored, 0 is DefaultMaxBodySize
+	// In-flight mirrored requests, a request is not mirrored when the limit is reached, 0 is DefaultMirrorMaxConcurrent
+	MaxConcurrent int
+}
+
+func NewMirrorConfig(pattern string, percentage float64) *MirrorConfig {
+	c := new(MirrorConfig)
+	c.Pattern = pattern
+	c.Percentage = percentage
+	return c
+}
+
+// mirrorState - in-flight mirrored requests, shared between clones so that a request releases against the state it
+// acquired
+type mirrorState struct {
+	inFlight int32
+}
+
+type mirror struct {
+	table   *table
+	name    string
+	enabled bool
+	config  MirrorConfig
+	state   *mirrorState
+}
+
+func cloneMirror(curr *mirror) *mirror {
+	t := new(mirror)
+	*t = *curr
+	return t
+}
+
+func newMirror(name string, table *table, config *MirrorConfig) *mirror {
+	t := new(mirror)
+	t.table = table
+	t.name = name
+	t.enabled = true
+	if config != nil {
+		t.config = *config
+	}
+	if t.config.MaxConcurrent == 0 {
+		t.config.MaxConcurrent = DefaultMirrorMaxConcurrent
+	}
+	t.state = new(mirrorState)
+	return t
+}
+
+func (m *mirror) validate() error {
+	if m.config.Pattern == "" {
+		return newFieldError("pattern", errors.New("invalid configuration: Mirror pattern is empty"))
+	}
+	// The mirrored request is sent to another host, so the pattern must be an absolute URL
+	if u, err := url.Parse(m.config.Pattern); err != nil || u.Scheme == "" || u.Host == "" {
+		return newFieldError("pattern", errors.New(fmt.Sprintf("invalid configuration: Mirror pattern is invalid [%v]", m.config.Pattern)))
+	}
+	if m.config.Percentage <= 0 || m.config.Percentage > 100 {
+		return newFieldError("percentage", errors.New("invalid configuration: Mirror percentage is not between 0 and 100"))
+	}
+	if m.config.Timeout < 0 {
+		return newFieldError("timeout", errors.New("invalid configuration: Mirror timeout is < 0"))
+	}
+	if m.config.MaxBodySize < 0 {
+		return newFieldError("maxBodySize", errors.New("invalid configuration: Mirror max body size is < 0"))
+	}
+	if m.config.MaxConcurrent < 0 {
+		return newFieldError("maxConcurrent", errors.New("invalid configuration: Mirror max concurrent is < 0"))
+	}
+	return nil
+}
+
+func (m *mirror) IsEnabled() bool { return m.enabled }
+
+func (m *mirror) Disable() {
+	if !m.IsEnabled() {
+		return
+	}
+	m.table.enableMirror(m.name, false)
+}
+
+func (m *mirror) Enable() {
+	if m.IsEnabled() {
+		return
+	}
+	m.table.enableMirror(m.name, true)
+}
+
+// Allow - determine if a request is mirrored
+func (m *mirror) Allow() bool {
+	if !m.IsEnabled() {
+		return false
+	}
+	return rand.Float64()*100 < m.config.Percentage
+}
+
+// Acquire - determine if there is a slot for a mirrored request, the request is dropped when the limit is reached. A
+// successful Acquire must be followed by a Release
+func (m *mirror) Acquire() bool {
+	if atomic.AddInt32(&m.state.inFlight, 1) > int32(m.config.MaxConcurrent) {
+		atomic.AddInt32(&m.state.inFlight, -1)
+		return false
+	}
+	return true
+}
+
+// Release - release a slot acquired by Acquire
+func (m *mirror) Release() {
+	atomic.AddInt32(&m.state.inFlight, -1)
+}
+
+func (m *mirror) BuildUrl(uri *url.URL) *url.URL {
+	return buildUrl(m.config.Pattern, uri)
+}
+
+func (m *mirror) Timeout() time.Duration {
+	if m.config.Timeout == 0 {
+		return DefaultMirrorTimeout
+	}
+	return m.config.Timeout
+}
+
+func (m *mirror) MaxBodySize() int64 {
+	if m.config.MaxBodySize == 0 {
+		return DefaultMaxBodySize
+	}
+	return m.config.MaxBodySize
+}
+
+func (m *mirror) Percentage() float64 {
+	return m.config.Percentage
+}
+
+func (m *mirror) SetPercentage(percentage float64) {
+	if percentage <= 0 || percentage > 100 || m.config.Percentage == percentage {
+		return
+	}
+	m.table.setMirrorPercentage(m.name, percentage)
+}
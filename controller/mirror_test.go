@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+)
+
+func Example_newMirror() {
+	t := newTable(true, false)
+	m := newMirror("test-route", t, NewMirrorConfig("http://mirror:8080", 25))
+	fmt.Printf("test: newMirror() -> [name:%v] [pattern:%v] [percentage:%v] [timeout:%v] [max-body:%v] [validate:%v]\n",
+		m.name, m.config.Pattern, m.Percentage(), m.Timeout(), m.MaxBodySize(), m.validate())
+
+	m2 := cloneMirror(m)
+	m2.config.Percentage = 50
+	fmt.Printf("test: cloneMirror() -> [prev-percentage:%v] [curr-percentage:%v]\n", m.Percentage(), m2.Percentage())
+
+	fmt.Printf("test: validate() -> %v\n", newMirror("test-route", t, NewMirrorConfig("", 25)).validate())
+	fmt.Printf("test: validate() -> %v\n", newMirror("test-route", t, NewMirrorConfig("http://mirror:8080", 0)).validate())
+	fmt.Printf("test: validate() -> %v\n", newMirror("test-route", t, NewMirrorConfig("shadow.internal", 25)).validate())
+	fmt.Printf("test: validate() -> %v\n", newMirror("test-route", t, NewMirrorConfig("/shadow", 25)).validate())
+
+	uri, _ := url.Parse("https://localhost:8081/search?q=test")
+	fmt.Printf("test: BuildUrl() -> %v\n", m.BuildUrl(uri))
+
+	//Output:
+	//test: newMirror() -> [name:test-route] [pattern:http://mirror:8080] [percentage:25] [timeout:5s] [max-body:65536] [validate:<nil>]
+	//test: cloneMirror() -> [prev-percentage:25] [curr-percentage:50]
+	//test: validate() -> pattern: invalid configuration: Mirror pattern is empty
+	//test: validate() -> percentage: invalid configuration: Mirror percentage is not between 0 and 100
+	//test: validate() -> pattern: invalid configuration: Mirror pattern is invalid [shadow.internal]
+	//test: validate() -> pattern: invalid configuration: Mirror pattern is invalid [/shadow]
+	//test: BuildUrl() -> http://mirror:8080/search?q=test
+
+}
+
+func Example_Mirror_Allow() {
+	name := "test-route"
+	t := newTable(true, false)
+	errs := t.AddController(newRoute(name, NewMirrorConfig("http://mirror:8080", 100)))
+	fmt.Printf("test: Add() -> [errors:%v] [count:%v]\n", errs, t.count())
+
+	m, _ := t.LookupByName(name).Mirror()
+	fmt.Printf("test: Allow() -> %v\n", m.Allow())
+
+	m.SetPercentage(10)
+	m, _ = t.LookupByName(name).Mirror()
+	fmt.Printf("test: SetPercentage(10) -> [percentage:%v]\n", m.Percentage())
+
+	m.Disable()
+	m, _ = t.LookupByName(name).Mirror()
+	fmt.Printf("test: Disable() -> [enabled:%v] [allow:%v]\n", m.IsEnabled(), m.Allow())
+
+	t2 := newTable(false, false)
+	errs = t2.AddController(newRoute(name, NewMirrorConfig("http://mirror:8080", 100)))
+	fmt.Printf("test: Add(ingress) -> [errors:%v]\n", errs)
+
+	//Output:
+	//test: Add() -> [errors:[]] [count:1]
+	//test: Allow() -> true
+	//test: SetPercentage(10) -> [percentage:10]
+	//test: Disable() -> [enabled:false] [allow:false]
+	//test: Add(ingress) -> [errors:[mirror: invalid configuration: Mirror is not valid for ingress traffic]]
+
+}
@@ -26,3 +26,29 @@ var defaultLogFn = func(traffic string, start time.Time, duration time.Duration,
 	s := FmtLog(traffic, start, duration, req, resp, statusFlags, controllerState)
 	fmt.Printf("{%v}\n", s)
 }
+
+// AuditEntry - a change made to a controller through the admin handler
+type AuditEntry struct {
+	Time      time.Time
+	User      string
+	RequestId string
+	Traffic   string
+	Route     string
+	Field     string
+	Old       string
+	New       string
+}
+
+// Audit - type for recording changes made through the admin handler
+type Audit func(entry AuditEntry)
+
+// SetAuditFn - configuration for audit function
+func SetAuditFn(fn Audit) {
+	if fn != nil {
+		defaultAuditFn = fn
+	}
+}
+
+var defaultAuditFn = func(entry AuditEntry) {
+	fmt.Printf("{%v}\n", FmtAudit(entry))
+}
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// Overlapping patterns are found by compiling each pattern into a program that matches the host + path strings
+// matched by the pattern, and then searching the product of two programs for a string that both match
+
+// compileProg - the program for the host + path strings matched by a pattern
+func (p *routePattern) compileProg() (*syntax.Prog, error) {
+	var expr string
+	if p.re != nil {
+		// A regular expression is not anchored
+		expr = `(?s:.*)(?:` + p.re.String() + `)(?s:.*)`
+	} else {
+		host := `[^/]*`
+		if p.host != "" {
+			if strings.ContainsAny(p.host, globChars) {
+				h, err := globExpr(p.host)
+				if err != nil {
+					return nil, err
+				}
+				host = h
+			} else {
+				// Literal hosts also match with a port
+				host = regexp.QuoteMeta(p.host) + `(?::[0-9]*)?`
+			}
+		}
+		var path string
+		switch {
+		case strings.ContainsAny(p.path, globChars):
+			s, err := globExpr(p.path)
+			if err != nil {
+				return nil, err
+			}
+			path = s
+		case strings.HasSuffix(p.path, "/"):
+			path = regexp.QuoteMeta(p.path) + `(?s:.*)`
+		case p.urn:
+			path = regexp.QuoteMeta(p.path) + `(?s:[/.].*)?`
+		default:
+			path = regexp.QuoteMeta(p.path) + `(?s:/.*)?`
+		}
+		expr = host + path
+	}
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	return syntax.Compile(re.Simplify())
+}
+
+// globExpr - a regular expression matching the same strings as a path.Match glob
+func globExpr(glob string) (string, error) {
+	invalid := errors.New(fmt.Sprintf("invalid glob [%v]", glob))
+	rs := []rune(glob)
+	var b strings.Builder
+	for i := 0; i < len(rs); i++ {
+		switch rs[i] {
+		case '*':
+			b.WriteString(`[^/]*`)
+		case '?':
+			b.WriteString(`[^/]`)
+		case '\\':
+			i++
+			if i == len(rs) {
+				return "", invalid
+			}
+			b.WriteString(regexp.QuoteMeta(string(rs[i])))
+		case '[':
+			i++
+			b.WriteString("[")
+			if i < len(rs) && rs[i] == '^' {
+				b.WriteString("^")
+				i++
+			}
+			for n := 0; ; n++ {
+				if i == len(rs) {
+					return "", invalid
+				}
+				if rs[i] == ']' && n > 0 {
+					break
+				}
+				var lo, hi rune
+				var ok bool
+				if lo, i, ok = globRune(rs, i); !ok {
+					return "", invalid
+				}
+				hi = lo
+				if i < len(rs) && rs[i] == '-' {
+					if hi, i, ok = globRune(rs, i+1); !ok {
+						return "", invalid
+					}
+				}
+				b.WriteString(fmt.Sprintf(`\x{%x}-\x{%x}`, lo, hi))
+			}
+			b.WriteString("]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(rs[i])))
+		}
+	}
+	return b.String(), nil
+}
+
+// globRune - the character of a glob character class at i, and the index after the character
+func globRune(rs []rune, i int) (rune, int, bool) {
+	if i < len(rs) && rs[i] == '\\' {
+		i++
+	}
+	if i == len(rs) {
+		return 0, i, false
+	}
+	return rs[i], i + 1, true
+}
+
+// intersects - determine if there is a string matched by both programs
+func intersects(a, b *syntax.Prog) bool {
+	type state struct{ a, b uint32 }
+	seen := make(map[state]bool)
+	var queue []state
+	add := func(as, bs []uint32) {
+		for _, x := range as {
+			for _, y := range bs {
+				s := state{x, y}
+				if !seen[s] {
+					seen[s] = true
+					queue = append(queue, s)
+				}
+			}
+		}
+	}
+	add(closure(a, uint32(a.Start), true), closure(b, uint32(b.Start), true))
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		ia, ib := &a.Inst[s.a], &b.Inst[s.b]
+		if ia.Op == syntax.InstMatch || ib.Op == syntax.InstMatch {
+			if ia.Op == ib.Op {
+				return true
+			}
+			continue
+		}
+		if rangesIntersect(instRanges(ia), instRanges(ib)) {
+			add(closure(a, ia.Out, false), closure(b, ib.Out, false))
+		}
+	}
+	return false
+}
+
+// closure - the rune and match instructions reachable from pc without reading a rune. Line anchors are not
+// restricted, so the result may include instructions that are not reachable
+func closure(prog *syntax.Prog, pc uint32, start bool) []uint32 {
+	type thread struct {
+		pc  uint32
+		end bool // Only the end of the text can follow
+	}
+	var pcs []uint32
+	seen := make(map[thread]bool)
+	var visit func(t thread)
+	visit = func(t thread) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		i := &prog.Inst[t.pc]
+		switch i.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			visit(thread{i.Out, t.end})
+			visit(thread{i.Arg, t.end})
+		case syntax.InstCapture, syntax.InstNop:
+			visit(thread{i.Out, t.end})
+		case syntax.InstEmptyWidth:
+			op := syntax.EmptyOp(i.Arg)
+			if op&syntax.EmptyBeginText != 0 && !start {
+				return
+			}
+			visit(thread{i.Out, t.end || op&syntax.EmptyEndText != 0})
+		case syntax.InstMatch:
+			pcs = append(pcs, t.pc)
+		case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+			if !t.end {
+				pcs = append(pcs, t.pc)
+			}
+		}
+	}
+	visit(thread{pc: pc})
+	return pcs
+}
+
+// instRanges - the rune ranges, pairs of low and high, matched by a rune instruction
+func instRanges(i *syntax.Inst) []rune {
+	switch i.Op {
+	case syntax.InstRuneAny:
+		return []rune{0, unicode.MaxRune}
+	case syntax.InstRuneAnyNotNL:
+		return []rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}
+	}
+	if len(i.Rune) != 1 {
+		return i.Rune
+	}
+	r := []rune{i.Rune[0], i.Rune[0]}
+	if syntax.Flags(i.Arg)&syntax.FoldCase != 0 {
+		for f := unicode.SimpleFold(i.Rune[0]); f != i.Rune[0]; f = unicode.SimpleFold(f) {
+			r = append(r, f, f)
+		}
+	}
+	return r
+}
+
+func rangesIntersect(a, b []rune) bool {
+	for i := 0; i+1 < len(a); i += 2 {
+		for j := 0; j+1 < len(b); j += 2 {
+			if a[i] <= b[j+1] && b[j] <= a[i+1] {
+				return true
+			}
+		}
+	}
+	return false
+}
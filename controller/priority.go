@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	LowPriority      = 0
+	NormalPriority   = 1
+	HighPriority     = 2
+	CriticalPriority = 3
+	DefaultPriority  = NormalPriority
+
+	LowPriorityName      = "low"
+	NormalPriorityName   = "normal"
+	HighPriorityName     = "high"
+	CriticalPriorityName = "critical"
+)
+
+var priorityNames = []string{LowPriorityName, NormalPriorityName, HighPriorityName, CriticalPriorityName}
+
+// Priority - interface for the criticality of ingress requests, lower priorities are shed first
+type Priority interface {
+	Level() int
+	Header() string
+	RequestLevel(req *http.Request) int
+}
+
+type PriorityConfig struct {
+	Level  string // low, normal, high or critical, "" is normal
+	Header string // Request header that can lower the priority of a request, but not raise it above the route level
+}
+
+func NewPriorityConfig(level, header string) *PriorityConfig {
+	c := new(PriorityConfig)
+	c.Level = level
+	c.Header = header
+	return c
+}
+
+type priority struct {
+	name   string
+	config PriorityConfig
+	level  int
+}
+
+func newPriority(name string, config *PriorityConfig) *priority {
+	t := new(priority)
+	t.name = name
+	if config != nil {
+		t.config = *config
+	}
+	t.level = DefaultPriority
+	if l, ok := ParsePriority(t.config.Level); ok {
+		t.level = l
+	}
+	return t
+}
+
+func (p *priority) validate() error {
+	if _, ok := ParsePriority(p.config.Level); !ok {
+		return newFieldError("level", errors.New(fmt.Sprintf("invalid configuration: Priority level is invalid [%v]", p.config.Level)))
+	}
+	return nil
+}
+
+func priorityState(m map[string]string, p *priority, req *http.Request) {
+	if p == nil {
+		m[PriorityName] = ""
+	} else {
+		m[PriorityName] = PriorityString(p.RequestLevel(req))
+	}
+}
+
+func (p *priority) Level() int {
+	return p.level
+}
+
+func (p *priority) Header() string {
+	return p.config.Header
+}
+
+// RequestLevel - the priority of a request, the header value is ignored if it is invalid or above the route level
+func (p *priority) RequestLevel(req *http.Request) int {
+	if p.config.Header == "" || req == nil || req.Header == nil {
+		return p.level
+	}
+	value := req.Header.Get(p.config.Header)
+	if value == "" {
+		return p.level
+	}
+	l, ok := ParsePriority(value)
+	if !ok || l > p.level {
+		return p.level
+	}
+	return l
+}
+
+// ParsePriority - parse a priority name or number, "" is DefaultPriority
+func ParsePriority(s string) (int, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return DefaultPriority, true
+	}
+	for i, name := range priorityNames {
+		if s == name {
+			return i, true
+		}
+	}
+	l, err := strconv.Atoi(s)
+	if err != nil || l < LowPriority || l > CriticalPriority {
+		return 0, false
+	}
+	return l, true
+}
+
+// PriorityString - the name of a priority level
+func PriorityString(level int) string {
+	if level < LowPriority || level > CriticalPriority {
+		return strconv.Itoa(level)
+	}
+	return priorityNames[level]
+}
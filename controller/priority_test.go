@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func Example_newPriority() {
+	p := newPriority("test-route", NewPriorityConfig(HighPriorityName, "X-Priority"))
+	fmt.Printf("test: newPriority() -> [name:%v] [level:%v] [header:%v] [validate:%v]\n", p.name, p.Level(), p.Header(), p.validate())
+
+	p = newPriority("test-route", NewPriorityConfig("", ""))
+	fmt.Printf("test: newPriority(\"\") -> [level:%v] [validate:%v]\n", PriorityString(p.Level()), p.validate())
+	fmt.Printf("test: validate() -> %v\n", newPriority("test-route", NewPriorityConfig("urgent", "")).validate())
+
+	//Output:
+	//test: newPriority() -> [name:test-route] [level:2] [header:X-Priority] [validate:<nil>]
+	//test: newPriority("") -> [level:normal] [validate:<nil>]
+	//test: validate() -> level: invalid configuration: Priority level is invalid [urgent]
+
+}
+
+func Example_Priority_RequestLevel() {
+	p := newPriority("test-route", NewPriorityConfig(HighPriorityName, "X-Priority"))
+	req, _ := http.NewRequest("GET", "https://www.google.com/search", nil)
+	fmt.Printf("test: RequestLevel() -> %v\n", PriorityString(p.RequestLevel(req)))
+
+	req.Header.Set("X-Priority", "low")
+	fmt.Printf("test: RequestLevel(low) -> %v\n", PriorityString(p.RequestLevel(req)))
+
+	req.Header.Set("X-Priority", "1")
+	fmt.Printf("test: RequestLevel(1) -> %v\n", PriorityString(p.RequestLevel(req)))
+
+	req.Header.Set("X-Priority", "critical")
+	fmt.Printf("test: RequestLevel(critical) -> %v\n", PriorityString(p.RequestLevel(req)))
+
+	req.Header.Set("X-Priority", "invalid")
+	fmt.Printf("test: RequestLevel(invalid) -> %v\n", PriorityString(p.RequestLevel(req)))
+
+	m := make(map[string]string)
+	req.Header.Set("X-Priority", "low")
+	priorityState(m, p, req)
+	fmt.Printf("test: priorityState(map) -> %v\n", m)
+	priorityState(m, nil, req)
+	fmt.Printf("test: priorityState(map,nil) -> %v\n", m)
+
+	//Output:
+	//test: RequestLevel() -> high
+	//test: RequestLevel(low) -> low
+	//test: RequestLevel(1) -> normal
+	//test: RequestLevel(critical) -> high
+	//test: RequestLevel(invalid) -> high
+	//test: priorityState(map) -> map[priority:low]
+	//test: priorityState(map,nil) -> map[priority:]
+
+}
+
+func Example_Priority_Traffic() {
+	name := "test-route"
+	t := newTable(false, false)
+	errs := t.AddController(newRoute(name, NewPriorityConfig(CriticalPriorityName, "")))
+	p, ok := t.LookupByName(name).Priority()
+	fmt.Printf("test: Add(ingress) -> [errors:%v] [ok:%v] [level:%v]\n", errs, ok, PriorityString(p.Level()))
+
+	errs = t.SetHostController(newRoute(HostControllerName, NewPriorityConfig(CriticalPriorityName, "")))
+	fmt.Printf("test: SetHostController() -> [errors:%v]\n", errs)
+
+	t2 := newTable(true, false)
+	errs = t2.AddController(newRoute(name, NewPriorityConfig(CriticalPriorityName, "")))
+	fmt.Printf("test: Add(egress) -> [errors:%v]\n", errs)
+
+	//Output:
+	//test: Add(ingress) -> [errors:[]] [ok:true] [level:critical]
+	//test: SetHostController() -> [errors:[host controller configuration does not allow retry, rate limiter, or failover controllers]]
+	//test: Add(egress) -> [errors:[priority: invalid configuration: Priority is not valid for egress traffic]]
+
+}
@@ -2,8 +2,10 @@ package controller
 
 import (
 	"errors"
+	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 // Proxy - interface for proxy
@@ -14,11 +16,26 @@ type Proxy interface {
 	Pattern() string
 	SetPattern(pattern string)
 	BuildUrl(uri *url.URL) *url.URL
+	Endpoints() []Endpoint
+	Ejected() []string
+	Select(req *http.Request) (uri *url.URL, endpoint int, branch string)
+	Done(endpoint int, success bool)
+	CanaryPercentage() int
+	SetCanaryPercentage(percentage int)
 }
 
 type ProxyConfig struct {
-	Enabled bool
-	Pattern string
+	Enabled      bool
+	Pattern      string
+	Endpoints    []Endpoint    // Weighted endpoints, used instead of Pattern when configured
+	Strategy     string        // round-robin, random, least-request, or consistent-hash
+	HashHeader   string        // Consistent hash key
+	MaxFailures  int           // Consecutive failures that eject an endpoint, 0 is DefaultMaxFailures
+	EjectionTime time.Duration // 0 is DefaultEjectionTime
+
+	CanaryPattern    string // Pattern for the canary branch
+	CanaryPercentage int    // Percentage of requests sent to the canary branch
+	CanaryHeader     string // Header that makes the branch sticky, such as a user id
 }
 
 func NewProxyConfig(enabled bool, pattern string) *ProxyConfig {
@@ -26,10 +43,24 @@ func NewProxyConfig(enabled bool, pattern string) *ProxyConfig {
 }
 
 type proxy struct {
-	table   *table
-	name    string
-	enabled bool
-	pattern string
+	table        *table
+	name         string
+	enabled      bool
+	pattern      string
+	endpoints    []Endpoint
+	strategy     string
+	hashHeader   string
+	maxFailures  int
+	ejectionTime time.Duration
+	balancer     *balancer
+	canary       CanaryConfig
+}
+
+// CanaryConfig - canary branch of a proxy
+type CanaryConfig struct {
+	Pattern    string
+	Percentage int
+	Header     string
 }
 
 func cloneProxy(curr *proxy) *proxy {
@@ -45,15 +76,34 @@ func newProxy(name string, table *table, config *ProxyConfig) *proxy {
 	if config != nil {
 		t.enabled = config.Enabled
 		t.pattern = config.Pattern
+		t.endpoints = config.Endpoints
+		t.strategy = config.Strategy
+		t.hashHeader = config.HashHeader
+		t.maxFailures = config.MaxFailures
+		t.ejectionTime = config.EjectionTime
+		t.canary = CanaryConfig{Pattern: config.CanaryPattern, Percentage: config.CanaryPercentage, Header: config.CanaryHeader}
 	}
+	if t.maxFailures <= 0 {
+		t.maxFailures = DefaultMaxFailures
+	}
+	if t.ejectionTime <= 0 {
+		t.ejectionTime = DefaultEjectionTime
+	}
+	t.balancer = newBalancer(t.endpoints)
 	return t
 }
 
 func (p *proxy) validate() error {
-	if len(p.pattern) == 0 && p.enabled {
-		return errors.New("invalid configuration: Proxy pattern is empty for enabled proxy")
+	if len(p.pattern) == 0 && len(p.endpoints) == 0 && p.enabled {
+		return newFieldError("pattern", errors.New("invalid configuration: Proxy pattern is empty for enabled proxy"))
+	}
+	if p.canary.Percentage < 0 || p.canary.Percentage > 100 {
+		return newFieldError("canaryPercentage", errors.New("invalid configuration: Proxy canary percentage is not between 0 and 100"))
 	}
-	return nil
+	if p.canary.Percentage > 0 && len(p.canary.Pattern) == 0 {
+		return newFieldError("canaryPattern", errors.New("invalid configuration: Proxy canary pattern is empty for canary percentage > 0"))
+	}
+	return validateEndpoints(p.endpoints, p.strategy, p.hashHeader)
 }
 
 func proxyState(m map[string]string, p *proxy) {
@@ -64,6 +114,22 @@ func proxyState(m map[string]string, p *proxy) {
 	}
 }
 
+func proxyEndpointState(m map[string]string, p *proxy, endpoint string) {
+	if p == nil {
+		m[EndpointName] = ""
+	} else {
+		m[EndpointName] = endpoint
+	}
+}
+
+func proxyBranchState(m map[string]string, p *proxy, branch string) {
+	if p == nil {
+		m[BranchName] = ""
+	} else {
+		m[BranchName] = branch
+	}
+}
+
 func (p *proxy) IsEnabled() bool { return p.enabled }
 
 func (p *proxy) Disable() {
@@ -90,11 +156,72 @@ func (p *proxy) SetPattern(pattern string) {
 	}
 }
 
+func (p *proxy) Endpoints() []Endpoint {
+	return p.endpoints
+}
+
+// Ejected - endpoints currently ejected for failures
+func (p *proxy) Ejected() []string {
+	return p.balancer.ejected()
+}
+
+// Select - build the proxy url from the canary pattern for the canary percentage of requests, otherwise from a
+// selected endpoint, or from the pattern if there are no endpoints. The endpoint is the index of the selected
+// endpoint, or -1, and a selected endpoint must be followed by a call to Done
+func (p *proxy) Select(req *http.Request) (*url.URL, int, string) {
+	if req == nil {
+		return nil, -1, ""
+	}
+	if p.isCanary(req) {
+		return buildUrl(p.canary.Pattern, req.URL), -1, CanaryBranch
+	}
+	if len(p.endpoints) == 0 {
+		return p.BuildUrl(req.URL), -1, PrimaryBranch
+	}
+	endpoint := p.balancer.selectEndpoint(p.strategy, hashKey(req, p.hashHeader))
+	return buildUrl(p.endpoints[endpoint].Url, req.URL), endpoint, PrimaryBranch
+}
+
+// isCanary - a sticky header value always selects the same branch for a given percentage
+func (p *proxy) isCanary(req *http.Request) bool {
+	if p.canary.Percentage <= 0 {
+		return false
+	}
+	if key := hashKey(req, p.canary.Header); key != "" {
+		return int(hash(key)%100) < p.canary.Percentage
+	}
+	return p.balancer.intn(100) < p.canary.Percentage
+}
+
+func (p *proxy) CanaryPercentage() int {
+	return p.canary.Percentage
+}
+
+func (p *proxy) SetCanaryPercentage(percentage int) {
+	if percentage < 0 || percentage > 100 || percentage == p.canary.Percentage || len(p.canary.Pattern) == 0 {
+		return
+	}
+	p.table.setProxyCanary(p.name, percentage)
+}
+
+// Done - record the outcome of a request sent to the selected endpoint index
+func (p *proxy) Done(endpoint int, success bool) {
+	if endpoint < 0 {
+		return
+	}
+	p.balancer.done(endpoint, success, p.maxFailures, p.ejectionTime)
+}
+
 func (p *proxy) BuildUrl(uri *url.URL) *url.URL {
-	if uri == nil || len(p.pattern) == 0 {
+	return buildUrl(p.pattern, uri)
+}
+
+// buildUrl - the uri is returned when the pattern is empty or the new url cannot be built
+func buildUrl(pattern string, uri *url.URL) *url.URL {
+	if uri == nil || len(pattern) == 0 {
 		return uri
 	}
-	uri2, err := url.Parse(p.pattern)
+	uri2, err := url.Parse(pattern)
 	if err != nil {
 		return uri
 	}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
 )
 
@@ -120,3 +121,55 @@ func Example_Proxy_Enable() {
 	//test: Enable() -> [prev-enabled:false] [curr-enabled:true]
 
 }
+
+func Example_Proxy_Canary() {
+	name := "test-route"
+	t := newTable(true, false)
+	config := &ProxyConfig{Enabled: true, Pattern: "http://primary:8080", CanaryPattern: "http://canary:8080", CanaryPercentage: 0, CanaryHeader: "X-User-Id"}
+	t.AddController(newRoute(name, config))
+	req, _ := http.NewRequest("GET", "https://www.google.com/search?q=test", nil)
+
+	ctrl := t.LookupByName(name)
+	p, _ := ctrl.Proxy()
+	uri, _, branch := p.Select(req)
+	fmt.Printf("test: Select(0%%) -> [uri:%v] [branch:%v]\n", uri, branch)
+
+	p.SetCanaryPercentage(100)
+	p, _ = t.LookupByName(name).Proxy()
+	uri, _, branch = p.Select(req)
+	fmt.Printf("test: Select(100%%) -> [uri:%v] [branch:%v] [percentage:%v]\n", uri, branch, p.CanaryPercentage())
+
+	p.SetCanaryPercentage(50)
+	p, _ = t.LookupByName(name).Proxy()
+	sticky := true
+	for _, id := range []string{"user-1", "user-2", "user-3"} {
+		req.Header.Set("X-User-Id", id)
+		_, _, first := p.Select(req)
+		for i := 0; i < 10; i++ {
+			if _, _, b := p.Select(req); b != first {
+				sticky = false
+			}
+		}
+	}
+	fmt.Printf("test: Select(sticky) -> %v\n", sticky)
+
+	p.SetCanaryPercentage(101)
+	p, _ = t.LookupByName(name).Proxy()
+	fmt.Printf("test: SetCanaryPercentage(101) -> [percentage:%v]\n", p.CanaryPercentage())
+
+	m := make(map[string]string, 16)
+	proxyBranchState(m, p.(*proxy), CanaryBranch)
+	fmt.Printf("test: proxyBranchState(map,p) -> %v\n", m)
+
+	config.CanaryPattern = ""
+	config.CanaryPercentage = 10
+	fmt.Printf("test: validate() -> %v\n", newProxy(name, t, config).validate())
+
+	//Output:
+	//test: Select(0%) -> [uri:http://primary:8080/search?q=test] [branch:primary]
+	//test: Select(100%) -> [uri:http://canary:8080/search?q=test] [branch:canary] [percentage:100]
+	//test: Select(sticky) -> true
+	//test: SetCanaryPercentage(101) -> [percentage:50]
+	//test: proxyBranchState(map,p) -> map[branch:canary]
+	//test: validate() -> canaryPattern: invalid configuration: Proxy canary pattern is empty for canary percentage > 0
+}
@@ -7,17 +7,29 @@ import (
 	"math"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 const (
 	InfValue     = "-1"
 	DefaultBurst = 1
+
+	DefaultMaxKeys     = 10000
+	DefaultIdleTimeout = time.Minute * 10
+
+	RateLimitLimitHeaderName     = "RateLimit-Limit"
+	RateLimitRemainingHeaderName = "RateLimit-Remaining"
+	RateLimitResetHeaderName     = "RateLimit-Reset"
 )
 
 // RateLimiter - interface for rate limiting
 type RateLimiter interface {
 	Allow() bool
+	AllowRequest(req *http.Request) (bool, string)
 	StatusCode() int
+	Headers(req *http.Request) http.Header
+	IsHeadersEnabled() bool
+	Body() string
 	SetLimit(limit rate.Limit)
 	SetBurst(burst int)
 	SetRateLimiter(limit rate.Limit, burst int)
@@ -26,9 +38,15 @@ type RateLimiter interface {
 }
 
 type RateLimiterConfig struct {
-	Limit      rate.Limit
-	Burst      int
-	StatusCode int
+	Limit       rate.Limit
+	Burst       int
+	StatusCode  int
+	Key         string        // Key extractor, each key has its own limit and burst, "" limits the route as a whole
+	MaxKeys     int           // 0 is DefaultMaxKeys, the least recently used key is evicted when full
+	IdleTimeout time.Duration // 0 is DefaultIdleTimeout
+	Global      bool          // Limit is shared between instances through the RateLimiterStore
+	Headers     bool          // Add the rate limit headers to allowed responses
+	Body        string        // Body of a rejected response
 }
 
 func NewRateLimiterConfig(limit rate.Limit, burst int, statusCode int) *RateLimiterConfig {
@@ -48,6 +66,8 @@ type rateLimiter struct {
 	table       *table
 	config      RateLimiterConfig
 	rateLimiter *rate.Limiter
+	keys        *keyedLimiters
+	statuses    *keyedLimiters // Latest store status by key, for the headers of a global limiter
 }
 
 func cloneRateLimiter(curr *rateLimiter) *rateLimiter {
@@ -65,6 +85,12 @@ func newRateLimiter(name string, table *table, config *RateLimiterConfig) *rateL
 		t.config = *config
 	}
 	t.rateLimiter = rate.NewLimiter(t.config.Limit, t.config.Burst)
+	if t.config.Key != "" {
+		t.keys = newKeyedLimiters(t.config.MaxKeys, t.config.IdleTimeout)
+	}
+	if t.config.Global {
+		t.statuses = newKeyedLimiters(t.config.MaxKeys, t.config.IdleTimeout)
+	}
 	return t
 }
 
@@ -79,10 +105,19 @@ func validateLimiter(max *rate.Limit, burst *int) {
 
 func (r *rateLimiter) validate() error {
 	if r.config.Limit < 0 {
-		return errors.New(fmt.Sprintf("invalid configuration: RateLimiter limit is < 0"))
+		return newFieldError("limit", errors.New(fmt.Sprintf("invalid configuration: RateLimiter limit is < 0")))
 	}
 	if r.config.Burst < 0 {
-		return errors.New(fmt.Sprintf("invalid configuration: RateLimiter burst is < 0"))
+		return newFieldError("burst", errors.New(fmt.Sprintf("invalid configuration: RateLimiter burst is < 0")))
+	}
+	if err := validateKey(r.config.Key); err != nil {
+		return err
+	}
+	if r.config.MaxKeys < 0 {
+		return newFieldError("maxKeys", errors.New("invalid configuration: RateLimiter max keys is < 0"))
+	}
+	if r.config.IdleTimeout < 0 {
+		return newFieldError("idleTimeout", errors.New("invalid configuration: RateLimiter idle timeout is < 0"))
 	}
 	return nil
 }
@@ -106,17 +141,127 @@ func rateLimiterState(m map[string]string, r *rateLimiter) map[string]string {
 	return m
 }
 
+func rateLimitKeyState(m map[string]string, r *rateLimiter, req *http.Request, statusFlags string) {
+	if r == nil || r.keys == nil || statusFlags != RateLimitFlag {
+		m[RateLimitKeyName] = ""
+	} else {
+		m[RateLimitKeyName] = extractKey(r.config.Key, req)
+	}
+}
+
+// Allow - determine if a request is allowed by the route limiter. A global limiter uses the local limiter when the
+// store is unreachable
 func (r *rateLimiter) Allow() bool {
 	if r.config.Limit == rate.Inf {
 		return true
 	}
+	if ok, err := r.allowGlobal(""); err == nil {
+		return ok
+	}
 	return r.rateLimiter.Allow()
 }
 
+func (r *rateLimiter) allowGlobal(key string) (bool, error) {
+	if !r.config.Global {
+		return false, errors.New("rate limiter is not global")
+	}
+	status, err := rateLimiterStore.Allow(r.storeKey(key), r.config.Limit, r.config.Burst)
+	if err != nil {
+		r.statuses.setStatus(key, nil, r.config.Limit, r.config.Burst, time.Now())
+		return false, err
+	}
+	r.statuses.setStatus(key, &status, r.config.Limit, r.config.Burst, time.Now())
+	return status.Allowed, nil
+}
+
+func (r *rateLimiter) storeKey(key string) string {
+	if key != "" {
+		return r.name + ":" + key
+	}
+	return r.name
+}
+
+// AllowRequest - determine if a request is allowed by the limiter for its key, the key is returned when the
+// request is not allowed
+func (r *rateLimiter) AllowRequest(req *http.Request) (bool, string) {
+	if r.keys == nil {
+		return r.Allow(), ""
+	}
+	if r.config.Limit == rate.Inf {
+		return true, ""
+	}
+	key := extractKey(r.config.Key, req)
+	ok, err := r.allowGlobal(key)
+	if err != nil {
+		ok = r.keys.get(key, r.config.Limit, r.config.Burst, time.Now()).Allow()
+	}
+	if ok {
+		return true, ""
+	}
+	return false, key
+}
+
 func (r *rateLimiter) StatusCode() int {
 	return r.config.StatusCode
 }
 
+func (r *rateLimiter) IsHeadersEnabled() bool {
+	return r.config.Headers
+}
+
+func (r *rateLimiter) Body() string {
+	return r.config.Body
+}
+
+// Headers - the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers for the limiter of a request,
+// https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/. Retry-After is added when there are no
+// remaining requests. The headers of a global limiter are from the latest store status, or from the local limiter
+// state when the store is unreachable.
+func (r *rateLimiter) Headers(req *http.Request) http.Header {
+	h := make(http.Header)
+	if r.config.Limit == rate.Inf || r.config.Limit <= 0 {
+		return h
+	}
+	if r.statuses != nil {
+		key := ""
+		if r.keys != nil {
+			key = extractKey(r.config.Key, req)
+		}
+		if status, at := r.statuses.status(key); status != nil {
+			return r.storeHeaders(h, status, at)
+		}
+	}
+	tokens := float64(r.config.Burst)
+	if r.keys == nil {
+		tokens = r.rateLimiter.Tokens()
+	} else if l := r.keys.peek(extractKey(r.config.Key, req)); l != nil {
+		tokens = l.Tokens()
+	}
+	remaining := int(math.Max(0, math.Floor(tokens)))
+	reset := math.Ceil((float64(r.config.Burst) - tokens) / float64(r.config.Limit))
+	h.Set(RateLimitLimitHeaderName, strconv.Itoa(r.config.Burst))
+	h.Set(RateLimitRemainingHeaderName, strconv.Itoa(remaining))
+	h.Set(RateLimitResetHeaderName, strconv.Itoa(int(math.Max(0, reset))))
+	if remaining == 0 {
+		retryAfter := math.Ceil((1 - tokens) / float64(r.config.Limit))
+		h.Set(RetryAfterHeaderName, strconv.Itoa(int(math.Max(1, retryAfter))))
+	}
+	return h
+}
+
+// storeHeaders - the headers from a store status, Retry-After is at least the time for one request
+func (r *rateLimiter) storeHeaders(h http.Header, status *StoreStatus, at time.Time) http.Header {
+	reset := math.Max(0, math.Ceil((status.Reset - time.Since(at)).Seconds()))
+	h.Set(RateLimitLimitHeaderName, strconv.Itoa(r.config.Burst))
+	h.Set(RateLimitRemainingHeaderName, strconv.Itoa(status.Remaining))
+	h.Set(RateLimitResetHeaderName, strconv.Itoa(int(reset)))
+	if !status.Allowed || status.Remaining == 0 {
+		retryAfter := math.Max(reset, math.Ceil(1/float64(r.config.Limit)))
+		h.Set(RetryAfterHeaderName, strconv.Itoa(int(math.Max(1, retryAfter))))
+	}
+	return h
+}
+
 func (r *rateLimiter) SetLimit(limit rate.Limit) {
 	if r.config.Limit == limit {
 		return
@@ -3,6 +3,7 @@ package controller
 import (
 	"fmt"
 	"golang.org/x/time/rate"
+	"net/http"
 )
 
 func Example_newRateLimiter() {
@@ -124,3 +125,31 @@ func Example_RateLimiter_Adjust() {
 	//test: AdjustRateLimiter(-10) -> [true] [state:map[burst:25 rateLimit:99]]
 
 }
+
+func Example_RateLimiter_Headers() {
+	req, _ := http.NewRequest("GET", "https://www.google.com/search", nil)
+	req.Header.Set("X-User-Id", "user-1")
+	r := newRateLimiter("test-route", nil, NewRateLimiterConfig(5, 10, 429))
+	r.Allow()
+	r.Allow()
+	r.Allow()
+	h := r.Headers(req)
+	fmt.Printf("test: Headers() -> [limit:%v] [remaining:%v] [reset:%v] [retry-after:%v]\n", h.Get(RateLimitLimitHeaderName),
+		h.Get(RateLimitRemainingHeaderName), h.Get(RateLimitResetHeaderName), h.Get(RetryAfterHeaderName))
+
+	config := NewRateLimiterConfig(5, 10, 429)
+	config.Key = "header:X-User-Id"
+	r = newRateLimiter("test-route", nil, config)
+	h = r.Headers(req)
+	fmt.Printf("test: Headers(new-key) -> [limit:%v] [remaining:%v] [reset:%v]\n", h.Get(RateLimitLimitHeaderName),
+		h.Get(RateLimitRemainingHeaderName), h.Get(RateLimitResetHeaderName))
+
+	r = newRateLimiter("test-route", nil, NewRateLimiterConfig(rate.Inf, 10, 429))
+	fmt.Printf("test: Headers(inf) -> %v\n", r.Headers(req))
+
+	//Output:
+	//test: Headers() -> [limit:10] [remaining:7] [reset:1] [retry-after:]
+	//test: Headers(new-key) -> [limit:10] [remaining:10] [reset:0]
+	//test: Headers(inf) -> map[]
+
+}
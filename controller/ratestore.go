@@ -0,0 +1,261 @@
+package controller
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"golang.org/x/time/rate"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultStoreTimeout    = time.Millisecond * 100
+	DefaultStorePoolSize   = 8
+	DefaultStoreBackoff    = time.Second
+	DefaultStoreMaxBackoff = time.Second * 30
+
+	storeKeyPrefix = "rate:"
+)
+
+// RateLimiterStore - interface for rate limiter state shared between instances
+type RateLimiterStore interface {
+	Allow(key string, limit rate.Limit, burst int) (StoreStatus, error)
+}
+
+// StoreStatus - the result of a store Allow, used for the rate limit headers of a global limiter
+type StoreStatus struct {
+	Allowed   bool
+	Remaining int           // Requests remaining
+	Reset     time.Duration // Time until the limit is reset
+}
+
+var rateLimiterStore RateLimiterStore = NewMemoryRateLimiterStore()
+
+// SetRateLimiterStore - configuration of the store used by global rate limiters
+func SetRateLimiterStore(store RateLimiterStore) {
+	if store != nil {
+		rateLimiterStore = store
+	}
+}
+
+// memoryStore - a token bucket by key, limits are only shared within the process. Keys are bounded by the same
+// least recently used list as keyed rate limiters
+type memoryStore struct {
+	limiters *keyedLimiters
+}
+
+// NewMemoryRateLimiterStore - create an in-memory store
+func NewMemoryRateLimiterStore() RateLimiterStore {
+	return &memoryStore{limiters: newKeyedLimiters(DefaultMaxKeys, DefaultIdleTimeout)}
+}
+
+func (s *memoryStore) Allow(key string, limit rate.Limit, burst int) (StoreStatus, error) {
+	now := time.Now()
+	l := s.limiters.get(key, limit, burst, now)
+	if l.Limit() != limit {
+		l.SetLimit(limit)
+	}
+	if l.Burst() != burst {
+		l.SetBurst(burst)
+	}
+	status := StoreStatus{Allowed: l.AllowN(now, 1)}
+	if limit == rate.Inf || limit <= 0 {
+		return status, nil
+	}
+	tokens := l.TokensAt(now)
+	status.Remaining = int(math.Max(0, math.Floor(tokens)))
+	status.Reset = time.Duration(math.Max(0, float64(burst)-tokens) / float64(limit) * float64(time.Second))
+	return status, nil
+}
+
+// redisStore - a fixed window counter by key, in a server that speaks the Redis protocol (RESP). Connections are
+// pooled, and after a failure the server is not used until a backoff has elapsed, so that requests use the local
+// limiter without waiting on the server
+type redisStore struct {
+	addr     string
+	password string
+	timeout  time.Duration
+	pool     chan *redisConn
+	mu       sync.Mutex
+	failures int
+	retryAt  time.Time
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedisRateLimiterStore - create a store for a Redis protocol compatible server, a timeout of 0 is
+// DefaultStoreTimeout
+func NewRedisRateLimiterStore(addr, password string, timeout time.Duration) RateLimiterStore {
+	if timeout <= 0 {
+		timeout = DefaultStoreTimeout
+	}
+	return &redisStore{addr: addr, password: password, timeout: timeout, pool: make(chan *redisConn, DefaultStorePoolSize)}
+}
+
+// Allow - the limit over a window is the greater of the burst and the limit for the window, which approximates
+// a token bucket without a server side script
+func (s *redisStore) Allow(key string, limit rate.Limit, burst int) (StoreStatus, error) {
+	if limit == rate.Inf {
+		return StoreStatus{Allowed: true}, nil
+	}
+	if limit <= 0 {
+		return StoreStatus{}, nil
+	}
+	window := time.Second
+	if limit < 1 {
+		window = time.Duration(float64(time.Second) / float64(limit))
+	}
+	max := int64(math.Max(float64(burst), math.Ceil(float64(limit)*window.Seconds())))
+	now := time.Now()
+	id := now.UnixNano() / int64(window)
+	k := storeKeyPrefix + key + ":" + strconv.FormatInt(id, 10)
+	ttl := strconv.FormatInt(int64(window/time.Millisecond)*2, 10)
+
+	if err := s.available(); err != nil {
+		return StoreStatus{}, err
+	}
+	replies, err := s.pipeline([]string{"INCR", k}, []string{"PEXPIRE", k, ttl})
+	if err != nil {
+		s.failed()
+		return StoreStatus{}, err
+	}
+	s.succeeded()
+	return StoreStatus{
+		Allowed:   replies[0] <= max,
+		Remaining: int(math.Max(0, float64(max-replies[0]))),
+		Reset:     time.Duration((id+1)*int64(window) - now.UnixNano()),
+	}, nil
+}
+
+// available - returns an error while backing off after a failure, once the backoff has elapsed one request is
+// sent to the server
+func (s *redisStore) available() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures == 0 {
+		return nil
+	}
+	now := time.Now()
+	if now.Before(s.retryAt) {
+		return errors.New(fmt.Sprintf("store is unavailable [%v]", s.addr))
+	}
+	s.retryAt = now.Add(s.timeout)
+	return nil
+}
+
+func (s *redisStore) failed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	backoff := DefaultStoreMaxBackoff
+	if s.failures < 5 {
+		backoff = DefaultStoreBackoff << s.failures
+	}
+	s.failures++
+	s.retryAt = time.Now().Add(backoff)
+}
+
+func (s *redisStore) succeeded() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = 0
+}
+
+// pipeline - send the commands on a pooled connection, and read the replies. A pooled connection may have been
+// closed by the server, so the commands are sent once more on a new connection
+func (s *redisStore) pipeline(cmds ...[]string) ([]int64, error) {
+	for {
+		c, pooled, err := s.get()
+		if err != nil {
+			return nil, err
+		}
+		replies, err := c.pipeline(s.timeout, cmds...)
+		if err == nil {
+			s.put(c)
+			return replies, nil
+		}
+		c.conn.Close()
+		if !pooled {
+			return nil, err
+		}
+	}
+}
+
+func (s *redisStore) get() (*redisConn, bool, error) {
+	select {
+	case c := <-s.pool:
+		return c, true, nil
+	default:
+	}
+	conn, err := net.DialTimeout("tcp", s.addr, s.timeout)
+	if err != nil {
+		return nil, false, err
+	}
+	c := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+	if s.password != "" {
+		if _, err = c.pipeline(s.timeout, []string{"AUTH", s.password}); err != nil {
+			conn.Close()
+			return nil, false, err
+		}
+	}
+	return c, false, nil
+}
+
+// put - return a connection to the pool, the connection is closed if the pool is full
+func (s *redisStore) put(c *redisConn) {
+	select {
+	case s.pool <- c:
+	default:
+		c.conn.Close()
+	}
+}
+
+// pipeline - send the commands, and read an integer or simple string reply for each command
+func (c *redisConn) pipeline(timeout time.Duration, cmds ...[]string) ([]int64, error) {
+	c.conn.SetDeadline(time.Now().Add(timeout))
+	var buf []byte
+	for _, args := range cmds {
+		buf = append(buf, "*"+strconv.Itoa(len(args))+"\r\n"...)
+		for _, arg := range args {
+			buf = append(buf, "$"+strconv.Itoa(len(arg))+"\r\n"+arg+"\r\n"...)
+		}
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		return nil, err
+	}
+	replies := make([]int64, len(cmds))
+	for i := range cmds {
+		reply, err := c.reply()
+		if err != nil {
+			return nil, err
+		}
+		replies[i] = reply
+	}
+	return replies, nil
+}
+
+func (c *redisConn) reply() (int64, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	if len(line) < 3 {
+		return 0, errors.New(fmt.Sprintf("invalid reply: [%q]", line))
+	}
+	line = line[:len(line)-2]
+	switch line[0] {
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '+':
+		return 0, nil
+	case '-':
+		return 0, errors.New(line[1:])
+	}
+	return 0, errors.New(fmt.Sprintf("unsupported reply: [%q]", line))
+}
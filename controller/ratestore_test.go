@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// respServer - local stand-in for a Redis protocol server, supporting AUTH, INCR and PEXPIRE
+type respServer struct {
+	ln     net.Listener
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func newRespServer() *respServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil
+	}
+	s := &respServer{ln: ln, counts: make(map[string]int64)}
+	go func() {
+		for {
+			conn, err1 := ln.Accept()
+			if err1 != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *respServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		var args []string
+		for i := 0; i < n; i++ {
+			r.ReadString('\n')
+			arg, _ := r.ReadString('\n')
+			args = append(args, strings.TrimSpace(arg))
+		}
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			conn.Write([]byte("+OK\r\n"))
+		case "INCR":
+			s.mu.Lock()
+			s.counts[args[1]]++
+			count := s.counts[args[1]]
+			s.mu.Unlock()
+			conn.Write([]byte(":" + strconv.FormatInt(count, 10) + "\r\n"))
+		case "PEXPIRE":
+			conn.Write([]byte(":1\r\n"))
+		default:
+			conn.Write([]byte("-ERR unknown command\r\n"))
+		}
+	}
+}
+
+func ExampleNewRedisRateLimiterStore() {
+	server := newRespServer()
+	defer server.ln.Close()
+
+	// Two instances sharing a limit of 2 requests per second
+	s1 := NewRedisRateLimiterStore(server.ln.Addr().String(), "password", time.Second)
+	s2 := NewRedisRateLimiterStore(server.ln.Addr().String(), "password", time.Second)
+
+	// Align to the start of a window
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	st1, err1 := s1.Allow("test-route", 2, 1)
+	st2, err2 := s2.Allow("test-route", 2, 1)
+	st3, err3 := s1.Allow("test-route", 2, 1)
+	fmt.Printf("test: Allow() -> [%v %v] [%v %v] [%v %v]\n", st1.Allowed, err1, st2.Allowed, err2, st3.Allowed, err3)
+	fmt.Printf("test: Allow() -> [remaining:%v %v %v] [reset:%v]\n", st1.Remaining, st2.Remaining, st3.Remaining, st3.Reset > 0 && st3.Reset <= time.Second)
+
+	s3 := NewRedisRateLimiterStore("127.0.0.1:1", "", time.Millisecond*50)
+	_, err := s3.Allow("test-route", 2, 1)
+	fmt.Printf("test: Allow(unreachable) -> [error:%v]\n", err != nil)
+	_, err = s3.Allow("test-route", 2, 1)
+	fmt.Printf("test: Allow(backoff) -> [error:%v]\n", err)
+
+	fmt.Printf("test: pool() -> [idle:%v]\n", len(s1.(*redisStore).pool))
+
+	//Output:
+	//test: Allow() -> [true <nil>] [true <nil>] [false <nil>]
+	//test: Allow() -> [remaining:1 0 0] [reset:true]
+	//test: Allow(unreachable) -> [error:true]
+	//test: Allow(backoff) -> [error:store is unavailable [127.0.0.1:1]]
+	//test: pool() -> [idle:1]
+
+}
+
+func ExampleNewMemoryRateLimiterStore() {
+	s := &memoryStore{limiters: newKeyedLimiters(2, 0)}
+	st1, _ := s.Allow("key-1", 1, 1)
+	st2, _ := s.Allow("key-1", 1, 1)
+	s.Allow("key-2", 1, 1)
+	s.Allow("key-3", 1, 1)
+	fmt.Printf("test: Allow() -> [%v] [%v] [keys:%v]\n", st1.Allowed, st2.Allowed, s.limiters.count())
+	fmt.Printf("test: Allow() -> [remaining:%v] [reset:%v]\n", st2.Remaining, st2.Reset.Round(time.Second))
+
+	// The least recently used key was evicted
+	st1, _ = s.Allow("key-1", 1, 1)
+	fmt.Printf("test: Allow(evicted) -> [%v]\n", st1.Allowed)
+
+	//Output:
+	//test: Allow() -> [true] [false] [keys:2]
+	//test: Allow() -> [remaining:0] [reset:1s]
+	//test: Allow(evicted) -> [true]
+
+}
+
+func Example_RateLimiter_Global() {
+	name := "test-route"
+	t := newTable(true, false)
+	config := NewRateLimiterConfig(1, 1, 429)
+	config.Global = true
+	t.AddController(newRoute(name, config))
+	rl, _ := t.LookupByName(name).RateLimiter()
+
+	store := NewMemoryRateLimiterStore()
+	SetRateLimiterStore(store)
+	status, _ := store.Allow(name, 1, 1)
+	fmt.Printf("test: Allow(other-instance) -> %v\n", status.Allowed)
+	fmt.Printf("test: Allow() -> %v\n", rl.Allow())
+	req, _ := http.NewRequest("GET", "https://www.google.com/search", nil)
+	h := rl.Headers(req)
+	fmt.Printf("test: Headers() -> [limit:%v] [remaining:%v] [reset:%v] [retry-after:%v]\n", h.Get(RateLimitLimitHeaderName),
+		h.Get(RateLimitRemainingHeaderName), h.Get(RateLimitResetHeaderName), h.Get(RetryAfterHeaderName))
+
+	// Local fallback
+	SetRateLimiterStore(NewRedisRateLimiterStore("127.0.0.1:1", "", time.Millisecond*50))
+	fmt.Printf("test: Allow(fallback) -> %v\n", rl.Allow())
+	fmt.Printf("test: Allow(fallback) -> %v\n", rl.Allow())
+	SetRateLimiterStore(NewMemoryRateLimiterStore())
+
+	//Output:
+	//test: Allow(other-instance) -> true
+	//test: Allow() -> false
+	//test: Headers() -> [limit:1] [remaining:0] [reset:1] [retry-after:1]
+	//test: Allow(fallback) -> true
+	//test: Allow(fallback) -> false
+
+}
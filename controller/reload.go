@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gotemplates/host/decode"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reload - replace the table routes with the routes read from the []byte representation of a route configuration.
+// All routes are validated before the table is updated, so either every change is applied or none are. Rate limiter
+// and circuit breaker state is kept for routes whose configuration did not change.
+func (t *table) Reload(buf []byte) []error {
+	doc, routes, errs := readRoutesJSON(buf)
+	if len(errs) > 0 {
+		return errs
+	}
+	return routeErrors(doc, t.reload(routes))
+}
+
+func (t *table) reload(routes []Route) []error {
+	var errs []error
+	var hostCtrl, defaultCtrl *controller
+	controllers := make(map[string]*controller, len(routes))
+	r := newRouter()
+
+	for i, route := range routes {
+		var ctrl *controller
+		var errs1 []error
+		switch {
+		case !t.egress && route.Name == HostControllerName:
+			ctrl, errs1 = t.newHostController(route)
+			hostCtrl = ctrl
+		case (t.egress && route.Name == DefaultEgressRouteName) || (!t.egress && route.Name == DefaultIngressRouteName):
+			ctrl, errs1 = t.newValidController(route)
+			defaultCtrl = ctrl
+		default:
+			errs1 = t.addRoute(controllers, r, route)
+		}
+		for _, err := range errs1 {
+			errs = append(errs, withPath(decode.Index("", i), err))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for name, ctrl := range controllers {
+		if curr, ok := t.controllers[name]; ok {
+			retainState(curr, ctrl)
+		}
+	}
+	if hostCtrl != nil {
+		retainState(t.hostCtrl, hostCtrl)
+		t.hostCtrl = hostCtrl
+	}
+	if defaultCtrl != nil {
+		retainState(t.defaultCtrl, defaultCtrl)
+		t.defaultCtrl = defaultCtrl
+	}
+	t.controllers = controllers
+	t.router = r
+	return nil
+}
+
+func (t *table) addRoute(controllers map[string]*controller, r *router, route Route) []error {
+	if IsEmpty(route.Name) {
+		return []error{newFieldError("name", errors.New("invalid argument: route name is empty"))}
+	}
+	if _, ok := controllers[route.Name]; ok {
+		return []error{newFieldError("name", errors.New(fmt.Sprintf("invalid argument: route name is a duplicate [%v]", route.Name)))}
+	}
+	ctrl, errs := t.newValidController(route)
+	if len(errs) > 0 {
+		return errs
+	}
+	if route.Pattern != "" {
+		p, err := newRoutePattern(route.Name, route.Pattern)
+		if err != nil {
+			return []error{newFieldError("pattern", err)}
+		}
+		err = r.add(p)
+		if err != nil {
+			return []error{newFieldError("pattern", err)}
+		}
+	}
+	controllers[route.Name] = ctrl
+	return nil
+}
+
+// retainState - keep live limiter tokens and circuit state for unchanged configurations
+func retainState(curr, ctrl *controller) {
+	if curr == nil || ctrl == nil {
+		return
+	}
+	if curr.rateLimiter != nil && ctrl.rateLimiter != nil && curr.rateLimiter.config == ctrl.rateLimiter.config {
+		ctrl.rateLimiter.rateLimiter = curr.rateLimiter.rateLimiter
+		ctrl.rateLimiter.keys = curr.rateLimiter.keys
+		ctrl.rateLimiter.statuses = curr.rateLimiter.statuses
+	}
+	if curr.retry != nil && ctrl.retry != nil && curr.retry.config.Limit == ctrl.retry.config.Limit && curr.retry.config.Burst == ctrl.retry.config.Burst {
+		ctrl.retry.rateLimiter = curr.retry.rateLimiter
+	}
+	if curr.retry != nil && ctrl.retry != nil {
+		ctrl.retry.budget = curr.retry.budget
+	}
+	if curr.circuitBreaker != nil && ctrl.circuitBreaker != nil && curr.circuitBreaker.config == ctrl.circuitBreaker.config {
+		ctrl.circuitBreaker.state = curr.circuitBreaker.state
+	}
+	// In-flight requests will release against the current state, so it is always kept
+	if curr.concurrency != nil && ctrl.concurrency != nil {
+		ctrl.concurrency.state = curr.concurrency.state
+		ctrl.concurrency.state.mu.Lock()
+		ctrl.concurrency.state.limit = ctrl.concurrency.clamp(ctrl.concurrency.state.limit)
+		ctrl.concurrency.state.mu.Unlock()
+	}
+	if curr.bulkhead != nil && ctrl.bulkhead != nil {
+		ctrl.bulkhead.state = curr.bulkhead.state
+		ctrl.bulkhead.state.mu.Lock()
+		ctrl.bulkhead.state.resize(ctrl.bulkhead.config.MaxConcurrent, ctrl.bulkhead.config.MaxQueue)
+		ctrl.bulkhead.state.mu.Unlock()
+	}
+	if curr.proxy != nil && ctrl.proxy != nil && equalEndpoints(curr.proxy.endpoints, ctrl.proxy.endpoints) {
+		ctrl.proxy.balancer = curr.proxy.balancer
+	}
+	if curr.mirror != nil && ctrl.mirror != nil {
+		ctrl.mirror.state = curr.mirror.state
+	}
+	if curr.hedge != nil && ctrl.hedge != nil {
+		ctrl.hedge.state = curr.hedge.state
+	}
+	if curr.failover != nil && ctrl.failover != nil {
+		ctrl.failover.state = curr.failover.state
+		ctrl.failover.enabled = curr.failover.enabled
+	}
+}
+
+func equalEndpoints(a, b []Endpoint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// WatchRoutes - poll a route configuration file, and reload the table when the file contents change. The returned
+// function stops the watch, and returns after any reload in progress has completed.
+func WatchRoutes(t Table, path string, interval time.Duration, errFn func(errs []error)) (stop func(), err error) {
+	if t == nil {
+		return nil, errors.New("invalid argument: table is nil")
+	}
+	if interval <= 0 {
+		return nil, errors.New("invalid argument: interval is <= 0")
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				buf2, err1 := os.ReadFile(path)
+				if err1 != nil {
+					notify(errFn, []error{err1})
+					continue
+				}
+				if bytes.Equal(buf, buf2) {
+					continue
+				}
+				buf = buf2
+				notify(errFn, t.Reload(buf))
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}, nil
+}
+
+func notify(errFn func(errs []error), errs []error) {
+	if errFn != nil && len(errs) > 0 {
+		errFn(errs)
+	}
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	reloadRoutes = `[
+	{"Name":"google-search","Pattern":"google.com/search","RateLimiter":{"Limit":1,"Burst":1,"StatusCode":429}},
+	{"Name":"twitter","Pattern":"twitter.com","Timeout":{"Duration":"500ms"}},
+	{"Name":"default-egress","Timeout":{"Duration":"5s"}}
+]`
+	reloadRoutes2 = `[
+	{"Name":"google-search","Pattern":"google.com/search","RateLimiter":{"Limit":1,"Burst":1,"StatusCode":429},"Timeout":{"Duration":"1s"}},
+	{"Name":"facebook","Pattern":"facebook.com","RateLimiter":{"Limit":100,"Burst":10}}
+]`
+	reloadRoutesInvalid = `[
+	{"Name":"google-search","Pattern":"google.com/search","Timeout":{"Duration":"0s"}},
+	{"Name":"twitter","Pattern":"twitter.com"},
+	{"Name":"twitter-all","Pattern":"twitter.com/"}
+]`
+)
+
+func ExampleTable_Reload() {
+	t := newTable(true, false)
+	errs := t.Reload([]byte(reloadRoutes))
+	fmt.Printf("test: Reload() -> [errs:%v] [count:%v] [default-timeout:%v]\n", errs, t.count(), t.Default().t().timeout.Duration())
+
+	req, _ := http.NewRequest("GET", "https://google.com/search?q=test", nil)
+	ctrl := t.LookupHttp(req)
+	rlc, _ := ctrl.RateLimiter()
+	fmt.Printf("test: LookupHttp(google.com/search) -> [controller:%v] [allow:%v] [allow:%v]\n", ctrl.Name(), rlc.Allow(), rlc.Allow())
+
+	errs = t.Reload([]byte(reloadRoutes2))
+	ctrl = t.LookupHttp(req)
+	rlc, _ = ctrl.RateLimiter()
+	fmt.Printf("test: Reload() -> [errs:%v] [count:%v] [twitter:%v] [facebook:%v]\n", errs, t.count(), t.exists("twitter"), t.exists("facebook"))
+	fmt.Printf("test: LookupHttp(google.com/search) -> [controller:%v] [timeout:%v] [allow:%v]\n", ctrl.Name(), ctrl.t().timeout.Duration(), rlc.Allow())
+
+	req, _ = http.NewRequest("GET", "https://twitter.com/home", nil)
+	fmt.Printf("test: LookupHttp(twitter.com/home) -> [controller:%v]\n", t.LookupHttp(req).Name())
+
+	errs = t.Reload([]byte(reloadRoutesInvalid))
+	fmt.Printf("test: Reload(invalid) -> [errs:%v] [count:%v] [facebook:%v]\n", errs, t.count(), t.exists("facebook"))
+
+	//Output:
+	//test: Reload() -> [errs:[]] [count:2] [default-timeout:5s]
+	//test: LookupHttp(google.com/search) -> [controller:google-search] [allow:true] [allow:false]
+	//test: Reload() -> [errs:[]] [count:2] [twitter:false] [facebook:true]
+	//test: LookupHttp(google.com/search) -> [controller:google-search] [timeout:1s] [allow:false]
+	//test: LookupHttp(twitter.com/home) -> [controller:default-egress]
+	//test: Reload(invalid) -> [errs:[line 2, column 67: routes[0].timeout.duration: invalid configuration: Timeout duration is <= 0 line 4, column 24: routes[2].pattern: invalid configuration: route pattern is ambiguous [twitter] [twitter.com] and [twitter-all] [twitter.com/]]] [count:2] [facebook:true]
+
+}
+
+func ExampleWatchRoutes() {
+	dir, _ := os.MkdirTemp("", "routes")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "routes.json")
+	os.WriteFile(path, []byte(reloadRoutes), 0644)
+
+	t := newTable(true, false)
+	t.Reload([]byte(reloadRoutes))
+	stop, err := WatchRoutes(t, path, time.Millisecond*10, func(errs []error) { fmt.Printf("test: WatchRoutes() -> [errs:%v]\n", errs) })
+	fmt.Printf("test: WatchRoutes() -> [err:%v] [facebook:%v]\n", err, t.exists("facebook"))
+
+	writeFile(path, reloadRoutes2)
+	time.Sleep(time.Millisecond * 100)
+	fmt.Printf("test: WriteFile() -> [facebook:%v]\n", t.exists("facebook"))
+
+	writeFile(path, "[{\"Name\":\"\"}]")
+	time.Sleep(time.Millisecond * 100)
+	stop()
+	stop()
+
+	_, err = WatchRoutes(t, filepath.Join(dir, "missing.json"), time.Second, nil)
+	fmt.Printf("test: WatchRoutes(missing) -> [err:%v]\n", err != nil)
+
+	//Output:
+	//test: WatchRoutes() -> [err:<nil>] [facebook:false]
+	//test: WriteFile() -> [facebook:true]
+	//test: WatchRoutes() -> [errs:[line 1, column 3: routes[0].name: invalid argument: route name is empty]]
+	//test: WatchRoutes(missing) -> [err:true]
+
+}
+
+// writeFile - replace the file contents atomically, so the watcher never reads a partial write
+func writeFile(path, s string) {
+	os.WriteFile(path+".tmp", []byte(s), 0644)
+	os.Rename(path+".tmp", path)
+}
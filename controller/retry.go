@@ -1,15 +1,32 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"golang.org/x/time/rate"
+	"math"
 	"math/rand"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	ConstantBackoff     = "constant"
+	ExponentialBackoff  = "exponential"
+	DecorrelatedBackoff = "decorrelated"
+	DefaultMaxAttempts  = 2
+	DefaultMaxBodySize  = 64 * 1024
+	DefaultMaxWait      = time.Minute
+
+	RetryAfterHeaderName     = "Retry-After"
+	IdempotencyKeyHeaderName = "Idempotency-Key"
+	budgetBuckets            = 10
+)
+
 // https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
 // https://github.com/keikoproj/inverse-exp-backoff
 
@@ -19,18 +36,35 @@ type Retry interface {
 	Enable()
 	Disable()
 	IsRetryable(statusCode int) (ok bool, status string)
+	IsRetryableRequest(method, idempotencyKey string, statusCode int) (ok bool, status string)
+	IsRetryableResponse(attempt int, prev time.Duration, req *http.Request, resp *http.Response, err error) (ok bool, wait time.Duration, status string)
+	IsRetryableMethod(method, idempotencyKey string) bool
+	MaxAttempts() int
+	MaxBodySize() int64
 	SetRateLimiter(limit rate.Limit, burst int)
 	AdjustRateLimiter(percentage int) bool
 	LimitAndBurst() (rate.Limit, int)
 }
 
 type RetryConfig struct {
-	Limit rate.Limit
-	Burst int
-	Wait  time.Duration
-	Codes []int
+	Limit           rate.Limit
+	Burst           int
+	Wait            time.Duration
+	Codes           []int
+	MaxAttempts     int           // Total attempts, including the original request
+	Backoff         string        // constant, exponential, or decorrelated
+	MaxWait         time.Duration // Cap on the backoff wait, and on an honoured Retry-After, 0 is DefaultMaxWait
+	TransportErrors bool          // Retry on transport errors
+	RetryAfter      bool          // Honour the Retry-After response header
+	Budget          float64       // Retries as a percentage of recent requests, 0 is unlimited
+	MaxBodySize     int64         // Largest request body buffered for replay, 0 is DefaultMaxBodySize
+	Methods         []string      // Methods that can be retried, empty is the idempotent methods
+	IdempotencyKey  bool          // Retry any method when the request has an Idempotency-Key header
 }
 
+// idempotentMethods - https://www.rfc-editor.org/rfc/rfc9110#section-9.2.2
+var idempotentMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete}
+
 func NewRetryConfig(validCodes []int, limit rate.Limit, burst int, wait time.Duration) *RetryConfig {
 	c := new(RetryConfig)
 	c.Wait = wait
@@ -47,6 +81,7 @@ type retry struct {
 	rand        *rand.Rand
 	config      RetryConfig
 	rateLimiter *rate.Limiter
+	budget      *retryBudget
 }
 
 func cloneRetry(curr *retry) *retry {
@@ -65,18 +100,38 @@ func newRetry(name string, table *table, config *RetryConfig) *retry {
 		t.config = *config
 	}
 	t.rateLimiter = rate.NewLimiter(t.config.Limit, t.config.Burst)
+	t.budget = new(retryBudget)
 	return t
 }
 
 func (r *retry) validate() error {
-	if len(r.config.Codes) == 0 {
-		return errors.New("invalid configuration: Retry status codes are empty")
+	if len(r.config.Codes) == 0 && !r.config.TransportErrors {
+		return newFieldError("codes", errors.New("invalid configuration: Retry status codes are empty"))
+	}
+	if r.config.MaxAttempts < 0 {
+		return newFieldError("maxAttempts", errors.New("invalid configuration: Retry max attempts is < 0"))
+	}
+	switch r.config.Backoff {
+	case "", ConstantBackoff, ExponentialBackoff, DecorrelatedBackoff:
+	default:
+		return newFieldError("backoff", errors.New(fmt.Sprintf("invalid configuration: Retry backoff is invalid [%v]", r.config.Backoff)))
+	}
+	if r.config.Budget < 0 || r.config.Budget > 100 {
+		return newFieldError("budget", errors.New("invalid configuration: Retry budget is not between 0 and 100"))
+	}
+	for i, m := range r.config.Methods {
+		if !isMethod(m) {
+			return newFieldError(fmt.Sprintf("methods[%v]", i), errors.New(fmt.Sprintf("invalid configuration: Retry method is invalid [%v]", m)))
+		}
+	}
+	if r.config.MaxBodySize < 0 {
+		return newFieldError("maxBodySize", errors.New("invalid configuration: Retry max body size is < 0"))
 	}
 	if r.config.Limit < 0 {
-		return errors.New("invalid configuration: Retry limit is < 0")
+		return newFieldError("limit", errors.New("invalid configuration: Retry limit is < 0"))
 	}
 	if r.config.Burst < 0 {
-		return errors.New("invalid configuration: Retry burst is < 0")
+		return newFieldError("burst", errors.New("invalid configuration: Retry burst is < 0"))
 	}
 	return nil
 }
@@ -103,6 +158,34 @@ func retryState(m map[string]string, r *retry, retried bool) map[string]string {
 
 }
 
+// retrySkipState - the reason a retryable response was not retried
+func retrySkipState(m map[string]string, r *retry, status string) {
+	reason := ""
+	if r != nil {
+		switch status {
+		case NotEnabledFlag:
+			reason = RetrySkipNotEnabled
+		case NotIdempotentFlag:
+			reason = RetrySkipNotIdempotent
+		case NotReplayableFlag:
+			reason = RetrySkipNotReplayable
+		case RateLimitFlag:
+			reason = RetrySkipRateLimited
+		case RetryBudgetFlag:
+			reason = RetrySkipBudget
+		}
+	}
+	m[RetrySkipName] = reason
+}
+
+func retryAttemptState(m map[string]string, r *retry, attempt int) {
+	if r == nil {
+		m[RetryAttemptName] = ""
+	} else {
+		m[RetryAttemptName] = strconv.Itoa(attempt)
+	}
+}
+
 func (r *retry) IsEnabled() bool { return r.enabled }
 
 func (r *retry) Disable() {
@@ -126,24 +209,198 @@ func (r *retry) SetRateLimiter(limit rate.Limit, burst int) {
 	r.table.setRetryRateLimit(r.name, limit, burst)
 }
 
+func (r *retry) MaxAttempts() int {
+	if r.config.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+	return r.config.MaxAttempts
+}
+
+func (r *retry) MaxBodySize() int64 {
+	if r.config.MaxBodySize <= 0 {
+		return DefaultMaxBodySize
+	}
+	return r.config.MaxBodySize
+}
+
+// IsRetryable - determine if the original request can be retried, waiting for the backoff if it can. The method
+// policy is applied to a GET request.
+//
+// Deprecated: use IsRetryableRequest, so the method policy is applied to the request method.
 func (r *retry) IsRetryable(statusCode int) (bool, string) {
+	return r.IsRetryableRequest("", "", statusCode)
+}
+
+// IsRetryableRequest - determine if the original request, with the given method and optional idempotency key, can
+// be retried, waiting for the backoff if it can
+func (r *retry) IsRetryableRequest(method, idempotencyKey string, statusCode int) (bool, string) {
+	denied := ""
+	if !r.IsRetryableMethod(method, idempotencyKey) {
+		denied = NotIdempotentFlag
+	}
+	return r.sleepIfRetryable(denied, statusCode)
+}
+
+func (r *retry) sleepIfRetryable(denied string, statusCode int) (bool, string) {
+	ok, wait, status := r.isRetryable(1, 0, denied, statusCode, nil, nil)
+	if ok {
+		time.Sleep(wait)
+	}
+	return ok, status
+}
+
+// IsRetryableResponse - determine if a completed attempt can be retried, and how long to wait before retrying. The
+// previous wait is used by the decorrelated backoff, and is 0 for the first retry
+func (r *retry) IsRetryableResponse(attempt int, prev time.Duration, req *http.Request, resp *http.Response, err error) (bool, time.Duration, string) {
+	statusCode := 0
+	var header http.Header
+	if resp != nil {
+		statusCode = resp.StatusCode
+		header = resp.Header
+	}
+	denied := ""
+	if req != nil && !r.IsRetryableMethod(req.Method, req.Header.Get(IdempotencyKeyHeaderName)) {
+		denied = NotIdempotentFlag
+	}
+	return r.isRetryable(attempt, prev, denied, statusCode, header, err)
+}
+
+// IsRetryableMethod - determine if the method policy allows a retry
+func (r *retry) IsRetryableMethod(method, idempotencyKey string) bool {
+	if r.config.IdempotencyKey && idempotencyKey != "" {
+		return true
+	}
+	if method == "" {
+		method = http.MethodGet
+	}
+	if len(r.config.Methods) == 0 {
+		return isIdempotentMethod(method)
+	}
+	return containsMethod(r.config.Methods, method)
+}
+
+// isIdempotentMethod - determine if the method is idempotent, an empty method is a GET
+func isIdempotentMethod(method string) bool {
+	if method == "" {
+		method = http.MethodGet
+	}
+	return containsMethod(idempotentMethods, method)
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRetryable - a non-empty denied status is returned for retryable responses that the method policy does not allow
+func (r *retry) isRetryable(attempt int, prev time.Duration, denied string, statusCode int, header http.Header, err error) (bool, time.Duration, string) {
+	if attempt <= 1 {
+		r.budget.request()
+	}
 	if !r.IsEnabled() {
-		return false, NotEnabledFlag
+		return false, 0, NotEnabledFlag
+	}
+	if attempt >= r.MaxAttempts() || !r.isRetryableCause(statusCode, err) {
+		return false, 0, ""
+	}
+	if denied != "" {
+		return false, 0, denied
 	}
-	if statusCode < http.StatusInternalServerError {
-		return false, ""
+	wait := r.backoff(attempt, prev)
+	if r.config.RetryAfter && err == nil {
+		if after, ok := parseRetryAfter(header); ok {
+			if after > r.maxWait() {
+				return false, 0, ""
+			}
+			if after > wait {
+				wait = after
+			}
+		}
+	}
+	// The budget is checked first, so that a retry denied by the budget does not take a rate limiter token
+	if !r.budget.allow(r.config.Budget) {
+		return false, 0, RetryBudgetFlag
 	}
 	if !r.rateLimiter.Allow() {
-		return false, RateLimitFlag
+		return false, 0, RateLimitFlag
+	}
+	return true, wait, ""
+}
+
+func (r *retry) isRetryableCause(statusCode int, err error) bool {
+	if err != nil {
+		return r.config.TransportErrors && !errors.Is(err, context.Canceled)
 	}
 	for _, code := range r.config.Codes {
 		if code == statusCode {
-			jitter := time.Duration(r.rand.Int31n(1000))
-			time.Sleep(r.config.Wait + jitter)
-			return true, ""
+			return true
 		}
 	}
-	return false, ""
+	return false
+}
+
+// maxWait - the cap on the backoff wait and on an honoured Retry-After
+func (r *retry) maxWait() time.Duration {
+	if r.config.MaxWait <= 0 {
+		return DefaultMaxWait
+	}
+	return r.config.MaxWait
+}
+
+// backoff - wait before the next attempt, https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/.
+// The wait is computed as a float and capped before the conversion, so that a large attempt does not overflow
+func (r *retry) backoff(attempt int, prev time.Duration) time.Duration {
+	max := float64(r.maxWait())
+	var wait float64
+	switch r.config.Backoff {
+	case ExponentialBackoff:
+		wait = float64(r.config.Wait) * math.Pow(2, float64(attempt-1))
+	case DecorrelatedBackoff:
+		// sleep = min(cap, random_between(base, sleep * 3))
+		if prev < r.config.Wait {
+			prev = r.config.Wait
+		}
+		upper := math.Min(float64(prev)*3, max)
+		wait = float64(r.config.Wait)
+		if upper > wait {
+			wait += float64(r.rand.Int63n(int64(upper - wait)))
+		}
+	default:
+		wait = float64(r.config.Wait + time.Duration(r.rand.Int31n(1000)))
+	}
+	if wait > max {
+		wait = max
+	}
+	return time.Duration(wait)
+}
+
+func parseRetryAfter(h http.Header) (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+	s := h.Get(RetryAfterHeaderName)
+	if s == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(s); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(s)
+	if err != nil {
+		return 0, false
+	}
+	d := time.Until(t)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
 }
 
 func (r *retry) AdjustRateLimiter(percentage int) bool {
@@ -162,3 +419,52 @@ func (r *retry) AdjustRateLimiter(percentage int) bool {
 func (r *retry) LimitAndBurst() (rate.Limit, int) {
 	return r.config.Limit, r.config.Burst
 }
+
+// retryBudget - request and retry counts over a sliding window of one second buckets
+type retryBudget struct {
+	mu      sync.Mutex
+	buckets [budgetBuckets]budgetBucket
+}
+
+type budgetBucket struct {
+	second   int64
+	requests int
+	retries  int
+}
+
+func (b *retryBudget) current() *budgetBucket {
+	now := time.Now().Unix()
+	bucket := &b.buckets[now%budgetBuckets]
+	if bucket.second != now {
+		*bucket = budgetBucket{second: now}
+	}
+	return bucket
+}
+
+func (b *retryBudget) request() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current().requests++
+}
+
+func (b *retryBudget) allow(percentage float64) bool {
+	if percentage <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	bucket := b.current()
+	requests, retries := 0, 0
+	oldest := time.Now().Unix() - budgetBuckets
+	for _, bb := range b.buckets {
+		if bb.second > oldest {
+			requests += bb.requests
+			retries += bb.retries
+		}
+	}
+	if float64(retries+1) > math.Max(1, float64(requests)*percentage/100) {
+		return false
+	}
+	bucket.retries++
+	return true
+}
@@ -1,8 +1,12 @@
 package controller
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"golang.org/x/time/rate"
+	"net/http"
+	"time"
 )
 
 func Example_newRetry() {
@@ -26,8 +30,8 @@ func Example_newRetry() {
 	fmt.Printf("test: retryState(t2,true,map) -> %v\n", retryState(nil, t2, true))
 
 	//Output:
-	//test: newRetry() -> [name:test-route] [config:{5 10 0s [504]}] [limit:5] [burst:10]
-	//test: newRetry() -> [name:test-route2] [config:{2 20 0s [503 504]}]
+	//test: newRetry() -> [name:test-route] [config:{5 10 0s [504] 0  0s false false 0 0 [] false}] [limit:5] [burst:10]
+	//test: newRetry() -> [name:test-route2] [config:{2 20 0s [503 504] 0  0s false false 0 0 [] false}]
 	//test: cloneRetry() -> [prev-enabled:true] [curr-enabled:false]
 	//test: retryState(nil,false,map) -> map[retry: retryBurst:-1 retryRateLimit:-1]
 	//test: retryState(t,false,map) -> map[retry:false retryBurst:20 retryRateLimit:2]
@@ -162,3 +166,158 @@ func Example_IsRetryable_RateLimit() {
 	//test: IsRetryable(504) -> [ok:true] [status:]
 
 }
+
+func Example_IsRetryableResponse_Attempts() {
+	get, _ := http.NewRequest(http.MethodGet, "https://www.google.com", nil)
+	config := NewRetryConfig([]int{503}, 100, 10, 0)
+	config.MaxAttempts = 3
+	config.TransportErrors = true
+	r := newRetry("test-route", newTable(true, false), config)
+	resp := &http.Response{StatusCode: 503}
+
+	ok, _, status := r.IsRetryableResponse(1, 0, get, resp, nil)
+	fmt.Printf("test: IsRetryableResponse(1,503) -> [ok:%v] [status:%v]\n", ok, status)
+
+	ok, _, status = r.IsRetryableResponse(2, 0, get, nil, errors.New("connection reset"))
+	fmt.Printf("test: IsRetryableResponse(2,error) -> [ok:%v] [status:%v]\n", ok, status)
+
+	ok, _, status = r.IsRetryableResponse(2, 0, get, nil, context.Canceled)
+	fmt.Printf("test: IsRetryableResponse(2,canceled) -> [ok:%v] [status:%v]\n", ok, status)
+
+	ok, _, status = r.IsRetryableResponse(3, 0, get, resp, nil)
+	fmt.Printf("test: IsRetryableResponse(3,503) -> [ok:%v] [status:%v]\n", ok, status)
+
+	//Output:
+	//test: IsRetryableResponse(1,503) -> [ok:true] [status:]
+	//test: IsRetryableResponse(2,error) -> [ok:true] [status:]
+	//test: IsRetryableResponse(2,canceled) -> [ok:false] [status:]
+	//test: IsRetryableResponse(3,503) -> [ok:false] [status:]
+
+}
+
+func Example_backoff() {
+	config := NewRetryConfig([]int{503}, 100, 10, time.Millisecond*100)
+	config.Backoff = ExponentialBackoff
+	config.MaxWait = time.Millisecond * 300
+	r := newRetry("test-route", newTable(true, false), config)
+	fmt.Printf("test: backoff(exponential) -> [1:%v] [2:%v] [3:%v]\n", r.backoff(1, 0), r.backoff(2, 0), r.backoff(3, 0))
+
+	r.config.Backoff = DecorrelatedBackoff
+	ok := true
+	var wait time.Duration
+	for i := 1; i < 10; i++ {
+		prev := wait
+		if wait = r.backoff(i, prev); wait < r.config.Wait || wait > r.config.MaxWait || (prev > 0 && wait > prev*3) {
+			ok = false
+		}
+	}
+	fmt.Printf("test: backoff(decorrelated) -> [in-range:%v]\n", ok)
+
+	// Without a MaxWait the wait is capped at DefaultMaxWait, and a large attempt does not overflow
+	r.config.Backoff = ExponentialBackoff
+	r.config.MaxWait = 0
+	fmt.Printf("test: backoff(exponential,attempt:100) -> [%v]\n", r.backoff(100, 0))
+
+	//Output:
+	//test: backoff(exponential) -> [1:100ms] [2:200ms] [3:300ms]
+	//test: backoff(decorrelated) -> [in-range:true]
+	//test: backoff(exponential,attempt:100) -> [1m0s]
+
+}
+
+func Example_IsRetryableResponse_RetryAfter() {
+	get, _ := http.NewRequest(http.MethodGet, "https://www.google.com", nil)
+	config := NewRetryConfig([]int{503}, 100, 10, 0)
+	config.MaxAttempts = 5
+	config.RetryAfter = true
+	config.MaxWait = time.Second * 5
+	r := newRetry("test-route", newTable(true, false), config)
+	resp := &http.Response{StatusCode: 503, Header: make(http.Header)}
+
+	resp.Header.Set(RetryAfterHeaderName, "2")
+	ok, wait, _ := r.IsRetryableResponse(1, 0, get, resp, nil)
+	fmt.Printf("test: IsRetryableResponse(Retry-After:2) -> [ok:%v] [wait:%v]\n", ok, wait)
+
+	resp.Header.Set(RetryAfterHeaderName, "10")
+	ok, wait, _ = r.IsRetryableResponse(2, 0, get, resp, nil)
+	fmt.Printf("test: IsRetryableResponse(Retry-After:10) -> [ok:%v] [wait:%v]\n", ok, wait)
+
+	// Without a MaxWait, Retry-After is capped at DefaultMaxWait
+	r.config.MaxWait = 0
+	resp.Header.Set(RetryAfterHeaderName, "3600")
+	ok, wait, _ = r.IsRetryableResponse(3, 0, get, resp, nil)
+	fmt.Printf("test: IsRetryableResponse(Retry-After:3600) -> [ok:%v] [wait:%v]\n", ok, wait)
+
+	//Output:
+	//test: IsRetryableResponse(Retry-After:2) -> [ok:true] [wait:2s]
+	//test: IsRetryableResponse(Retry-After:10) -> [ok:false] [wait:0s]
+	//test: IsRetryableResponse(Retry-After:3600) -> [ok:false] [wait:0s]
+
+}
+
+func Example_IsRetryableResponse_Budget() {
+	get, _ := http.NewRequest(http.MethodGet, "https://www.google.com", nil)
+	config := NewRetryConfig([]int{503}, 100, 100, 0)
+	config.Budget = 20
+	r := newRetry("test-route", newTable(true, false), config)
+	resp := &http.Response{StatusCode: 503}
+
+	retries := 0
+	status := ""
+	for i := 0; i < 10; i++ {
+		ok, _, s := r.IsRetryableResponse(1, 0, get, resp, nil)
+		if ok {
+			retries++
+		} else {
+			status = s
+		}
+	}
+	fmt.Printf("test: IsRetryableResponse(budget:20%%) -> [requests:10] [retries:%v] [status:%v]\n", retries, status)
+
+	// A retry denied by the budget does not take a rate limiter token
+	fmt.Printf("test: rateLimiter.Tokens() -> [%v]\n", int(r.rateLimiter.Tokens()))
+
+	//Output:
+	//test: IsRetryableResponse(budget:20%) -> [requests:10] [retries:2] [status:RB]
+	//test: rateLimiter.Tokens() -> [98]
+
+}
+
+func Example_IsRetryableResponse_Method() {
+	config := NewRetryConfig([]int{503}, 100, 10, 0)
+	r := newRetry("test-route", newTable(true, false), config)
+	resp := &http.Response{StatusCode: 503}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://www.google.com", nil)
+	ok, _, status := r.IsRetryableResponse(1, 0, req, resp, nil)
+	fmt.Printf("test: IsRetryableResponse(POST) -> [ok:%v] [status:%v]\n", ok, status)
+
+	req.Header.Set(IdempotencyKeyHeaderName, "123-456")
+	ok, _, status = r.IsRetryableResponse(1, 0, req, resp, nil)
+	fmt.Printf("test: IsRetryableResponse(POST,Idempotency-Key) -> [ok:%v] [status:%v]\n", ok, status)
+
+	r.config.IdempotencyKey = true
+	ok, _, status = r.IsRetryableResponse(1, 0, req, resp, nil)
+	fmt.Printf("test: IsRetryableResponse(POST,Idempotency-Key,enabled) -> [ok:%v] [status:%v]\n", ok, status)
+
+	r.config.Methods = []string{http.MethodGet}
+	ok, status = r.IsRetryableRequest(http.MethodPut, "", 503)
+	fmt.Printf("test: IsRetryableRequest(PUT,allow-list:GET) -> [ok:%v] [status:%v]\n", ok, status)
+
+	m := make(map[string]string)
+	retrySkipState(m, r, status)
+	fmt.Printf("test: retrySkipState(NI) -> %v\n", m)
+
+	r.config.Methods = []string{http.MethodPost}
+	ok, status = r.IsRetryable(503)
+	fmt.Printf("test: IsRetryable(allow-list:POST) -> [ok:%v] [status:%v]\n", ok, status)
+
+	//Output:
+	//test: IsRetryableResponse(POST) -> [ok:false] [status:NI]
+	//test: IsRetryableResponse(POST,Idempotency-Key) -> [ok:false] [status:NI]
+	//test: IsRetryableResponse(POST,Idempotency-Key,enabled) -> [ok:true] [status:]
+	//test: IsRetryableRequest(PUT,allow-list:GET) -> [ok:false] [status:NI]
+	//test: retrySkipState(NI) -> map[retrySkip:not-idempotent]
+	//test: IsRetryable(allow-list:POST) -> [ok:false] [status:NI]
+
+}
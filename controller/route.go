@@ -9,16 +9,22 @@ import (
 
 // Route - route data
 type Route struct {
-	Name        string
-	Pattern     string
-	Traffic     string // egress/ingress
-	Ping        bool   // health traffic
-	Protocol    string // gRPC, HTTP10, HTTP11, HTTP2, HTTP3gRPC, HTTP
-	Timeout     *TimeoutConfig
-	RateLimiter *RateLimiterConfig
-	Retry       *RetryConfig
-	Failover    *FailoverConfig
-	Proxy       *ProxyConfig
+	Name               string
+	Pattern            string
+	Traffic            string // egress/ingress
+	Ping               bool   // health traffic
+	Protocol           string // gRPC, HTTP10, HTTP11, HTTP2, HTTP3gRPC, HTTP
+	Timeout            *TimeoutConfig
+	RateLimiter        *RateLimiterConfig
+	Retry              *RetryConfig
+	Failover           *FailoverConfig
+	Proxy              *ProxyConfig
+	CircuitBreaker     *CircuitBreakerConfig
+	ConcurrencyLimiter *ConcurrencyLimiterConfig
+	Bulkhead           *BulkheadConfig
+	Hedge              *HedgeConfig
+	Mirror             *MirrorConfig
+	Priority           *PriorityConfig
 }
 
 type TimeoutConfigJson struct {
@@ -26,24 +32,114 @@ type TimeoutConfigJson struct {
 	StatusCode int
 }
 
+type RateLimiterConfigJson struct {
+	Limit       rate.Limit
+	Burst       int
+	StatusCode  int
+	Key         string
+	MaxKeys     int
+	IdleTimeout string
+	Global      bool
+	Headers     bool
+	Body        string
+}
+
 type RetryConfigJson struct {
-	Limit rate.Limit
-	Burst int
-	Wait  string
-	Codes []int
+	Limit           rate.Limit
+	Burst           int
+	Wait            string
+	Codes           []int
+	MaxAttempts     int
+	Backoff         string
+	MaxWait         string
+	TransportErrors bool
+	RetryAfter      bool
+	Budget          float64
+	MaxBodySize     int64
+	Methods         []string
+	IdempotencyKey  bool
+}
+
+type CircuitBreakerConfigJson struct {
+	ErrorRatio          float64
+	MinRequests         int
+	ConsecutiveFailures int
+	CoolDown            string
+	StatusCode          int
+}
+
+type ConcurrencyLimiterConfigJson struct {
+	Algorithm    string
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	Backoff      float64
+	Timeout      string
+	Smoothing    float64
+	Tolerance    float64
+	StatusCode   int
+}
+
+type BulkheadConfigJson struct {
+	MaxConcurrent int
+	MaxQueue      int
+	QueueTimeout  string
+	StatusCode    int
+}
+
+type HedgeConfigJson struct {
+	Delay      string
+	Percentile float64
+	Budget     float64
+}
+
+type MirrorConfigJson struct {
+	Pattern       string
+	Percentage    float64
+	Timeout       string
+	MaxBodySize   int64
+	MaxConcurrent int
+}
+
+type FailoverConfigJson struct {
+	Enabled      bool
+	ErrorRatio   float64
+	TimeoutRatio float64
+	MinRequests  int
+	Window       string
+}
+
+type ProxyConfigJson struct {
+	Enabled      bool
+	Pattern      string
+	Endpoints    []Endpoint
+	Strategy     string
+	HashHeader   string
+	MaxFailures  int
+	EjectionTime string
+
+	CanaryPattern    string
+	CanaryPercentage int
+	CanaryHeader     string
 }
 
 type RouteConfig struct {
-	Name        string
-	Pattern     string
-	Traffic     string // Egress/Ingress
-	Ping        bool   // Health traffic
-	Protocol    string // gRPC, HTTP10, HTTP11, HTTP2, HTTP3gRPC, HTTP
-	Timeout     *TimeoutConfigJson
-	RateLimiter *RateLimiterConfig
-	Retry       *RetryConfigJson
-	Failover    *FailoverConfig
-	Proxy       *ProxyConfig
+	Name               string
+	Pattern            string
+	Traffic            string // Egress/Ingress
+	Ping               bool   // Health traffic
+	Protocol           string // gRPC, HTTP10, HTTP11, HTTP2, HTTP3gRPC, HTTP
+	Timeout            *TimeoutConfigJson
+	RateLimiter        *RateLimiterConfigJson
+	Retry              *RetryConfigJson
+	Failover           *FailoverConfigJson
+	Proxy              *ProxyConfigJson
+	CircuitBreaker     *CircuitBreakerConfigJson
+	ConcurrencyLimiter *ConcurrencyLimiterConfigJson
+	Bulkhead           *BulkheadConfigJson
+	Hedge              *HedgeConfigJson
+	Mirror             *MirrorConfigJson
+	Priority           *PriorityConfig
 }
 
 func newRoute(name string, config ...any) Route {
@@ -72,6 +168,18 @@ func NewRoute(name string, traffic, protocol string, ping bool, config ...any) R
 			route.Proxy = c
 		case *RetryConfig:
 			route.Retry = c
+		case *CircuitBreakerConfig:
+			route.CircuitBreaker = c
+		case *ConcurrencyLimiterConfig:
+			route.ConcurrencyLimiter = c
+		case *BulkheadConfig:
+			route.Bulkhead = c
+		case *HedgeConfig:
+			route.Hedge = c
+		case *MirrorConfig:
+			route.Mirror = c
+		case *PriorityConfig:
+			route.Priority = c
 		}
 	}
 	return route
@@ -79,34 +187,103 @@ func NewRoute(name string, traffic, protocol string, ping bool, config ...any) R
 
 // NewRouteFromConfig - creates a new route from configuration
 func NewRouteFromConfig(config RouteConfig) (Route, error) {
+	route, errs := newRouteFromConfig(config)
+	if len(errs) > 0 {
+		return Route{}, errs[0]
+	}
+	return route, nil
+}
+
+// newRouteFromConfig - creates a new route from configuration, with an error for each invalid duration
+func newRouteFromConfig(config RouteConfig) (Route, []error) {
+	var errs []error
+	convert := func(path, s string) time.Duration {
+		duration, err := ConvertDuration(s)
+		if err != nil {
+			errs = append(errs, newFieldError(path, err))
+		}
+		return duration
+	}
 	route := Route{}
 	route.Name = config.Name
 	route.Pattern = config.Pattern
 	route.Traffic = config.Traffic
 	route.Ping = config.Ping
 	route.Protocol = config.Protocol
-	route.Failover = config.Failover
-	route.Proxy = config.Proxy
-	route.RateLimiter = config.RateLimiter
+	if config.Failover != nil {
+		duration := convert("failover.window", config.Failover.Window)
+		route.Failover = NewAutoFailoverConfig(nil, config.Failover.ErrorRatio, config.Failover.TimeoutRatio, config.Failover.MinRequests, duration)
+		route.Failover.Enabled = config.Failover.Enabled
+	}
+	if config.Proxy != nil {
+		duration := convert("proxy.ejectionTime", config.Proxy.EjectionTime)
+		route.Proxy = &ProxyConfig{Enabled: config.Proxy.Enabled, Pattern: config.Proxy.Pattern, Endpoints: config.Proxy.Endpoints,
+			Strategy: config.Proxy.Strategy, HashHeader: config.Proxy.HashHeader, MaxFailures: config.Proxy.MaxFailures, EjectionTime: duration,
+			CanaryPattern: config.Proxy.CanaryPattern, CanaryPercentage: config.Proxy.CanaryPercentage, CanaryHeader: config.Proxy.CanaryHeader}
+	}
+	if config.RateLimiter != nil {
+		duration := convert("rateLimiter.idleTimeout", config.RateLimiter.IdleTimeout)
+		c := config.RateLimiter
+		route.RateLimiter = NewRateLimiterConfig(c.Limit, c.Burst, c.StatusCode)
+		route.RateLimiter.Key = c.Key
+		route.RateLimiter.MaxKeys = c.MaxKeys
+		route.RateLimiter.IdleTimeout = duration
+		route.RateLimiter.Global = c.Global
+		route.RateLimiter.Headers = c.Headers
+		route.RateLimiter.Body = c.Body
+	}
+	route.Priority = config.Priority
 	if config.Timeout != nil {
-		duration, err := ConvertDuration(config.Timeout.Duration)
-		if err != nil {
-			return Route{}, err
-		}
+		duration := convert("timeout.duration", config.Timeout.Duration)
 		route.Timeout = NewTimeoutConfig(duration, config.Timeout.StatusCode)
 	}
 	if config.Retry != nil {
-		duration, err := ConvertDuration(config.Retry.Wait)
-		if err != nil {
-			return Route{}, err
-		}
+		duration := convert("retry.wait", config.Retry.Wait)
+		maxWait := convert("retry.maxWait", config.Retry.MaxWait)
 		route.Retry = NewRetryConfig(config.Retry.Codes, config.Retry.Limit, config.Retry.Burst, duration)
+		route.Retry.MaxAttempts = config.Retry.MaxAttempts
+		route.Retry.Backoff = config.Retry.Backoff
+		route.Retry.MaxWait = maxWait
+		route.Retry.TransportErrors = config.Retry.TransportErrors
+		route.Retry.RetryAfter = config.Retry.RetryAfter
+		route.Retry.Budget = config.Retry.Budget
+		route.Retry.MaxBodySize = config.Retry.MaxBodySize
+		route.Retry.Methods = config.Retry.Methods
+		route.Retry.IdempotencyKey = config.Retry.IdempotencyKey
 	}
-	return route, nil
+	if config.CircuitBreaker != nil {
+		duration := convert("circuitBreaker.coolDown", config.CircuitBreaker.CoolDown)
+		route.CircuitBreaker = NewCircuitBreakerConfig(config.CircuitBreaker.ErrorRatio, config.CircuitBreaker.MinRequests, config.CircuitBreaker.ConsecutiveFailures, duration, config.CircuitBreaker.StatusCode)
+	}
+	if config.ConcurrencyLimiter != nil {
+		duration := convert("concurrencyLimiter.timeout", config.ConcurrencyLimiter.Timeout)
+		c := config.ConcurrencyLimiter
+		route.ConcurrencyLimiter = NewConcurrencyLimiterConfig(c.Algorithm, c.InitialLimit, c.MinLimit, c.MaxLimit, c.StatusCode)
+		route.ConcurrencyLimiter.Backoff = c.Backoff
+		route.ConcurrencyLimiter.Timeout = duration
+		route.ConcurrencyLimiter.Smoothing = c.Smoothing
+		route.ConcurrencyLimiter.Tolerance = c.Tolerance
+	}
+	if config.Bulkhead != nil {
+		duration := convert("bulkhead.queueTimeout", config.Bulkhead.QueueTimeout)
+		route.Bulkhead = NewBulkheadConfig(config.Bulkhead.MaxConcurrent, config.Bulkhead.MaxQueue, duration, config.Bulkhead.StatusCode)
+	}
+	if config.Hedge != nil {
+		duration := convert("hedge.delay", config.Hedge.Delay)
+		route.Hedge = NewHedgeConfig(duration, config.Hedge.Percentile, config.Hedge.Budget)
+	}
+	if config.Mirror != nil {
+		duration := convert("mirror.timeout", config.Mirror.Timeout)
+		route.Mirror = NewMirrorConfig(config.Mirror.Pattern, config.Mirror.Percentage)
+		route.Mirror.Timeout = duration
+		route.Mirror.MaxBodySize = config.Mirror.MaxBodySize
+		route.Mirror.MaxConcurrent = config.Mirror.MaxConcurrent
+	}
+	return route, errs
 }
 
 func (r Route) IsConfigured() bool {
-	return r.Retry != nil || r.Timeout != nil || r.RateLimiter != nil || r.Failover != nil || r.Proxy != nil
+	return r.Retry != nil || r.Timeout != nil || r.RateLimiter != nil || r.Failover != nil || r.Proxy != nil || r.CircuitBreaker != nil || r.ConcurrencyLimiter != nil || r.Bulkhead != nil || r.Hedge != nil || r.Mirror != nil || r.Priority != nil
 }
 
 func ConvertDuration(s string) (time.Duration, error) {
@@ -81,7 +81,7 @@ func ExampleConfig_Marshal() {
 	//fmt.Printf("test: []Route -> [error:%v] %v\n", err, string(buf))
 
 	//Output:
-	//test: Config{} -> [error:<nil>] {"Name":"test-route","Pattern":"google.com","Traffic":"ingress","Ping":true,"Protocol":"HTTP11","Timeout":{"Duration":20000,"StatusCode":504},"RateLimiter":{"Limit":100,"Burst":25,"StatusCode":503},"Retry":{"Limit":100,"Burst":33,"Wait":500,"Codes":[503,504]},"Failover":null,"Proxy":{"Enabled":false,"Pattern":"http:"}}
+	//test: Config{} -> [error:<nil>] {"Name":"test-route","Pattern":"google.com","Traffic":"ingress","Ping":true,"Protocol":"HTTP11","Timeout":{"Duration":20000,"StatusCode":504},"RateLimiter":{"Limit":100,"Burst":25,"StatusCode":503,"Key":"","MaxKeys":0,"IdleTimeout":0,"Global":false,"Headers":false,"Body":""},"Retry":{"Limit":100,"Burst":33,"Wait":500,"Codes":[503,504],"MaxAttempts":0,"Backoff":"","MaxWait":0,"TransportErrors":false,"RetryAfter":false,"Budget":0,"MaxBodySize":0,"Methods":null,"IdempotencyKey":false},"Failover":null,"Proxy":{"Enabled":false,"Pattern":"http:","Endpoints":null,"Strategy":"","HashHeader":"","MaxFailures":0,"EjectionTime":0,"CanaryPattern":"","CanaryPercentage":0,"CanaryHeader":""},"CircuitBreaker":null,"ConcurrencyLimiter":null,"Bulkhead":null,"Hedge":null,"Mirror":null,"Priority":null}
 
 }
 
@@ -162,14 +162,36 @@ func ExampleNewRouteFromConfig() {
 	route, err = NewRouteFromConfig(config)
 	fmt.Printf("test: NewRouteFromConfig() [err:%v] [timeout:%v] [retry:%v]\n", err, route.Timeout, route.Retry)
 
+	config.RateLimiter = &RateLimiterConfigJson{Limit: 100, Burst: 25, Key: "header:X-Api-Key", IdleTimeout: "5m"}
+	route, err = NewRouteFromConfig(config)
+	fmt.Printf("test: NewRouteFromConfig() [err:%v] [idleTimeout:%v]\n", err, route.RateLimiter.IdleTimeout)
+
+	config.RateLimiter.IdleTimeout = "5z"
+	route, err = NewRouteFromConfig(config)
+	fmt.Printf("test: NewRouteFromConfig() [err:%v]\n", err)
+	config.RateLimiter = nil
+
+	config.Failover = &FailoverConfigJson{ErrorRatio: 0.5, MinRequests: 10, Window: "1m"}
+	route, err = NewRouteFromConfig(config)
+	fmt.Printf("test: NewRouteFromConfig() [err:%v] [window:%v]\n", err, route.Failover.Window)
+
+	config.Failover.Window = "1x"
+	route, err = NewRouteFromConfig(config)
+	fmt.Printf("test: NewRouteFromConfig() [err:%v]\n", err)
+	config.Failover = nil
+
 	config.Timeout.Duration = "x34"
 	route, err = NewRouteFromConfig(config)
 	fmt.Printf("test: NewRouteFromConfig() [err:%v] [route:%v]\n", err, route)
 
 	//Output:
-	//test: NewRouteFromConfig() [err:strconv.Atoi: parsing "5x": invalid syntax] [route:{   false  <nil> <nil> <nil> <nil> <nil>}]
-	//test: NewRouteFromConfig() [err:<nil>] [timeout:&{500ms 5040}] [retry:&{100 25 4m5s []}]
-	//test: NewRouteFromConfig() [err:strconv.Atoi: parsing "x34": invalid syntax] [route:{   false  <nil> <nil> <nil> <nil> <nil>}]
+	//test: NewRouteFromConfig() [err:retry.wait: strconv.Atoi: parsing "5x": invalid syntax] [route:{   false  <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil>}]
+	//test: NewRouteFromConfig() [err:<nil>] [timeout:&{500ms 5040}] [retry:&{100 25 4m5s [] 0  0s false false 0 0 [] false}]
+	//test: NewRouteFromConfig() [err:<nil>] [idleTimeout:5m0s]
+	//test: NewRouteFromConfig() [err:rateLimiter.idleTimeout: strconv.Atoi: parsing "5z": invalid syntax]
+	//test: NewRouteFromConfig() [err:<nil>] [window:1m0s]
+	//test: NewRouteFromConfig() [err:failover.window: strconv.Atoi: parsing "1x": invalid syntax]
+	//test: NewRouteFromConfig() [err:timeout.duration: strconv.Atoi: parsing "x34": invalid syntax] [route:{   false  <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil> <nil>}]
 	
 }
 
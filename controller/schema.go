@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/gotemplates/host/decode"
+	"reflect"
+	"time"
+)
+
+const (
+	SchemaDraft    = "https://json-schema.org/draft/2020-12/schema"
+	SchemaFileName = "routeconfig.schema.json"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// RouteConfigSchema - a JSON Schema for a list of route configurations, generated from the RouteConfig type. Field
+// names are those written by encoding/json, and unknown fields are not allowed
+func RouteConfigSchema() ([]byte, error) {
+	defs := make(map[string]any)
+	schema := map[string]any{
+		"$schema": SchemaDraft,
+		"title":   "Routes",
+		"type":    "array",
+		"items":   typeSchema(reflect.TypeOf(RouteConfig{}), defs),
+		"$defs":   defs,
+	}
+	buf, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, '\n'), nil
+}
+
+// typeSchema - the schema of a type, structs are added to the definitions and referenced by name
+func typeSchema(t reflect.Type, defs map[string]any) map[string]any {
+	if t == durationType {
+		return map[string]any{"type": "integer", "description": "Duration in nanoseconds"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return map[string]any{"anyOf": []any{typeSchema(t.Elem(), defs), map[string]any{"type": "null"}}}
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			// The definition is added before the fields, so that a recursive type references itself
+			defs[t.Name()] = nil
+			properties := make(map[string]any)
+			structProperties(t, properties, defs)
+			defs[t.Name()] = map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": []string{"array", "null"}, "items": typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": []string{"object", "null"}, "additionalProperties": typeSchema(t.Elem(), defs)}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	}
+	return map[string]any{}
+}
+
+func structProperties(t reflect.Type, properties map[string]any, defs map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := decode.FieldName(f)
+		if !ok || f.Type.Kind() == reflect.Func || f.Type.Kind() == reflect.Chan {
+			continue
+		}
+		if name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			structProperties(ft, properties, defs)
+			continue
+		}
+		properties[name] = typeSchema(f.Type, defs)
+	}
+}
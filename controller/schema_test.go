@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+var updateSchema = flag.Bool("update", false, "update the route configuration JSON Schema file")
+
+func ExampleRouteConfigSchema() {
+	buf, err := RouteConfigSchema()
+	fmt.Printf("test: RouteConfigSchema() -> [err:%v]\n", err)
+
+	if *updateSchema {
+		os.WriteFile(SchemaFileName, buf, 0644)
+	}
+	file, err := os.ReadFile(SchemaFileName)
+	fmt.Printf("test: ReadFile(%v) -> [err:%v] [current:%v]\n", SchemaFileName, err, bytes.Equal(buf, file))
+
+	var schema struct {
+		Items map[string]string
+		Defs  map[string]struct {
+			Properties           map[string]any
+			AdditionalProperties bool
+		} `json:"$defs"`
+	}
+	json.Unmarshal(buf, &schema)
+	timeout := schema.Defs["TimeoutConfigJson"]
+	fmt.Printf("test: Schema() -> [items:%v] [timeout:%v] [duration:%v] [additional:%v]\n", schema.Items["$ref"], len(timeout.Properties), timeout.Properties["Duration"], timeout.AdditionalProperties)
+	fmt.Printf("test: Schema(RateLimiterConfigJson) -> [limit:%v] [idleTimeout:%v]\n", schema.Defs["RateLimiterConfigJson"].Properties["Limit"], schema.Defs["RateLimiterConfigJson"].Properties["IdleTimeout"])
+	fmt.Printf("test: Schema(FailoverConfigJson) -> [properties:%v] [window:%v]\n", len(schema.Defs["FailoverConfigJson"].Properties), schema.Defs["FailoverConfigJson"].Properties["Window"])
+
+	//Output:
+	//test: RouteConfigSchema() -> [err:<nil>]
+	//test: ReadFile(routeconfig.schema.json) -> [err:<nil>] [current:true]
+	//test: Schema() -> [items:#/$defs/RouteConfig] [timeout:2] [duration:map[type:string]] [additional:false]
+	//test: Schema(RateLimiterConfigJson) -> [limit:map[type:number]] [idleTimeout:map[type:string]]
+	//test: Schema(FailoverConfigJson) -> [properties:5] [window:map[type:string]]
+
+}
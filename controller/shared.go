@@ -4,16 +4,27 @@ const (
 	EgressTraffic  = "egress"
 	IngressTraffic = "ingress"
 	PingTraffic    = "ping"
+	MirrorTraffic  = "mirror"
 
-	PingName            = "ping"
-	TimeoutName         = "timeout"
-	FailoverName        = "failover"
-	ProxyName           = "proxy"
-	RetryName           = "retry"
-	RetryRateLimitName  = "retryRateLimit"
-	RetryRateBurstName  = "retryBurst"
-	RateLimitName       = "rateLimit"
-	RateBurstName       = "burst"
-	ControllerName      = "name"
-	RequestIdHeaderName = "X-REQUEST-ID"
+	PingName             = "ping"
+	TimeoutName          = "timeout"
+	FailoverName         = "failover"
+	ProxyName            = "proxy"
+	CircuitBreakerName   = "circuitBreaker"
+	RetryName            = "retry"
+	RetryRateLimitName   = "retryRateLimit"
+	RetryRateBurstName   = "retryBurst"
+	RetryAttemptName     = "retryAttempt"
+	RetrySkipName        = "retrySkip"
+	RateLimitName        = "rateLimit"
+	RateLimitKeyName     = "rateLimitKey"
+	RateBurstName        = "burst"
+	ConcurrencyLimitName = "concurrencyLimit"
+	InFlightName         = "inFlight"
+	HedgeName            = "hedge"
+	EndpointName         = "endpoint"
+	BranchName           = "branch"
+	PriorityName         = "priority"
+	ControllerName       = "name"
+	RequestIdHeaderName  = "X-REQUEST-ID"
 )
@@ -13,6 +13,7 @@ type table struct {
 	mu           sync.RWMutex
 	httpMatch    HttpMatcher
 	uriMatch     UriMatcher
+	router       *router
 	hostCtrl     *controller
 	defaultCtrl  *controller
 	nilCtrl      *controller
@@ -33,12 +34,9 @@ func newTable(egress, allowDefault bool) *table {
 	t := new(table)
 	t.egress = egress
 	t.allowDefault = allowDefault
-	t.httpMatch = func(req *http.Request) (name string, ok bool) {
-		return "", true
-	}
-	t.uriMatch = func(urn string, method string) (name string, ok bool) {
-		return "", true
-	}
+	t.httpMatch = t.matchHttp
+	t.uriMatch = t.matchUri
+	t.router = newRouter()
 	t.controllers = make(map[string]*controller, 100)
 	t.hostCtrl = newDefaultController(HostControllerName)
 	t.defaultCtrl = newDefaultController(DefaultControllerName)
@@ -67,46 +65,54 @@ func (t *table) SetUriMatcher(fn UriMatcher) {
 }
 
 func (t *table) SetHostController(route Route) []error {
-	if t.isEgress() {
-		return []error{errors.New("host controller configuration is not valid for egress traffic")}
-	}
-	if !t.isEgress() && (route.Retry != nil || route.Timeout != nil || route.Failover != nil) {
-		return []error{errors.New("host controller configuration does not allow retry, rate limiter, or failover controllers")}
-	}
-	t.mu.Lock()
-	defer t.mu.Unlock()
-	route.Name = HostControllerName
-	ctrl, errs := newController(route, t)
+	ctrl, errs := t.newHostController(route)
 	if len(errs) > 0 {
 		return errs
 	}
-	err := ctrl.validate(t.egress)
-	if err != nil {
-		return []error{err}
-	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.hostCtrl = ctrl
 	return nil
 }
 
+func (t *table) newHostController(route Route) (*controller, []error) {
+	if t.isEgress() {
+		return nil, []error{errors.New("host controller configuration is not valid for egress traffic")}
+	}
+	if !t.isEgress() && (route.Retry != nil || route.Timeout != nil || route.Failover != nil || route.CircuitBreaker != nil || route.Hedge != nil || route.Mirror != nil || route.Priority != nil) {
+		return nil, []error{errors.New("host controller configuration does not allow retry, rate limiter, or failover controllers")}
+	}
+	route.Name = HostControllerName
+	return t.newValidController(route)
+}
+
 func (t *table) SetDefaultController(route Route) []error {
 	//if !t.isEgress() {
 	//	return []error{errors.New("default controller configuration is not valid for ingress traffic")}
 	//}
-	t.mu.Lock()
-	defer t.mu.Unlock()
 	if route.Name == "" {
 		route.Name = DefaultControllerName
 	}
-	act, errs := newController(route, t)
+	act, errs := t.newValidController(route)
 	if len(errs) > 0 {
 		return errs
 	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.defaultCtrl = act
+	return nil
+}
+
+func (t *table) newValidController(route Route) (*controller, []error) {
+	act, errs := newController(route, t)
+	if len(errs) > 0 {
+		return nil, errs
+	}
 	err := act.validate(t.egress)
 	if err != nil {
-		return []error{err}
+		return nil, []error{err}
 	}
-	t.defaultCtrl = act
-	return nil
+	return act, nil
 }
 
 func (t *table) Host() Controller {
@@ -176,19 +182,25 @@ func (t *table) AddController(route Route) []error {
 	if IsEmpty(route.Name) {
 		return []error{errors.New("invalid argument: route name is empty")}
 	}
-	t.mu.Lock()
-	defer t.mu.Unlock()
-	act, errs := newController(route, t)
+	act, errs := t.newValidController(route)
 	if len(errs) > 0 {
 		return errs
 	}
-	err := act.validate(t.egress)
-	if err != nil {
-		return []error{err}
-	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	if _, ok := t.controllers[route.Name]; ok {
 		return []error{errors.New(fmt.Sprintf("invalid argument: route name is a duplicate [%v]", route.Name))}
 	}
+	if route.Pattern != "" {
+		p, err1 := newRoutePattern(route.Name, route.Pattern)
+		if err1 != nil {
+			return []error{err1}
+		}
+		err1 = t.router.add(p)
+		if err1 != nil {
+			return []error{err1}
+		}
+	}
 	t.controllers[route.Name] = act
 	return nil
 }
@@ -232,5 +244,6 @@ func (t *table) remove(name string) {
 	}
 	t.mu.Lock()
 	delete(t.controllers, name)
+	t.router.remove(name)
 	t.mu.Unlock()
 }
@@ -12,6 +12,13 @@ func (t *table) enableFailover(name string, enabled bool) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	if ctrl, ok := t.controllers[name]; ok {
+		if enabled && !ctrl.failover.enabled {
+			traffic := IngressTraffic
+			if t.egress {
+				traffic = EgressTraffic
+			}
+			defaultMetrics.failover(traffic, name)
+		}
 		c := cloneFailover(ctrl.failover)
 		c.enabled = enabled
 		t.update(name, cloneController[*failover](ctrl, c))
@@ -45,6 +52,19 @@ func (t *table) setProxyPattern(name string, pattern string, enable bool) {
 	}
 }
 
+func (t *table) setProxyCanary(name string, percentage int) {
+	if name == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ctrl, ok := t.controllers[name]; ok {
+		fc := cloneProxy(ctrl.proxy)
+		fc.canary.Percentage = percentage
+		t.update(name, cloneController[*proxy](ctrl, fc))
+	}
+}
+
 /*
 func (t *table) setFailoverInvoke(name string, fn FailoverInvoke, enable bool) {
 	if name == "" {
@@ -121,6 +141,9 @@ func (t *table) setRateLimit(name string, limit rate.Limit) {
 		c.config.Limit = limit
 		// Not cloning the limiter as an old reference will not cause stale data when logging
 		c.rateLimiter.SetLimit(limit)
+		if c.keys != nil {
+			c.keys.set(limit, c.config.Burst)
+		}
 		t.update(name, cloneController[*rateLimiter](ctrl, c))
 	}
 }
@@ -136,6 +159,9 @@ func (t *table) setRateBurst(name string, burst int) {
 		c.config.Burst = burst
 		// Not cloning the limiter as an old reference will not cause stale data when logging
 		c.rateLimiter.SetBurst(burst)
+		if c.keys != nil {
+			c.keys.set(c.config.Limit, burst)
+		}
 		t.update(name, cloneController[*rateLimiter](ctrl, c))
 	}
 }
@@ -151,6 +177,9 @@ func (t *table) setRateLimiter(name string, config RateLimiterConfig) {
 		c.config.Limit = config.Limit
 		c.config.Burst = config.Burst
 		c.rateLimiter = rate.NewLimiter(c.config.Limit, c.config.Burst)
+		if c.keys != nil {
+			c.keys.set(c.config.Limit, c.config.Burst)
+		}
 		t.update(name, cloneController[*rateLimiter](ctrl, c))
 	}
 }
@@ -183,3 +212,151 @@ func (t *table) setRetryRateLimit(name string, limit rate.Limit, burst int) {
 		t.update(name, cloneController[*retry](ctrl, c))
 	}
 }
+
+func (t *table) enableCircuitBreaker(name string, enabled bool) {
+	if name == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ctrl, ok := t.controllers[name]; ok {
+		c := cloneCircuitBreaker(ctrl.circuitBreaker)
+		c.enabled = enabled
+		t.update(name, cloneController[*circuitBreaker](ctrl, c))
+	}
+}
+
+func (t *table) setCircuitBreaker(name string, config CircuitBreakerConfig) {
+	if name == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ctrl, ok := t.controllers[name]; ok {
+		c := cloneCircuitBreaker(ctrl.circuitBreaker)
+		// Window size changes require new state, the current circuit state is carried over
+		if config.MinRequests != c.config.MinRequests {
+			s := newBreakerState(config.MinRequests)
+			c.state.mu.Lock()
+			s.state = c.state.state
+			s.openedAt = c.state.openedAt
+			c.state.mu.Unlock()
+			c.state = s
+		}
+		c.config = config
+		t.update(name, cloneController[*circuitBreaker](ctrl, c))
+	}
+}
+
+func (t *table) enableConcurrencyLimiter(name string, enabled bool) {
+	if name == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ctrl, ok := t.controllers[name]; ok {
+		c := cloneConcurrencyLimiter(ctrl.concurrency)
+		c.enabled = enabled
+		t.update(name, cloneController[*concurrencyLimiter](ctrl, c))
+	}
+}
+
+func (t *table) setConcurrencyLimiter(name string, config ConcurrencyLimiterConfig) {
+	if name == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ctrl, ok := t.controllers[name]; ok {
+		c := cloneConcurrencyLimiter(ctrl.concurrency)
+		c.config = config
+		// The in-flight count is carried over, the current limit is kept within the new bounds
+		c.state.mu.Lock()
+		c.state.limit = c.clamp(c.state.limit)
+		c.state.mu.Unlock()
+		t.update(name, cloneController[*concurrencyLimiter](ctrl, c))
+	}
+}
+
+func (t *table) enableBulkhead(name string, enabled bool) {
+	if name == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ctrl, ok := t.controllers[name]; ok {
+		c := cloneBulkhead(ctrl.bulkhead)
+		c.enabled = enabled
+		t.update(name, cloneController[*bulkhead](ctrl, c))
+	}
+}
+
+func (t *table) setBulkhead(name string, maxConcurrent, maxQueue int) {
+	if name == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ctrl, ok := t.controllers[name]; ok {
+		c := cloneBulkhead(ctrl.bulkhead)
+		c.config.MaxConcurrent = maxConcurrent
+		c.config.MaxQueue = maxQueue
+		// Not cloning the state, as in-flight requests need to release against it
+		c.state.mu.Lock()
+		c.state.resize(maxConcurrent, maxQueue)
+		c.state.mu.Unlock()
+		t.update(name, cloneController[*bulkhead](ctrl, c))
+	}
+}
+
+func (t *table) enableHedge(name string, enabled bool) {
+	if name == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ctrl, ok := t.controllers[name]; ok {
+		c := cloneHedge(ctrl.hedge)
+		c.enabled = enabled
+		t.update(name, cloneController[*hedge](ctrl, c))
+	}
+}
+
+func (t *table) setHedgeDelay(name string, delay time.Duration) {
+	if name == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ctrl, ok := t.controllers[name]; ok {
+		c := cloneHedge(ctrl.hedge)
+		c.config.Delay = delay
+		t.update(name, cloneController[*hedge](ctrl, c))
+	}
+}
+
+func (t *table) enableMirror(name string, enabled bool) {
+	if name == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ctrl, ok := t.controllers[name]; ok {
+		c := cloneMirror(ctrl.mirror)
+		c.enabled = enabled
+		t.update(name, cloneController[*mirror](ctrl, c))
+	}
+}
+
+func (t *table) setMirrorPercentage(name string, percentage float64) {
+	if name == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ctrl, ok := t.controllers[name]; ok {
+		c := cloneMirror(ctrl.mirror)
+		c.config.Percentage = percentage
+		t.update(name, cloneController[*mirror](ctrl, c))
+	}
+}
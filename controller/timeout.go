@@ -50,7 +50,7 @@ func newTimeout(name string, table *table, config *TimeoutConfig) *timeout {
 
 func (t *timeout) validate() error {
 	if t.config.Duration <= 0 {
-		return errors.New("invalid configuration: Timeout duration is <= 0")
+		return newFieldError("duration", errors.New("invalid configuration: Timeout duration is <= 0"))
 	}
 	return nil
 }
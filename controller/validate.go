@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gotemplates/host/decode"
+	"strings"
+)
+
+// fieldError - an error for a field of a route configuration, the error string starts with the path of the field
+type fieldError struct {
+	path string
+	err  error
+}
+
+func newFieldError(path string, err error) error {
+	return &fieldError{path: path, err: err}
+}
+
+func (e *fieldError) Error() string {
+	if e.path == "" {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.path, e.err)
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+// withPath - prefix the path of an error with the path of the enclosing configuration
+func withPath(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var fe *fieldError
+	if errors.As(err, &fe) {
+		return &fieldError{path: joinPath(path, fe.path), err: fe.err}
+	}
+	return &fieldError{path: path, err: err}
+}
+
+func joinPath(path, field string) string {
+	if strings.HasPrefix(field, "[") {
+		return path + field
+	}
+	return decode.Field(path, field)
+}
+
+// ValidateRoutes - validate a JSON route configuration for egress or ingress traffic, without changing a table.
+// Unknown fields are rejected, and every problem in every route is reported with its line and column and the path
+// of the field, for example routes[3].retry.codes
+func ValidateRoutes(buf []byte, traffic string) []error {
+	doc, err := decode.JSON(buf)
+	if err != nil {
+		return []error{err}
+	}
+	return validateRoutes(doc.Nest(RoutesTableName), traffic)
+}
+
+// ValidateRoutesYAML - validate a YAML route configuration, as ValidateRoutes does
+func ValidateRoutesYAML(buf []byte, traffic string) []error {
+	doc, err := decode.YAML(buf)
+	if err != nil {
+		return []error{err}
+	}
+	return validateRoutes(doc.Nest(RoutesTableName), traffic)
+}
+
+// ValidateRoutesTOML - validate a TOML route configuration, as ValidateRoutes does
+func ValidateRoutesTOML(buf []byte, traffic string) []error {
+	doc, err := decode.TOML(buf)
+	if err != nil {
+		return []error{err}
+	}
+	return validateRoutes(doc, traffic)
+}
+
+func validateRoutes(doc *decode.Document, traffic string) []error {
+	if traffic != EgressTraffic && traffic != IngressTraffic {
+		return []error{errors.New(fmt.Sprintf("invalid argument: traffic is invalid [%v]", traffic))}
+	}
+	routes, errs := decodeRoutes(doc)
+	if routes == nil && len(errs) > 0 {
+		return errs
+	}
+	t := newTable(traffic == EgressTraffic, false)
+	return append(errs, routeErrors(doc, t.reload(routes))...)
+}
+
+// decodeRoutes - decode the routes named routes in a document. Unknown fields are rejected, and the errors of every
+// route are returned. A route with errors is returned with only its name and pattern, so that duplicate names and
+// ambiguous patterns are still found
+func decodeRoutes(doc *decode.Document) ([]Route, []error) {
+	var items []any
+	if err := doc.Decode(RoutesTableName, &items); err != nil {
+		return nil, []error{err}
+	}
+	var errs []error
+	var routes []Route
+	for i := range items {
+		path := decode.Index(RoutesTableName, i)
+		for _, field := range doc.UnknownFields(path, RouteConfig{}) {
+			errs = append(errs, doc.Errorf(field, "%v: unknown field", field))
+		}
+		var config RouteConfig
+		if err := doc.Decode(path, &config); err != nil {
+			errs = append(errs, err)
+			routes = append(routes, Route{Name: config.Name, Pattern: config.Pattern})
+			continue
+		}
+		route, errs1 := newRouteFromConfig(config)
+		for _, err := range errs1 {
+			errs = append(errs, positionError(doc, withPath(path, err)))
+		}
+		if len(errs1) > 0 {
+			route = Route{Name: config.Name, Pattern: config.Pattern}
+		}
+		routes = append(routes, route)
+	}
+	return routes, errs
+}
+
+// readRoutes - decode the routes of a document, no routes are returned if there are errors
+func readRoutes(doc *decode.Document) ([]Route, []error) {
+	routes, errs := decodeRoutes(doc)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return routes, nil
+}
+
+// routeErrors - the errors of adding or reloading the routes of a document, with the path and position of the route
+func routeErrors(doc *decode.Document, errs []error) []error {
+	var errs1 []error
+	for _, err := range errs {
+		errs1 = append(errs1, positionError(doc, withPath(RoutesTableName, err)))
+	}
+	return errs1
+}
+
+// errorList - the errors of a route configuration, returned as one error
+type errorList []error
+
+func (l errorList) Error() string {
+	var s []string
+	for _, err := range l {
+		s = append(s, err.Error())
+	}
+	return strings.Join(s, "; ")
+}
+
+// joinErrors - a single error for the errors of a route configuration
+func joinErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errorList(errs)
+}
+
+// positionError - an error with the path of the field, and the line and column of the field in the document
+func positionError(doc *decode.Document, err error) error {
+	var fe *fieldError
+	if !errors.As(err, &fe) {
+		return err
+	}
+	return doc.Wrap(fe.path, fe)
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"fmt"
+)
+
+const invalidRoutesJson = `[
+  {
+    "Name": "google-search",
+    "Pattern": "google.com/search",
+    "Timeout": { "Duration": "500ms", "Status": 504 }
+  },
+  {
+    "Name": "twitter",
+    "Pattern": "twitter.com",
+    "Timeout": { "Duration": "-1ms" },
+    "Retry": { "Limit": 100, "Burst": 10, "Wait": "100ms", "Codes": [] }
+  },
+  {
+    "Name": "google-search",
+    "Pattern": "google.com/maps",
+    "RateLimiter": { "Limit": -1 }
+  },
+  {
+    "Name": "facebook",
+    "Timeout": { "Duration": 10 }
+  }
+]`
+
+const invalidRoutesYAML = `
+- name: google-search
+  pattern: google.com/search
+  retry:
+    limit: 100
+    burst: 10
+    wait: 100ms
+    codes: [503]
+    methods: [GET, get]
+- name: twitter
+  pattern: twitter.com
+  priority:
+    level: urgent
+`
+
+const invalidRoutesTOML = `
+[[routes]]
+Name = "google-search"
+Pattern = "google.com/search"
+
+[[routes]]
+Name = "twitter"
+Pattern = "twitter.com"
+Proxy = { Pattern = "http://localhost:8080", EjectionTime = "10x" }
+Bulkhead = { MaxConcurrent = 10, Queue = 5 }
+`
+
+func ExampleValidateRoutes() {
+	errs := ValidateRoutes([]byte(invalidRoutesJson), EgressTraffic)
+	for _, err := range errs {
+		fmt.Printf("test: ValidateRoutes() -> %v\n", err)
+	}
+
+	errs = ValidateRoutes([]byte(invalidRoutesJson), "both")
+	fmt.Printf("test: ValidateRoutes(both) -> %v\n", errs)
+
+	errs = ValidateRoutes([]byte(`[{"Name": "google-search"},]`), EgressTraffic)
+	fmt.Printf("test: ValidateRoutes(syntax) -> %v\n", errs)
+
+	errs = ValidateRoutes([]byte(`[{"Name": "google-search", "Timeout": { "Duration": "500ms" }}]`), EgressTraffic)
+	fmt.Printf("test: ValidateRoutes(valid) -> %v\n", errs)
+
+	//Output:
+	//test: ValidateRoutes() -> line 5, column 39: routes[0].Timeout.Status: unknown field
+	//test: ValidateRoutes() -> line 20, column 18: cannot unmarshal number into routes[3].Timeout.Duration of type string
+	//test: ValidateRoutes() -> line 10, column 18: routes[1].timeout.duration: invalid configuration: Timeout duration is <= 0
+	//test: ValidateRoutes() -> line 11, column 60: routes[1].retry.codes: invalid configuration: Retry status codes are empty
+	//test: ValidateRoutes() -> line 14, column 5: routes[2].name: invalid argument: route name is a duplicate [google-search]
+	//test: ValidateRoutes(both) -> [invalid argument: traffic is invalid [both]]
+	//test: ValidateRoutes(syntax) -> [line 1, column 28: invalid character ']' looking for beginning of value]
+	//test: ValidateRoutes(valid) -> []
+
+}
+
+func ExampleValidateRoutesYAML() {
+	errs := ValidateRoutesYAML([]byte(invalidRoutesYAML), EgressTraffic)
+	for _, err := range errs {
+		fmt.Printf("test: ValidateRoutesYAML() -> %v\n", err)
+	}
+
+	errs = ValidateRoutesYAML([]byte("- name: twitter\n  retry:\n    codes: [503]\n"), IngressTraffic)
+	fmt.Printf("test: ValidateRoutesYAML(ingress) -> %v\n", errs)
+
+	//Output:
+	//test: ValidateRoutesYAML() -> line 9, column 20: routes[0].retry.methods[1]: invalid configuration: Retry method is invalid [get]
+	//test: ValidateRoutesYAML() -> line 13, column 5: routes[1].priority.level: invalid configuration: Priority level is invalid [urgent]
+	//test: ValidateRoutesYAML(ingress) -> [line 2, column 3: routes[0].retry: invalid configuration: Retry is not valid for ingress traffic]
+
+}
+
+func ExampleValidateRoutesTOML() {
+	errs := ValidateRoutesTOML([]byte(invalidRoutesTOML), EgressTraffic)
+	for _, err := range errs {
+		fmt.Printf("test: ValidateRoutesTOML() -> %v\n", err)
+	}
+
+	//Output:
+	//test: ValidateRoutesTOML() -> line 10, column 34: routes[1].Bulkhead.Queue: unknown field
+	//test: ValidateRoutesTOML() -> line 9, column 46: routes[1].proxy.ejectionTime: strconv.Atoi: parsing "10x": invalid syntax
+
+}
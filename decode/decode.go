@@ -0,0 +1,323 @@
+package decode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Error - a configuration error at a position in the source, a column of 0 is unknown
+type Error struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Column <= 0 {
+		return fmt.Sprintf("line %v: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %v, column %v: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+type position struct {
+	line   int
+	column int
+}
+
+// Document - a parsed JSON, YAML or TOML document, values are decoded with encoding/json semantics so that field names
+// match the JSON configuration
+type Document struct {
+	value     any
+	positions map[string]position
+}
+
+// Decode - decode the value at the path into v, "" is the document root. If v is a slice, the elements are
+// decoded one at a time so that errors have the position of the element
+func (d *Document) Decode(path string, v any) error {
+	if d == nil {
+		return errors.New("invalid argument: document is nil")
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("invalid argument: decode target is not a pointer")
+	}
+	value, ok := d.lookup(path)
+	if !ok {
+		return d.Errorf(path, "value is missing")
+	}
+	items, isArray := value.([]any)
+	if rv.Elem().Kind() != reflect.Slice || !isArray {
+		return d.unmarshal(path, value, v)
+	}
+	slice := reflect.MakeSlice(rv.Elem().Type(), 0, len(items))
+	for i, item := range items {
+		elem := reflect.New(rv.Elem().Type().Elem())
+		if err := d.unmarshal(Index(path, i), item, elem.Interface()); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem.Elem())
+	}
+	rv.Elem().Set(slice)
+	return nil
+}
+
+// Wrap - wrap an error with the position of the path, or the nearest enclosing path with a position
+func (d *Document) Wrap(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if d == nil {
+		return err
+	}
+	key := canonical(path)
+	for {
+		if p, ok := d.positions[key]; ok {
+			return &Error{Line: p.line, Column: p.column, Err: err}
+		}
+		if key == "" {
+			return err
+		}
+		key = parent(key)
+	}
+}
+
+// Errorf - create an error with the position of the path
+func (d *Document) Errorf(path string, format string, args ...any) error {
+	return d.Wrap(path, errors.New(fmt.Sprintf(format, args...)))
+}
+
+func (d *Document) unmarshal(path string, value, v any) error {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return d.Wrap(path, err)
+	}
+	err = json.Unmarshal(buf, v)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		field := display(Field(path, typeErr.Field))
+		return d.Errorf(field, "cannot unmarshal %v into %v of type %v", typeErr.Value, field, typeErr.Type)
+	}
+	return d.Wrap(path, err)
+}
+
+// lookup - find the value at the path, map keys are matched without case as encoding/json does
+func (d *Document) lookup(path string) (any, bool) {
+	value := d.value
+	for _, segment := range split(path) {
+		switch v := value.(type) {
+		case map[string]any:
+			found := false
+			for k, item := range v {
+				if strings.EqualFold(k, segment) {
+					value, found = item, true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// Field - the path of a field in the value at a path
+func Field(path, field string) string {
+	if path == "" {
+		return field
+	}
+	if field == "" {
+		return path
+	}
+	return path + "." + field
+}
+
+// Index - the path of an element of the array at a path
+func Index(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+// split - the segments of a path, array indexes are segments
+func split(path string) []string {
+	var segments []string
+	for _, s := range strings.Split(strings.ReplaceAll(path, "[", ".["), ".") {
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// canonical - the position key of a path, keys are not case sensitive and numeric segments are array indexes
+func canonical(path string) string {
+	return display(strings.ToLower(path))
+}
+
+// display - a path with numeric segments as array indexes, encoding/json reports an element as .0
+func display(path string) string {
+	s := ""
+	for _, segment := range split(path) {
+		if _, err := strconv.Atoi(segment); err == nil {
+			s += "[" + segment + "]"
+		} else {
+			s = Field(s, segment)
+		}
+	}
+	return s
+}
+
+func parent(key string) string {
+	i := strings.LastIndexAny(key, ".[")
+	if i < 0 {
+		return ""
+	}
+	return key[:i]
+}
+
+func (d *Document) setPosition(path string, line, column int) {
+	if d.positions == nil {
+		d.positions = make(map[string]position)
+	}
+	key := canonical(path)
+	if _, ok := d.positions[key]; !ok {
+		d.positions[key] = position{line: line, column: column}
+	}
+}
+
+// Nest - the document as the value of a field, so that the paths of a list document start with the name of the list
+func (d *Document) Nest(name string) *Document {
+	n := new(Document)
+	n.value = map[string]any{name: d.value}
+	for key, p := range d.positions {
+		n.setPosition(Field(name, key), p.line, p.column)
+	}
+	return n
+}
+
+// UnknownFields - the paths of the fields in the value at the path that are not fields of v. Field names are
+// matched without case as encoding/json does
+func (d *Document) UnknownFields(path string, v any) []string {
+	if d == nil || v == nil {
+		return nil
+	}
+	value, ok := d.lookup(path)
+	if !ok {
+		return nil
+	}
+	var paths []string
+	unknownFields(path, value, reflect.TypeOf(v), &paths)
+	return paths
+}
+
+func unknownFields(path string, value any, t reflect.Type, paths *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		for _, key := range sortedKeys(m) {
+			f, ok := structField(t, key)
+			if !ok {
+				*paths = append(*paths, Field(path, key))
+				continue
+			}
+			unknownFields(Field(path, key), m[key], f.Type, paths)
+		}
+	case reflect.Map:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		for _, key := range sortedKeys(m) {
+			unknownFields(Field(path, key), m[key], t.Elem(), paths)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]any)
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			unknownFields(Index(path, i), item, t.Elem(), paths)
+		}
+	}
+}
+
+// structField - the field decoded from a key, by json tag or field name
+func structField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := FieldName(f)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			// Embedded struct, its fields are promoted
+			if ef, ok := structField(indirect(f.Type), key); ok {
+				return ef, true
+			}
+			continue
+		}
+		if strings.EqualFold(name, key) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// FieldName - the name of a struct field in a document, "" for an embedded struct whose fields are promoted. Fields
+// that are not exported, or are tagged json:"-", are not decoded
+func FieldName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if f.Anonymous && name == "" && indirect(f.Type).Kind() == reflect.Struct {
+		return "", true
+	}
+	if !f.IsExported() {
+		return "", false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
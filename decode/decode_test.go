@@ -0,0 +1,149 @@
+package decode
+
+import (
+	"fmt"
+)
+
+type testConfig struct {
+	Name    string
+	Limit   float64
+	Servers []testServer
+}
+
+type testServer struct {
+	Host string
+	Port int
+}
+
+const testYAML = `
+- name: first
+  limit: 100
+  servers:
+    - host: localhost
+      port: 8080
+- name: second
+  limit: fast
+`
+
+const testTOML = `
+[[configs]]
+Name = "first"
+Limit = 100
+
+[[configs.Servers]]
+Host = "localhost"
+Port = 8080
+
+[[configs]]
+Name = "second"
+Servers = [{ Host = "localhost", Port = "8081" }]
+`
+
+func ExampleYAML() {
+	doc, err := YAML([]byte(testYAML))
+	fmt.Printf("test: YAML() -> [err:%v]\n", err)
+
+	var configs []testConfig
+	err = doc.Decode("", &configs)
+	fmt.Printf("test: Decode() -> [err:%v] [count:%v]\n", err, len(configs))
+
+	var config testConfig
+	err = doc.Decode("[0]", &config)
+	fmt.Printf("test: Decode([0]) -> [err:%v] [config:%v]\n", err, config)
+
+	fmt.Printf("test: Wrap([1].name) -> %v\n", doc.Errorf("[1].name", "name is a duplicate"))
+
+	_, err = YAML([]byte("- name: first\n  limit: 100\n - name: second"))
+	fmt.Printf("test: YAML(invalid) -> [err:%v]\n", err)
+
+	//Output:
+	//test: YAML() -> [err:<nil>]
+	//test: Decode() -> [err:line 8, column 3: cannot unmarshal string into [1].limit of type float64] [count:0]
+	//test: Decode([0]) -> [err:<nil>] [config:{first 100 [{localhost 8080}]}]
+	//test: Wrap([1].name) -> line 7, column 3: name is a duplicate
+	//test: YAML(invalid) -> [err:line 2: did not find expected '-' indicator]
+
+}
+
+func ExampleTOML() {
+	doc, err := TOML([]byte(testTOML))
+	fmt.Printf("test: TOML() -> [err:%v]\n", err)
+
+	var configs []testConfig
+	err = doc.Decode("configs", &configs)
+	fmt.Printf("test: Decode() -> [err:%v] [count:%v]\n", err, len(configs))
+
+	var config testConfig
+	err = doc.Decode("configs[0]", &config)
+	fmt.Printf("test: Decode(configs[0]) -> [err:%v] [config:%v]\n", err, config)
+
+	fmt.Printf("test: Wrap(configs[1]) -> %v\n", doc.Errorf("configs[1]", "name is a duplicate"))
+	fmt.Printf("test: Wrap(configs[0].Servers[0].Host) -> %v\n", doc.Errorf("configs[0].Servers[0].Host", "host is invalid"))
+
+	err = doc.Decode("routes", &configs)
+	fmt.Printf("test: Decode(routes) -> [err:%v]\n", err)
+
+	_, err = TOML([]byte("[[configs]]\nName = \"first\"\nLimit = \n"))
+	fmt.Printf("test: TOML(invalid) -> [err:%v]\n", err)
+
+	//Output:
+	//test: TOML() -> [err:<nil>]
+	//test: Decode() -> [err:line 12, column 34: cannot unmarshal string into configs[1].Servers[0].Port of type int] [count:0]
+	//test: Decode(configs[0]) -> [err:<nil>] [config:{first 100 [{localhost 8080}]}]
+	//test: Wrap(configs[1]) -> line 10, column 3: name is a duplicate
+	//test: Wrap(configs[0].Servers[0].Host) -> line 7, column 1: host is invalid
+	//test: Decode(routes) -> [err:value is missing]
+	//test: TOML(invalid) -> [err:line 3, column 9: incomplete number]
+
+}
+
+const testJSON = `[
+  {
+    "Name": "first",
+    "Limit": 100,
+    "Servers": [{ "Host": "localhost", "Port": 8080, "Protocol": "h2" }]
+  },
+  {
+    "name": "second",
+    "burst": 10
+  }
+]`
+
+func ExampleJSON() {
+	doc, err := JSON([]byte(testJSON))
+	fmt.Printf("test: JSON() -> [err:%v]\n", err)
+
+	var configs []testConfig
+	err = doc.Decode("", &configs)
+	fmt.Printf("test: Decode() -> [err:%v] [configs:%v]\n", err, configs)
+
+	fmt.Printf("test: Wrap([1].name) -> %v\n", doc.Errorf("[1].name", "name is a duplicate"))
+
+	_, err = JSON([]byte("[\n  { \"Name\": \"first\" }\n  { \"Name\": \"second\" }\n]"))
+	fmt.Printf("test: JSON(invalid) -> [err:%v]\n", err)
+
+	//Output:
+	//test: JSON() -> [err:<nil>]
+	//test: Decode() -> [err:<nil>] [configs:[{first 100 [{localhost 8080}]} {second 0 []}]]
+	//test: Wrap([1].name) -> line 8, column 5: name is a duplicate
+	//test: JSON(invalid) -> [err:line 3, column 3: invalid character '{' after array element]
+
+}
+
+func ExampleDocument_UnknownFields() {
+	doc, _ := JSON([]byte(testJSON))
+	for _, path := range doc.UnknownFields("", []testConfig{}) {
+		fmt.Printf("test: UnknownFields() -> %v\n", doc.Errorf(path, "%v: unknown field", path))
+	}
+
+	nested := doc.Nest("configs")
+	fmt.Printf("test: UnknownFields(configs[1]) -> %v\n", nested.UnknownFields("configs[1]", testConfig{}))
+	fmt.Printf("test: Wrap(configs[1].burst) -> %v\n", nested.Errorf("configs[1].burst", "unknown field"))
+
+	//Output:
+	//test: UnknownFields() -> line 5, column 54: [0].Servers[0].Protocol: unknown field
+	//test: UnknownFields() -> line 9, column 5: [1].burst: unknown field
+	//test: UnknownFields(configs[1]) -> [configs[1].burst]
+	//test: Wrap(configs[1].burst) -> line 9, column 5: unknown field
+
+}
@@ -0,0 +1,107 @@
+package decode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// JSON - parse a JSON document, syntax errors have the line and column of the error. Numbers are kept as
+// json.Number so that integers are not rounded
+func JSON(buf []byte) (*Document, error) {
+	if buf == nil {
+		return nil, errors.New("invalid argument: buffer is nil")
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			// The offset is after the invalid character
+			line, column := newLines(buf).position(syntaxErr.Offset - 1)
+			return nil, &Error{Line: line, Column: column, Err: err}
+		}
+		return nil, err
+	}
+	d := new(Document)
+	d.value = value
+	d.jsonPositions(buf)
+	return d, nil
+}
+
+// lines - the offset of each newline in a buffer
+type lines []int64
+
+func newLines(buf []byte) lines {
+	var l lines
+	for i, b := range buf {
+		if b == '\n' {
+			l = append(l, int64(i))
+		}
+	}
+	return l
+}
+
+// position - the line and column of an offset, both start at 1
+func (l lines) position(offset int64) (int, int) {
+	line := sort.Search(len(l), func(i int) bool { return l[i] >= offset })
+	start := int64(0)
+	if line > 0 {
+		start = l[line-1] + 1
+	}
+	return line + 1, int(offset-start) + 1
+}
+
+// token - the line and column of the token after an offset, the decoder offset is before any whitespace and
+// separators that precede a token
+func (l lines) token(buf []byte, offset int64) (int, int) {
+	for offset < int64(len(buf)) && bytes.IndexByte([]byte(" \t\r\n,:"), buf[offset]) >= 0 {
+		offset++
+	}
+	return l.position(offset)
+}
+
+func (d *Document) jsonPositions(buf []byte) {
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	d.jsonValue(dec, buf, newLines(buf), "")
+}
+
+// jsonValue - set the position of the value at the path and its children, false if the document is not valid
+func (d *Document) jsonValue(dec *json.Decoder, buf []byte, l lines, path string) bool {
+	line, column := l.token(buf, dec.InputOffset())
+	d.setPosition(path, line, column)
+	tok, err := dec.Token()
+	if err != nil {
+		return false
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return true
+	}
+	switch delim {
+	case '{':
+		for dec.More() {
+			line, column = l.token(buf, dec.InputOffset())
+			key, err := dec.Token()
+			if err != nil {
+				return false
+			}
+			name, _ := key.(string)
+			// The key position is used for the value, so an error points at the field name
+			d.setPosition(Field(path, name), line, column)
+			if !d.jsonValue(dec, buf, l, Field(path, name)) {
+				return false
+			}
+		}
+	case '[':
+		for i := 0; dec.More(); i++ {
+			if !d.jsonValue(dec, buf, l, Index(path, i)) {
+				return false
+			}
+		}
+	}
+	_, err = dec.Token()
+	return err == nil
+}
@@ -0,0 +1,89 @@
+package decode
+
+import (
+	"errors"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"strings"
+)
+
+// TOML - parse a TOML document, syntax errors have the line and column of the error. A list of routes or
+// operators is an array of tables, for example [[routes]]
+func TOML(buf []byte) (*Document, error) {
+	if buf == nil {
+		return nil, errors.New("invalid argument: buffer is nil")
+	}
+	d := new(Document)
+	var value map[string]any
+	if err := toml.Unmarshal(buf, &value); err != nil {
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			line, column := decodeErr.Position()
+			return nil, &Error{Line: line, Column: column, Err: errors.New(strings.TrimPrefix(decodeErr.Error(), "toml: "))}
+		}
+		return nil, err
+	}
+	d.value = value
+	d.tomlPositions(buf)
+	return d, nil
+}
+
+// tomlPositions - the position of each key, array tables are counted so that each element has an index
+func (d *Document) tomlPositions(buf []byte) {
+	p := unstable.Parser{}
+	p.Reset(buf)
+	arrays := make(map[string]int)
+	table := ""
+	for p.NextExpression() {
+		expr := p.Expression()
+		switch expr.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			table = d.tomlKey(&p, "", expr.Key(), arrays, expr.Kind == unstable.ArrayTable)
+		case unstable.KeyValue:
+			d.tomlKeyValue(&p, table, expr)
+		}
+	}
+}
+
+// tomlKey - set the position of each part of a dotted key, and return the path of the key
+func (d *Document) tomlKey(p *unstable.Parser, path string, it unstable.Iterator, arrays map[string]int, arrayTable bool) string {
+	for it.Next() {
+		key := it.Node()
+		path = Field(path, string(key.Data))
+		shape := p.Shape(key.Raw)
+		if arrayTable && it.IsLast() {
+			arrays[strings.ToLower(path)]++
+		}
+		if n, ok := arrays[strings.ToLower(path)]; ok {
+			d.setPosition(path, shape.Start.Line, shape.Start.Column)
+			path = Index(path, n-1)
+		}
+		d.setPosition(path, shape.Start.Line, shape.Start.Column)
+	}
+	return path
+}
+
+func (d *Document) tomlKeyValue(p *unstable.Parser, table string, expr *unstable.Node) {
+	path := d.tomlKey(p, table, expr.Key(), nil, false)
+	d.tomlValue(p, path, expr.Value())
+}
+
+func (d *Document) tomlValue(p *unstable.Parser, path string, value *unstable.Node) {
+	switch value.Kind {
+	case unstable.InlineTable:
+		it := value.Children()
+		for it.Next() {
+			d.tomlKeyValue(p, path, it.Node())
+		}
+	case unstable.Array:
+		it := value.Children()
+		for i := 0; it.Next(); i++ {
+			item := it.Node()
+			if item.Raw.Length > 0 {
+				shape := p.Shape(item.Raw)
+				d.setPosition(Index(path, i), shape.Start.Line, shape.Start.Column)
+			}
+			d.tomlValue(p, Index(path, i), item)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package decode
+
+import (
+	"errors"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"strconv"
+)
+
+var yamlLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// YAML - parse a YAML document, syntax errors have the line of the error
+func YAML(buf []byte) (*Document, error) {
+	if buf == nil {
+		return nil, errors.New("invalid argument: buffer is nil")
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(buf, &root); err != nil {
+		if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return nil, &Error{Line: line, Err: errors.New(m[2])}
+		}
+		return nil, err
+	}
+	d := new(Document)
+	if len(root.Content) == 0 {
+		return d, nil
+	}
+	node := root.Content[0]
+	if err := node.Decode(&d.value); err != nil {
+		return nil, &Error{Line: node.Line, Column: node.Column, Err: err}
+	}
+	d.yamlPositions("", node)
+	return d, nil
+}
+
+func (d *Document) yamlPositions(path string, node *yaml.Node) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		d.setPosition(path, node.Line, node.Column)
+		node = node.Alias
+	}
+	d.setPosition(path, node.Line, node.Column)
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			// The key position is used for the value, so an error points at the field name
+			d.setPosition(Field(path, key.Value), key.Line, key.Column)
+			d.yamlPositions(Field(path, key.Value), node.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			d.yamlPositions(Index(path, i), item)
+		}
+	}
+}
@@ -6,13 +6,14 @@ require (
 	github.com/felixge/httpsnoop v1.0.3
 	github.com/google/uuid v1.3.0
 	github.com/gotemplates/core v0.0.0-20230310155125-62cdf6089a6d
+	github.com/pelletier/go-toml/v2 v2.0.9
 	golang.org/x/time v0.3.0
 	google.golang.org/grpc v1.53.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/go-http-utils/headers v0.0.0-20181008091004-fed159eddc2a // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -16,6 +16,12 @@ type DatabaseUrl struct {
 	Url string
 }
 
+// FailoverStatus - content of a FailoverEvent message
+type FailoverStatus struct {
+	Name     string
+	Failover bool
+}
+
 // ControllerApply - type for applying a controller
 type ControllerApply func(ctx context.Context, statusCode func() int, uri, requestId, method string) (fn func(), newCtx context.Context, rateLimited bool)
 
@@ -63,3 +69,16 @@ func AccessControllerApply(msg *Message) ControllerApply {
 	}
 	return nil
 }
+
+// AccessFailoverStatus - access function for a FailoverStatus in a message
+func AccessFailoverStatus(msg *Message) (FailoverStatus, bool) {
+	if msg == nil || msg.Content == nil {
+		return FailoverStatus{}, false
+	}
+	for _, c := range msg.Content {
+		if status, ok := c.(FailoverStatus); ok {
+			return status, true
+		}
+	}
+	return FailoverStatus{}, false
+}
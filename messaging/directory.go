@@ -69,6 +69,22 @@ func (d *EntryDirectory) Send(msg Message) error {
 	return errors.New(fmt.Sprintf("entry not found: [%v]", msg.To))
 }
 
+// Broadcast - send a message to all entries, an entry whose channel is full does not receive the message
+func (d *EntryDirectory) Broadcast(msg Message) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, e := range d.m {
+		if e.c == nil {
+			continue
+		}
+		msg.To = e.uri
+		select {
+		case e.c <- msg:
+		default:
+		}
+	}
+}
+
 func (d *EntryDirectory) Shutdown() {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
@@ -94,3 +94,22 @@ func ExampleEntryDirectory_Send() {
 	//test: <- c -> : [urn:test-1] [urn:test-2] [urn:test-3]
 
 }
+
+func ExampleEntryDirectory_Broadcast() {
+	d := NewEntryDirectory()
+	c1 := make(chan Message, 1)
+	c2 := make(chan Message)
+
+	d.Add("urn:test-1", c1)
+	d.Add("urn:test-2", c2)
+	d.Add("urn:test-3", nil)
+
+	d.Broadcast(Message{From: "test-route", Event: FailoverEvent, Content: []any{FailoverStatus{Name: "test-route", Failover: true}}})
+	msg := <-c1
+	status, ok := AccessFailoverStatus(&msg)
+	fmt.Printf("test: Broadcast() -> [to:%v] [event:%v] [status:%v] [ok:%v] [pending:%v]\n", msg.To, msg.Event, status, ok, len(c2))
+
+	//Output:
+	//test: Broadcast() -> [to:urn:test-1] [event:event:failover] [status:{test-route true}] [ok:true] [pending:0]
+
+}
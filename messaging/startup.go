@@ -30,6 +30,11 @@ func registerResourceUnchecked(uri string, c chan Message) error {
 	return nil
 }
 
+// Broadcast - send a message to all registered resources without blocking
+func Broadcast(msg Message) {
+	directory.Broadcast(msg)
+}
+
 // Shutdown - resource shutdown
 func Shutdown() {
 	directory.Shutdown()
@@ -3,6 +3,8 @@ package middleware
 import (
 	"github.com/felixge/httpsnoop"
 	"github.com/gotemplates/host/controller"
+	"github.com/gotemplates/host/tracing"
+	"io"
 	"net/http"
 	"time"
 )
@@ -11,14 +13,38 @@ import (
 func ControllerHttpHostMetricsHandler(appHandler http.Handler, msg string) http.Handler {
 	wrappedH := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now().UTC()
-		ctrl := controller.IngressTable.Host()
+		host := controller.IngressTable.Host()
+		ctrl := controller.IngressTable.LookupHttp(r)
 		var m httpsnoop.Metrics
+		r, span := startSpan(tracing.Extract(r.Context(), r.Header), ctrl, controller.IngressTraffic, tracing.ServerKind, r)
+		defer func() { finishSpan(span, m.Code, nil) }()
 
-		if rlc, ok := ctrl.RateLimiter(); ok && !rlc.Allow() {
-			ctrl.LogHttpIngress(start, time.Since(start), r, rlc.StatusCode(), 0, controller.RateLimitFlag)
+		s := loadShedder()
+		if s != nil {
+			if !s.Acquire(priority(ctrl, r)) {
+				ctrl.LogHttpIngress(start, time.Since(start), r, s.StatusCode(), 0, controller.ShedFlag)
+				w.WriteHeader(s.StatusCode())
+				return
+			}
+			defer s.Release()
+		}
+		hostAdmission, ok := admit(host, w, r, start)
+		if !ok {
+			return
+		}
+		admission, ok := admit(ctrl, w, r, start)
+		if !ok {
+			// The request was not handled, so the host limiter is not given an outcome
+			hostAdmission.cancel()
 			return
 		}
-		ctrl = controller.IngressTable.LookupHttp(r)
+		defer func() {
+			admission.release(m.Code)
+			hostAdmission.release(m.Code)
+		}()
+		if s != nil {
+			s.Observe(time.Since(start))
+		}
 		if toc, ok := ctrl.Timeout(); ok {
 			m = httpsnoop.CaptureMetrics(http.TimeoutHandler(appHandler, toc.Duration(), msg), w, r)
 		} else {
@@ -29,3 +55,95 @@ func ControllerHttpHostMetricsHandler(appHandler http.Handler, msg string) http.
 	})
 	return wrappedH
 }
+
+// priority - the priority of an ingress request, DefaultPriority if the route does not configure one
+func priority(ctrl controller.Controller, r *http.Request) int {
+	if pc, ok := ctrl.Priority(); ok {
+		return pc.RequestLevel(r)
+	}
+	return controller.DefaultPriority
+}
+
+func allow(rlc controller.RateLimiter, r *http.Request) bool {
+	ok, _ := rlc.AllowRequest(r)
+	return ok
+}
+
+// admission - the concurrency limiter and bulkhead slots of an admitted request
+type admission struct {
+	clc   controller.ConcurrencyLimiter
+	bhc   controller.Bulkhead
+	start time.Time
+}
+
+// release - release the slots of a handled request, the outcome is recorded by the concurrency limiter
+func (a *admission) release(statusCode int) {
+	if a.bhc != nil {
+		a.bhc.Release()
+	}
+	if a.clc != nil {
+		a.clc.Release(time.Since(a.start), statusCode < http.StatusInternalServerError)
+	}
+}
+
+// cancel - release the slots of a request that was not handled, without an outcome
+func (a *admission) cancel() {
+	if a.bhc != nil {
+		a.bhc.Release()
+	}
+	if a.clc != nil {
+		a.clc.Cancel()
+	}
+}
+
+// admit - apply the controller rate limiter, concurrency limiter and bulkhead, in the same order as egress traffic.
+// The returned admission releases the request
+func admit(ctrl controller.Controller, w http.ResponseWriter, r *http.Request, start time.Time) (*admission, bool) {
+	if rlc, ok := ctrl.RateLimiter(); ok {
+		if !allow(rlc, r) {
+			written := writeRateLimited(w, r, rlc)
+			ctrl.LogHttpIngress(start, time.Since(start), r, rlc.StatusCode(), written, controller.RateLimitFlag)
+			return nil, false
+		}
+		if rlc.IsHeadersEnabled() {
+			copyHeaders(w.Header(), rlc.Headers(r))
+		}
+	}
+	a := &admission{start: start}
+	if clc, ok := ctrl.ConcurrencyLimiter(); ok {
+		if !clc.Acquire() {
+			ctrl.LogHttpIngress(start, time.Since(start), r, clc.StatusCode(), 0, controller.ConcurrencyFlag)
+			w.WriteHeader(clc.StatusCode())
+			return nil, false
+		}
+		a.clc = clc
+	}
+	if bhc, ok := ctrl.Bulkhead(); ok {
+		if !bhc.Acquire(r.Context()) {
+			a.cancel()
+			ctrl.LogHttpIngress(start, time.Since(start), r, bhc.StatusCode(), 0, controller.BulkheadFlag)
+			w.WriteHeader(bhc.StatusCode())
+			return nil, false
+		}
+		a.bhc = bhc
+	}
+	return a, true
+}
+
+// writeRateLimited - write the rejected response, with the rate limit headers and the configured body
+func writeRateLimited(w http.ResponseWriter, r *http.Request, rlc controller.RateLimiter) int64 {
+	copyHeaders(w.Header(), rlc.Headers(r))
+	body := rlc.Body()
+	if body != "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.WriteHeader(rlc.StatusCode())
+	n, _ := io.WriteString(w, body)
+	return int64(n)
+}
+
+func copyHeaders(dst, src http.Header) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
@@ -2,7 +2,10 @@ package middleware
 
 import (
 	"fmt"
+	"github.com/gotemplates/host/controller"
 	"net/http"
+	"net/http/httptest"
+	"time"
 )
 
 func ExampleTimeoutHandler() {
@@ -19,3 +22,92 @@ func _ExampleMiddleware() {
 	//Output:
 	//fail
 }
+
+func Example_writeRateLimited() {
+	t := controller.NewEgressTable()
+	config := controller.NewRateLimiterConfig(1, 1, 429)
+	config.Body = "too many requests"
+	t.AddController(controller.NewRoute("rate-limit-route", controller.EgressTraffic, "", false, config))
+	rlc, _ := t.LookupByName("rate-limit-route").RateLimiter()
+
+	req, _ := http.NewRequest("GET", "https://www.google.com/search", nil)
+	rlc.Allow()
+	rec := httptest.NewRecorder()
+	written := writeRateLimited(rec, req, rlc)
+	fmt.Printf("test: writeRateLimited() -> [status:%v] [written:%v] [body:%v]\n", rec.Code, written, rec.Body.String())
+	fmt.Printf("test: writeRateLimited() -> [limit:%v] [remaining:%v] [reset:%v] [retry-after:%v]\n",
+		rec.Header().Get(controller.RateLimitLimitHeaderName), rec.Header().Get(controller.RateLimitRemainingHeaderName),
+		rec.Header().Get(controller.RateLimitResetHeaderName), rec.Header().Get(controller.RetryAfterHeaderName))
+
+	//Output:
+	//test: writeRateLimited() -> [status:429] [written:17] [body:too many requests]
+	//test: writeRateLimited() -> [limit:1] [remaining:0] [reset:1] [retry-after:1]
+
+}
+
+func Example_admit() {
+	t := controller.NewIngressTable()
+	errs := t.AddController(controller.NewRoute("ingress-route", controller.IngressTraffic, "", false,
+		controller.NewRateLimiterConfig(100, 1, 429), controller.NewBulkheadConfig(1, 0, 0, 503)))
+	fmt.Printf("test: AddController(ingress) -> %v\n", errs)
+	ctrl := t.LookupByName("ingress-route")
+
+	req, _ := http.NewRequest("GET", "https://www.google.com/search", nil)
+	rec := httptest.NewRecorder()
+	admission, ok := admit(ctrl, rec, req, time.Now())
+	fmt.Printf("test: admit() -> [ok:%v] [status:%v]\n", ok, rec.Code)
+
+	rec = httptest.NewRecorder()
+	_, ok = admit(ctrl, rec, req, time.Now())
+	fmt.Printf("test: admit(rate-limited) -> [ok:%v] [status:%v]\n", ok, rec.Code)
+
+	time.Sleep(time.Millisecond * 20)
+	rec = httptest.NewRecorder()
+	_, ok = admit(ctrl, rec, req, time.Now())
+	fmt.Printf("test: admit(bulkhead) -> [ok:%v] [status:%v]\n", ok, rec.Code)
+
+	admission.release(http.StatusOK)
+	time.Sleep(time.Millisecond * 20)
+	rec = httptest.NewRecorder()
+	_, ok = admit(ctrl, rec, req, time.Now())
+	fmt.Printf("test: admit(released) -> [ok:%v] [status:%v]\n", ok, rec.Code)
+
+	//Output:
+	//test: AddController(ingress) -> []
+	//test: admit() -> [ok:true] [status:200]
+	//test: Write() -> [{"traffic":"ingress","route_name":"ingress-route","method":"GET","host":"www.google.com","path":"/search","protocol":"HTTP/1.1","status_code":429,"status_flags":"RL","bytes_received":-1,"bytes_sent":0,"timeout_ms":-1,"rate-limit":100,"rate-burst":1,"retry":,"retry-rate-limit":,"retry-rate-burst":,"failover":,"proxy":}]
+	//test: admit(rate-limited) -> [ok:false] [status:429]
+	//test: Write() -> [{"traffic":"ingress","route_name":"ingress-route","method":"GET","host":"www.google.com","path":"/search","protocol":"HTTP/1.1","status_code":503,"status_flags":"BH","bytes_received":-1,"bytes_sent":0,"timeout_ms":-1,"rate-limit":100,"rate-burst":1,"retry":,"retry-rate-limit":,"retry-rate-burst":,"failover":,"proxy":}]
+	//test: admit(bulkhead) -> [ok:false] [status:503]
+	//test: admit(released) -> [ok:true] [status:200]
+
+}
+
+func Example_admit_cancel() {
+	t := controller.NewIngressTable()
+	errs := t.AddController(controller.NewRoute("ingress-route", controller.IngressTraffic, "", false,
+		controller.NewConcurrencyLimiterConfig(controller.AIMDAlgorithm, 4, 1, 8, 503), controller.NewBulkheadConfig(1, 0, 0, 503)))
+	fmt.Printf("test: AddController(ingress) -> %v\n", errs)
+	ctrl := t.LookupByName("ingress-route")
+	clc, _ := ctrl.ConcurrencyLimiter()
+
+	req, _ := http.NewRequest("GET", "https://www.google.com/search", nil)
+	admission, ok := admit(ctrl, httptest.NewRecorder(), req, time.Now())
+	fmt.Printf("test: admit() -> [ok:%v] [limit:%v] [in-flight:%v]\n", ok, clc.Limit(), clc.InFlight())
+
+	// The request rejected by the bulkhead releases the concurrency limiter without an outcome
+	rec := httptest.NewRecorder()
+	_, ok = admit(ctrl, rec, req, time.Now())
+	fmt.Printf("test: admit(bulkhead) -> [ok:%v] [status:%v] [limit:%v] [in-flight:%v]\n", ok, rec.Code, clc.Limit(), clc.InFlight())
+
+	admission.cancel()
+	fmt.Printf("test: cancel() -> [limit:%v] [in-flight:%v]\n", clc.Limit(), clc.InFlight())
+
+	//Output:
+	//test: AddController(ingress) -> []
+	//test: admit() -> [ok:true] [limit:4] [in-flight:1]
+	//test: Write() -> [{"traffic":"ingress","route_name":"ingress-route","method":"GET","host":"www.google.com","path":"/search","protocol":"HTTP/1.1","status_code":503,"status_flags":"BH","bytes_received":-1,"bytes_sent":0,"timeout_ms":-1,"rate-limit":-1,"rate-burst":-1,"retry":,"retry-rate-limit":,"retry-rate-burst":,"failover":,"proxy":}]
+	//test: admit(bulkhead) -> [ok:false] [status:503] [limit:4] [in-flight:1]
+	//test: cancel() -> [limit:4] [in-flight:0]
+
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"github.com/gotemplates/host/controller"
+	"io"
+	"net/http"
+	"time"
+)
+
+type hedgeResult struct {
+	resp        *http.Response
+	err         error
+	statusFlags string
+	hedged      bool
+	latency     time.Duration
+}
+
+// cancelBody - cancel the request context once the winning response body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// hedgedExchange - send the request, and a hedged copy if the request is slower than the hedge delay. The first
+// successful response is used and the other request is cancelled, a timeout is not a successful response. Only
+// idempotent methods are hedged.
+func (w *controllerWrapper) hedgedExchange(tc controller.Timeout, hc controller.Hedge, req *http.Request) (resp *http.Response, err error, statusFlags string, hedged bool) {
+	if hc == nil || !hc.IsEnabled() || !hc.IsHedgeableMethod(req.Method) || !(req.Body == nil || req.Body == http.NoBody || req.GetBody != nil) {
+		resp, err, statusFlags = w.exchange(tc, req)
+		return
+	}
+	hc.Request()
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	send := func(hedged bool) bool {
+		ctx, cancel := context.WithCancel(req.Context())
+		r := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err1 := req.GetBody()
+			if err1 != nil {
+				cancel()
+				return false
+			}
+			r.Body = body
+		}
+		cancels = append(cancels, cancel)
+		go func() {
+			start := time.Now()
+			resp, err, flags := w.exchange(tc, r)
+			results <- hedgeResult{resp: resp, err: err, statusFlags: flags, hedged: hedged, latency: time.Since(start)}
+		}()
+		return true
+	}
+	send(false)
+	timer := time.NewTimer(hc.Delay())
+	defer timer.Stop()
+	pending := 1
+	sent := false
+	var result hedgeResult
+	for pending > 0 {
+		select {
+		case result = <-results:
+			pending--
+			// Only the latency of the original request is recorded, an original request that is cancelled because
+			// the hedged request won is not
+			if !result.hedged {
+				hc.Record(result.latency)
+			}
+			if result.err == nil && result.statusFlags != controller.UpstreamTimeoutFlag {
+				winner := 0
+				if result.hedged {
+					winner = 1
+				}
+				for i, cancel := range cancels {
+					if i != winner {
+						cancel()
+					}
+				}
+				go discard(results, pending)
+				if result.resp.Body != nil {
+					result.resp.Body = &cancelBody{ReadCloser: result.resp.Body, cancel: cancels[winner]}
+				} else {
+					cancels[winner]()
+				}
+				return result.resp, nil, result.statusFlags, result.hedged
+			}
+		case <-timer.C:
+			if !sent && hc.Allow() && send(true) {
+				pending++
+			}
+			sent = true
+		}
+	}
+	for _, cancel := range cancels {
+		cancel()
+	}
+	return result.resp, result.err, result.statusFlags, result.hedged
+}
+
+// discard - close the responses of cancelled requests
+func discard(results chan hedgeResult, pending int) {
+	for i := 0; i < pending; i++ {
+		r := <-results
+		if r.err == nil && r.resp != nil && r.resp.Body != nil {
+			r.resp.Body.Close()
+		}
+	}
+}
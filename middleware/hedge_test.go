@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gotemplates/host/controller"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+)
+
+func Example_hedgedExchange() {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The original request is slow, the hedged request is not
+		if atomic.AddInt32(&count, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(time.Second):
+			}
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	t := controller.NewEgressTable()
+	t.AddController(controller.NewRoute("hedge-route", controller.EgressTraffic, "", false, controller.NewHedgeConfig(time.Millisecond*20, 0, 100)))
+	hc, _ := t.LookupByName("hedge-route").Hedge()
+
+	w := &controllerWrapper{rt: http.DefaultTransport}
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err, _, hedged := w.hedgedExchange(nil, hc, req)
+	buf, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	fmt.Printf("test: hedgedExchange() -> [status_code:%v] [err:%v] [hedged:%v] [body:%v]\n", resp.StatusCode, err, hedged, string(buf))
+
+	resp, err, _, hedged = w.hedgedExchange(nil, hc, req)
+	resp.Body.Close()
+	fmt.Printf("test: hedgedExchange() -> [status_code:%v] [err:%v] [hedged:%v]\n", resp.StatusCode, err, hedged)
+
+	fmt.Printf("test: IsHedgeableMethod() -> [GET:%v] [PUT:%v] [POST:%v]\n", hc.IsHedgeableMethod("GET"), hc.IsHedgeableMethod("PUT"), hc.IsHedgeableMethod("POST"))
+
+	//Output:
+	//test: hedgedExchange() -> [status_code:200] [err:<nil>] [hedged:true] [body:hello]
+	//test: hedgedExchange() -> [status_code:200] [err:<nil>] [hedged:false]
+	//test: IsHedgeableMethod() -> [GET:true] [PUT:true] [POST:false]
+
+}
+
+func Example_hedgedExchange_timeout() {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The original request times out before the hedged request completes
+		wait := time.Millisecond * 100
+		if atomic.AddInt32(&count, 1) == 1 {
+			wait = time.Second
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(wait):
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	t := controller.NewEgressTable()
+	t.AddController(controller.NewRoute("hedge-route", controller.EgressTraffic, "", false, controller.NewTimeoutConfig(time.Millisecond*150, 504), controller.NewHedgeConfig(time.Millisecond*100, 0, 100)))
+	ctrl := t.LookupByName("hedge-route")
+	tc, _ := ctrl.Timeout()
+	hc, _ := ctrl.Hedge()
+
+	w := &controllerWrapper{rt: http.DefaultTransport}
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err, flags, hedged := w.hedgedExchange(tc, hc, req)
+	resp.Body.Close()
+	fmt.Printf("test: hedgedExchange() -> [status_code:%v] [err:%v] [flags:%v] [hedged:%v]\n", resp.StatusCode, err, flags, hedged)
+
+	//Output:
+	//test: hedgedExchange() -> [status_code:200] [err:<nil>] [flags:] [hedged:true]
+
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gotemplates/host/controller"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// mirrorRequest - a copy of the request for the mirror url, with the copy of the request body if the body is not
+// buffered
+type mirrorRequest struct {
+	req    *http.Request
+	body   *bodyCopy
+	cancel context.CancelFunc
+}
+
+// newMirrorRequest - create a copy of the request for the mirror url. The copy has its own context so that it is not
+// cancelled with the original request. A body that is not buffered is not read here, it is copied as it is read
+// for the original request.
+func newMirrorRequest(mc controller.Mirror, req *http.Request) *mirrorRequest {
+	// The original url is returned when the mirror url cannot be built, which would send a duplicate to the primary
+	uri := mc.BuildUrl(req.URL)
+	if uri == nil || uri == req.URL {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mc.Timeout())
+	m := &mirrorRequest{cancel: cancel}
+	m.req = req.Clone(ctx)
+	m.req.URL = uri
+	m.req.Host = m.req.URL.Host
+	m.req.RequestURI = ""
+	switch {
+	case req.Body == nil || req.Body == http.NoBody:
+	case req.GetBody != nil:
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil
+		}
+		m.req.Body = body
+	default:
+		m.body = &bodyCopy{max: mc.MaxBodySize(), done: make(chan struct{})}
+		req.Body = &teeBody{ReadCloser: req.Body, copy: m.body}
+		m.req.Body = http.NoBody
+	}
+	return m
+}
+
+// bodyCopy - the copy of a request body, complete once the original body has been read
+type bodyCopy struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	max  int64
+	over bool
+	ok   bool
+	once sync.Once
+	done chan struct{}
+}
+
+func (c *bodyCopy) write(p []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.over {
+		return
+	}
+	if int64(c.buf.Len()+len(p)) > c.max {
+		c.over = true
+		c.buf = bytes.Buffer{}
+		return
+	}
+	c.buf.Write(p)
+}
+
+func (c *bodyCopy) finish(eof bool) {
+	c.once.Do(func() {
+		c.mu.Lock()
+		c.ok = eof && !c.over
+		c.mu.Unlock()
+		close(c.done)
+	})
+}
+
+// wait - wait until the original body has been read, returns false if the body is larger than the maximum, or the
+// original body was closed before it was read
+func (c *bodyCopy) wait(ctx context.Context) ([]byte, bool) {
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.Bytes(), c.ok
+}
+
+// teeBody - the original request body, the body is copied as it is read
+type teeBody struct {
+	io.ReadCloser
+	copy *bodyCopy
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.copy.write(p[:n])
+	if err != nil {
+		b.copy.finish(err == io.EOF)
+	}
+	return n, err
+}
+
+func (b *teeBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.copy.finish(false)
+	return err
+}
+
+// mirror - send the mirrored request, the response is discarded. The request is dropped if the body could not
+// be copied
+func (w *controllerWrapper) mirror(ctrl controller.Controller, mc controller.Mirror, m *mirrorRequest) {
+	defer mc.Release()
+	defer m.cancel()
+	req := m.req
+	if m.body != nil {
+		buf, ok := m.body.wait(req.Context())
+		if !ok {
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(buf))
+	}
+	start := time.Now().UTC()
+	statusFlags := ""
+	resp, err := w.rt.RoundTrip(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			statusFlags = controller.UpstreamTimeoutFlag
+		}
+		resp = &http.Response{Request: req, StatusCode: http.StatusServiceUnavailable}
+	} else if resp.Body != nil {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	ctrl.LogHttpMirror(start, time.Since(start), req, resp, statusFlags)
+}
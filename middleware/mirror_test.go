@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/gotemplates/host/controller"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func Example_mirror() {
+	t := controller.NewEgressTable()
+	t.AddController(controller.NewRoute("mirror-route", controller.EgressTraffic, "", false, controller.NewMirrorConfig("http://mirror.test", 100)))
+	ctrl := t.LookupByName("mirror-route")
+	mc, _ := ctrl.Mirror()
+
+	ctx, cancelReq := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "POST", "http://primary.test/search?q=golang", strings.NewReader("payload"))
+	m := newMirrorRequest(mc, req)
+	// Cancelling the original request does not cancel the mirrored request
+	cancelReq()
+
+	// The mirror url cannot be built from the decoded path, so the request is not mirrored to the primary
+	bad, _ := http.NewRequest("GET", "http://primary.test/%25zz", nil)
+	fmt.Printf("test: newMirrorRequest(invalid) -> [mirrored:%v]\n", newMirrorRequest(mc, bad) != nil)
+
+	w := &controllerWrapper{rt: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		buf, _ := io.ReadAll(r.Body)
+		fmt.Printf("test: RoundTrip() -> [url:%v] [body:%v] [ctx:%v]\n", r.URL, string(buf), r.Context().Err())
+		return &http.Response{Request: r, StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("discarded"))}, nil
+	})}
+	mc.Acquire()
+	w.mirror(ctrl, mc, m)
+
+	buf, _ := io.ReadAll(req.Body)
+	fmt.Printf("test: Body() -> [body:%v]\n", string(buf))
+
+	//Output:
+	//test: newMirrorRequest(invalid) -> [mirrored:false]
+	//test: RoundTrip() -> [url:http://mirror.test/search?q=golang] [body:payload] [ctx:<nil>]
+	//test: Write() -> [{"traffic":"mirror","route_name":"mirror-route","method":"POST","host":"mirror.test","path":"/search","protocol":"HTTP/1.1","status_code":200,"status_flags":"","bytes_received":-1,"bytes_sent":0,"timeout_ms":-1,"rate-limit":-1,"rate-burst":-1,"retry":,"retry-rate-limit":,"retry-rate-burst":,"failover":,"proxy":}]
+	//test: Body() -> [body:payload]
+
+}
+
+func Example_mirror_streamed() {
+	t := controller.NewEgressTable()
+	config := controller.NewMirrorConfig("http://mirror.test", 100)
+	config.MaxConcurrent = 1
+	t.AddController(controller.NewRoute("mirror-route", controller.EgressTraffic, "", false, config))
+	ctrl := t.LookupByName("mirror-route")
+	mc, _ := ctrl.Mirror()
+	w := &controllerWrapper{rt: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		buf, _ := io.ReadAll(r.Body)
+		fmt.Printf("test: RoundTrip() -> [url:%v] [body:%v]\n", r.URL, string(buf))
+		return &http.Response{Request: r, StatusCode: http.StatusOK}, nil
+	})}
+
+	// The body is not read until it is read for the original request
+	req, _ := http.NewRequest("POST", "http://primary.test/upload", io.NopCloser(strings.NewReader("streamed")))
+	fmt.Printf("test: Acquire() -> [%v]\n", mc.Acquire())
+	fmt.Printf("test: Acquire(limit) -> [%v]\n", mc.Acquire())
+	m := newMirrorRequest(mc, req)
+	done := make(chan struct{})
+	go func() {
+		w.mirror(ctrl, mc, m)
+		close(done)
+	}()
+	buf, _ := io.ReadAll(req.Body)
+	req.Body.Close()
+	<-done
+	fmt.Printf("test: Body() -> [body:%v]\n", string(buf))
+
+	// The original body is closed before it is read, so the request is not mirrored
+	req, _ = http.NewRequest("POST", "http://primary.test/upload", io.NopCloser(strings.NewReader("streamed")))
+	fmt.Printf("test: Acquire(released) -> [%v]\n", mc.Acquire())
+	m = newMirrorRequest(mc, req)
+	req.Body.Close()
+	w.mirror(ctrl, mc, m)
+	fmt.Printf("test: mirror(closed) -> [acquire:%v]\n", mc.Acquire())
+
+	//Output:
+	//test: Acquire() -> [true]
+	//test: Acquire(limit) -> [false]
+	//test: RoundTrip() -> [url:http://mirror.test/upload] [body:streamed]
+	//test: Write() -> [{"traffic":"mirror","route_name":"mirror-route","method":"POST","host":"mirror.test","path":"/upload","protocol":"HTTP/1.1","status_code":200,"status_flags":"","bytes_received":-1,"bytes_sent":0,"timeout_ms":-1,"rate-limit":-1,"rate-burst":-1,"retry":,"retry-rate-limit":,"retry-rate-burst":,"failover":,"proxy":}]
+	//test: Body() -> [body:streamed]
+	//test: Acquire(released) -> [true]
+	//test: mirror(closed) -> [acquire:true]
+
+}
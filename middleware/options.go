@@ -3,6 +3,7 @@ package middleware
 import (
 	"github.com/gotemplates/host/accessdata"
 	"github.com/gotemplates/host/accesslog"
+	"sync/atomic"
 )
 
 // SetAccessLogFn - allows setting an application configured logging function
@@ -17,3 +18,17 @@ var logFn = defaultLogFn
 var defaultLogFn = func(e *accessdata.Entry) {
 	accesslog.Write[accesslog.LogOutputHandler, accessdata.JsonFormatter](e)
 }
+
+// SetShedder - allows setting an ingress load shedder, nil disables shedding. The shedder can be set while
+// requests are being served
+func SetShedder(s *Shedder) {
+	shedder.Store(s)
+}
+
+// shedder - the current *Shedder, which is nil if shedding is disabled
+var shedder atomic.Value
+
+func loadShedder() *Shedder {
+	s, _ := shedder.Load().(*Shedder)
+	return s
+}
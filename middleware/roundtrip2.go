@@ -1,21 +1,29 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"github.com/gotemplates/host/controller"
+	"github.com/gotemplates/host/tracing"
+	"io"
 	"net/http"
+	"net/url"
 	"time"
 )
 
+const (
+	maxDrain = 4096
+)
+
 type controllerWrapper struct {
 	rt http.RoundTripper
 }
 
 // RoundTrip - implementation of the RoundTrip interface for a transport, also logs an access entry
-func (w *controllerWrapper) RoundTrip(req *http.Request) (*http.Response, error) {
+func (w *controllerWrapper) RoundTrip(req *http.Request) (resp *http.Response, err error) {
 	var start = time.Now().UTC()
-	var retry = false
+	var attempt = 1
 
 	// !panic
 	if w == nil || w.rt == nil {
@@ -23,35 +31,250 @@ func (w *controllerWrapper) RoundTrip(req *http.Request) (*http.Response, error)
 	}
 	ctrl := controller.EgressTable.LookupHttp(req)
 	ctrl.UpdateHeaders(req)
-	if rlc, ok := ctrl.RateLimiter(); ok && !rlc.Allow() {
-		resp := &http.Response{Request: req, StatusCode: rlc.StatusCode()}
-		ctrl.LogHttpEgress(start, time.Since(start), req, resp, controller.RateLimitFlag, false)
+	// Each attempt has a span, so that retries are visible in the trace. The first span is started before the
+	// admission checks, so that a rejected request is traced
+	parent := req.Context()
+	req, span := startSpan(parent, ctrl, controller.EgressTraffic, tracing.ClientKind, req)
+	if rlc, ok := ctrl.RateLimiter(); ok && !allow(rlc, req) {
+		resp := &http.Response{Request: req, StatusCode: rlc.StatusCode(), Header: rlc.Headers(req)}
+		ctrl.LogHttpEgress(start, time.Since(start), req, resp, controller.RateLimitFlag, controller.EgressStatus{Attempt: attempt})
+		finishSpan(span, resp.StatusCode, nil)
+		return resp, nil
+	}
+	// The breaker is checked first, so that a request rejected by an open circuit does not wait for a slot. A request
+	// rejected after it is allowed is cancelled, without an outcome
+	cbc, breaker := ctrl.CircuitBreaker()
+	probe := false
+	if breaker {
+		var ok bool
+		if ok, probe = cbc.Allow(); !ok {
+			resp := &http.Response{Request: req, StatusCode: cbc.StatusCode()}
+			ctrl.LogHttpEgress(start, time.Since(start), req, resp, controller.CircuitOpenFlag, controller.EgressStatus{Attempt: attempt})
+			finishSpan(span, resp.StatusCode, nil)
+			return resp, nil
+		}
+	}
+	clc, limited := ctrl.ConcurrencyLimiter()
+	if limited && !clc.Acquire() {
+		if breaker {
+			cbc.Cancel(probe)
+		}
+		resp := &http.Response{Request: req, StatusCode: clc.StatusCode()}
+		ctrl.LogHttpEgress(start, time.Since(start), req, resp, controller.ConcurrencyFlag, controller.EgressStatus{Attempt: attempt})
+		finishSpan(span, resp.StatusCode, nil)
 		return resp, nil
 	}
-	if pc, ok := ctrl.Proxy(); ok && pc.IsEnabled() {
-		req.URL = pc.BuildUrl(req.URL)
-		if req.URL != nil {
-			req.Host = req.URL.Host
+	if bhc, ok := ctrl.Bulkhead(); ok {
+		if !bhc.Acquire(req.Context()) {
+			if limited {
+				clc.Cancel()
+			}
+			if breaker {
+				cbc.Cancel(probe)
+			}
+			resp := &http.Response{Request: req, StatusCode: bhc.StatusCode()}
+			ctrl.LogHttpEgress(start, time.Since(start), req, resp, controller.BulkheadFlag, controller.EgressStatus{Attempt: attempt})
+			finishSpan(span, resp.StatusCode, nil)
+			return resp, nil
 		}
+		defer bhc.Release()
+	}
+	// A request cancelled by the client is not an outcome of the upstream, so the limit is not adjusted
+	if limited {
+		defer func(ctx context.Context, begin time.Time) {
+			if ctx.Err() != nil {
+				clc.Cancel()
+				return
+			}
+			clc.Release(time.Since(begin), err == nil && resp.StatusCode < http.StatusInternalServerError)
+		}(req.Context(), start)
+	}
+	// A mirrored request is dropped when the mirror is at the limit of in-flight requests
+	if mc, ok := ctrl.Mirror(); ok && mc.Allow() && mc.Acquire() {
+		if m := newMirrorRequest(mc, req); m != nil {
+			go w.mirror(ctrl, mc, m)
+		} else {
+			mc.Release()
+		}
+	}
+	status := controller.EgressStatus{Attempt: attempt, Begin: start}
+	sel := newEndpointSelector(ctrl, req)
+	sel.next(req, &status)
+	if req.URL != nil {
+		span.SetAttribute(UrlAttribute, req.URL.String())
 	}
 	tc, _ := ctrl.Timeout()
-	resp, err, statusFlags := w.exchange(tc, req)
-	if err != nil {
-		return resp, err
+	rc, retry := ctrl.Retry()
+	replayable := true
+	if retry && rc.IsEnabled() && rc.IsRetryableMethod(req.Method, req.Header.Get(controller.IdempotencyKeyHeaderName)) {
+		replayable = bufferBody(req, rc.MaxBodySize())
 	}
-	if rc, ok := ctrl.Retry(); ok {
-		prevFlags := statusFlags
-		retry, statusFlags = rc.IsRetryable(resp.StatusCode)
-		if retry {
-			ctrl.LogHttpEgress(start, time.Since(start), req, resp, prevFlags, false)
+	hc, _ := ctrl.Hedge()
+	var statusFlags string
+	resp, err, statusFlags, status.Hedged = w.hedgedExchange(tc, hc, req)
+	canceled := false
+	if retry {
+		// The previous wait is used by the decorrelated backoff
+		var wait time.Duration
+		for ; ; attempt++ {
+			var ok bool
+			var flags string
+			ok, wait, flags = rc.IsRetryableResponse(attempt, wait, req, resp, err)
+			if !ok {
+				if flags != "" {
+					statusFlags = flags
+					status.RetryStatus = flags
+				}
+				break
+			}
+			if !replayable || rewindBody(req) != nil {
+				statusFlags = controller.NotReplayableFlag
+				status.RetryStatus = statusFlags
+				break
+			}
+			// The attempt is only released once another attempt is certain
+			if !sleep(req.Context(), wait) {
+				canceled = true
+				break
+			}
+			if err == nil {
+				status.Retried = true
+				ctrl.LogHttpEgress(start, time.Since(start), req, resp, statusFlags, status)
+				drain(resp)
+			}
+			finishSpan(span, responseStatusCode(resp), err)
+			sel.done(resp, err)
 			start = time.Now()
-			resp, err, statusFlags = w.exchange(tc, req)
+			status.Attempt = attempt + 1
+			status.Retried = false
+			sel.next(req, &status)
+			req, span = startSpan(parent, ctrl, controller.EgressTraffic, tracing.ClientKind, req)
+			resp, err, statusFlags, status.Hedged = w.hedgedExchange(tc, hc, req)
 		}
 	}
-	ctrl.LogHttpEgress(start, time.Since(start), req, resp, statusFlags, retry)
+	sel.done(resp, err)
+	if breaker {
+		cbc.Record(probe, err == nil && resp.StatusCode < http.StatusInternalServerError)
+	}
+	if fc, ok := ctrl.Failover(); ok {
+		fc.Record(err != nil || resp.StatusCode >= http.StatusInternalServerError, statusFlags == controller.UpstreamTimeoutFlag)
+	}
+	if canceled {
+		if err == nil {
+			ctrl.LogHttpEgress(start, time.Since(start), req, resp, statusFlags, status)
+			drain(resp)
+		}
+		finishSpan(span, responseStatusCode(resp), req.Context().Err())
+		return nil, req.Context().Err()
+	}
+	if err != nil {
+		finishSpan(span, 0, err)
+		return resp, err
+	}
+	ctrl.LogHttpEgress(start, time.Since(start), req, resp, statusFlags, status)
+	finishSpan(span, resp.StatusCode, nil)
 	return resp, err
 }
 
+// endpointSelector - each attempt is sent to a selected proxy endpoint, and the outcome is recorded for that endpoint
+type endpointSelector struct {
+	pc       controller.Proxy
+	uri      *url.URL
+	endpoint int
+}
+
+func newEndpointSelector(ctrl controller.Controller, req *http.Request) *endpointSelector {
+	pc, ok := ctrl.Proxy()
+	if !ok || !pc.IsEnabled() {
+		return nil
+	}
+	return &endpointSelector{pc: pc, uri: req.URL, endpoint: -1}
+}
+
+// next - select the endpoint of the next attempt
+func (s *endpointSelector) next(req *http.Request, status *controller.EgressStatus) {
+	if s == nil {
+		return
+	}
+	req.URL = s.uri
+	req.URL, s.endpoint, status.Branch = s.pc.Select(req)
+	status.Endpoint = ""
+	if s.endpoint >= 0 {
+		status.Endpoint = s.pc.Endpoints()[s.endpoint].Url
+	}
+	if req.URL != nil {
+		req.Host = req.URL.Host
+	}
+}
+
+// done - record the outcome of the attempt
+func (s *endpointSelector) done(resp *http.Response, err error) {
+	if s == nil {
+		return
+	}
+	s.pc.Done(s.endpoint, err == nil && resp.StatusCode < http.StatusInternalServerError)
+	s.endpoint = -1
+}
+
+// sleep - wait for the retry backoff, returns false if the request context is done
+func sleep(ctx context.Context, wait time.Duration) bool {
+	if wait <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// bufferBody - make the request body replayable, returns false if the body is larger than max and cannot be replayed
+func bufferBody(req *http.Request, max int64) bool {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return true
+	}
+	buf, err := io.ReadAll(io.LimitReader(req.Body, max+1))
+	if err != nil || int64(len(buf)) > max {
+		// Send what has been read, followed by the remainder of the original body
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(buf), req.Body), req.Body}
+		return false
+	}
+	req.Body.Close()
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return true
+}
+
+// rewindBody - reset the request body before a retry
+func rewindBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+// drain - release the connection of a response that is being retried
+func drain(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrain))
+	resp.Body.Close()
+}
+
 func (w *controllerWrapper) exchange(tc controller.Timeout, req *http.Request) (resp *http.Response, err error, statusFlags string) {
 	if tc == nil {
 		resp, err = w.rt.RoundTrip(req)
@@ -1,9 +1,13 @@
 package middleware
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/gotemplates/host/controller"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -14,6 +18,12 @@ var (
 	rateLimitRoute = "rate-limit-route"
 	retryRoute     = "retry-route"
 	proxyRoute     = "proxy-route"
+	cancelRoute    = "retry-cancel-route"
+	cancelUrl      = "https://www.retry-cancel.com"
+	endpointRoute  = "retry-endpoint-route"
+	endpointUrl    = "https://www.retry-endpoint.com"
+	limitRoute     = "concurrency-cancel-route"
+	limitUrl       = "https://www.concurrency-cancel.com"
 	//googleUrl      = "https://www.google.com/search?q=test"
 	twitterUrl  = "https://www.twitter.com"
 	facebookUrl = "https://www.facebook.com"
@@ -93,12 +103,25 @@ func init() {
 		if req.URL.String() == instagramUrl {
 			return proxyRoute, true
 		}
+		if req.URL.String() == cancelUrl {
+			return cancelRoute, true
+		}
+		if req.URL.String() == endpointUrl {
+			return endpointRoute, true
+		}
+		if req.URL.String() == limitUrl {
+			return limitRoute, true
+		}
 		return "", true
 	})
 
 	controller.EgressTable.AddController(controller.NewRoute(timeoutRoute, controller.EgressTraffic, "", false, controller.NewTimeoutConfig(time.Millisecond, 504)))
 	controller.EgressTable.AddController(controller.NewRoute(rateLimitRoute, controller.EgressTraffic, "", false, controller.NewRateLimiterConfig(2000, 0, 503)))
 	controller.EgressTable.AddController(controller.NewRoute(retryRoute, controller.EgressTraffic, "", false, controller.NewTimeoutConfig(time.Millisecond, 504), controller.NewRetryConfig([]int{503, 504}, 0, 0, 0)))
+	controller.EgressTable.AddController(controller.NewRoute(cancelRoute, controller.EgressTraffic, "", false, controller.NewRetryConfig([]int{503}, 100, 10, time.Second)))
+	controller.EgressTable.AddController(controller.NewRoute(endpointRoute, controller.EgressTraffic, "", false, controller.NewRetryConfig([]int{503}, 100, 10, 0),
+		&controller.ProxyConfig{Enabled: true, Endpoints: []controller.Endpoint{{Url: "http://host-a:8080"}, {Url: "http://host-b:8080"}}, MaxFailures: 1}))
+	controller.EgressTable.AddController(controller.NewRoute(limitRoute, controller.EgressTraffic, "", false, controller.NewConcurrencyLimiterConfig(controller.AIMDAlgorithm, 4, 1, 8, 503)))
 	controller.EgressTable.AddController(controller.NewRoute(proxyRoute, controller.EgressTraffic, "", false, controller.NewProxyConfig(true, googleUrl)))
 
 	controller.SetLogFn(testHttpLog)
@@ -251,6 +274,99 @@ func Example_Controller_Default_Retry() {
 
 }
 
+type cancelTransport struct {
+	cancel func()
+}
+
+// RoundTrip - the request is canceled while the response is returned, so the retry wait is canceled
+func (t cancelTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.cancel()
+	return &http.Response{Request: req, StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func Example_Controller_Retry_Canceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", cancelUrl, nil)
+	if c, ok := controller.EgressTable.LookupByName(cancelRoute).Retry(); ok {
+		c.Enable()
+	}
+
+	w := &controllerWrapper{rt: cancelTransport{cancel: cancel}}
+	resp, err := w.RoundTrip(req)
+	fmt.Printf("test: RoundTrip(canceled) -> [resp:%v] [err:%v]\n", resp != nil, err)
+
+	//Output:
+	//test: Write() -> [{"traffic":"egress","route_name":"retry-cancel-route","method":"GET","host":"www.retry-cancel.com","path":"","protocol":"HTTP/1.1","status_code":503,"status_flags":"","bytes_received":-1,"bytes_sent":0,"timeout_ms":-1,"rate-limit":-1,"rate-burst":-1,"retry":false,"retry-rate-limit":100,"retry-rate-burst":10,"failover":,"proxy":}]
+	//test: RoundTrip(canceled) -> [resp:false] [err:context canceled]
+
+}
+
+type canceledTransport struct {
+	cancel func()
+}
+
+// RoundTrip - the request is canceled by the client before a response
+func (t canceledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.cancel()
+	return nil, req.Context().Err()
+}
+
+func Example_Controller_ConcurrencyLimiter_Canceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", limitUrl, nil)
+	clc, _ := controller.EgressTable.LookupByName(limitRoute).ConcurrencyLimiter()
+
+	w := &controllerWrapper{rt: canceledTransport{cancel: cancel}}
+	_, err := w.RoundTrip(req)
+	fmt.Printf("test: RoundTrip(canceled) -> [err:%v] [limit:%v] [in-flight:%v]\n", err, clc.Limit(), clc.InFlight())
+
+	//Output:
+	//test: RoundTrip(canceled) -> [err:context canceled] [limit:4] [in-flight:0]
+
+}
+
+type endpointTransport struct{}
+
+// RoundTrip - host-a is unavailable
+func (t endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	code := http.StatusOK
+	if req.URL.Host == "host-a:8080" {
+		code = http.StatusServiceUnavailable
+	}
+	return &http.Response{Request: req, StatusCode: code, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func Example_Controller_Retry_Endpoint() {
+	req, _ := http.NewRequest("GET", endpointUrl, nil)
+	ctrl := controller.EgressTable.LookupByName(endpointRoute)
+	if c, ok := ctrl.Retry(); ok {
+		c.Enable()
+	}
+
+	w := &controllerWrapper{rt: endpointTransport{}}
+	resp, err := w.RoundTrip(req)
+	pc, _ := ctrl.Proxy()
+	fmt.Printf("test: RoundTrip(retry) -> [status_code:%v] [err:%v] [ejected:%v]\n", resp.StatusCode, err, pc.Ejected())
+
+	// The request is recorded once in the route metrics, with the retry
+	buf := new(bytes.Buffer)
+	controller.WriteMetrics(buf)
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, endpointRoute) && (strings.Contains(line, "xx\"} 1") || strings.Contains(line, "retries") || strings.Contains(line, "_count")) {
+			fmt.Printf("test: WriteMetrics() -> %v\n", line)
+		}
+	}
+
+	//Output:
+	//test: Write() -> [{"traffic":"egress","route_name":"retry-endpoint-route","method":"GET","host":"host-a:8080","path":"","protocol":"HTTP/1.1","status_code":503,"status_flags":"","bytes_received":-1,"bytes_sent":0,"timeout_ms":-1,"rate-limit":-1,"rate-burst":-1,"retry":false,"retry-rate-limit":100,"retry-rate-burst":10,"failover":,"proxy":true}]
+	//test: Write() -> [{"traffic":"egress","route_name":"retry-endpoint-route","method":"GET","host":"host-b:8080","path":"","protocol":"HTTP/1.1","status_code":200,"status_flags":"","bytes_received":-1,"bytes_sent":0,"timeout_ms":-1,"rate-limit":-1,"rate-burst":-1,"retry":true,"retry-rate-limit":100,"retry-rate-burst":10,"failover":,"proxy":true}]
+	//test: RoundTrip(retry) -> [status_code:200] [err:<nil>] [ejected:[http://host-a:8080]]
+	//test: WriteMetrics() -> host_route_requests_total{traffic="egress",route="retry-endpoint-route",status_class="2xx"} 1
+	//test: WriteMetrics() -> host_route_retries_total{traffic="egress",route="retry-endpoint-route"} 1
+	//test: WriteMetrics() -> host_route_request_duration_seconds_count{traffic="egress",route="retry-endpoint-route"} 1
+
+}
+
 func Example_Controller_Proxy() {
 	req, _ := http.NewRequest("GET", instagramUrl, nil)
 
@@ -266,3 +382,30 @@ func Example_Controller_Proxy() {
 	//test: RoundTrip(handler:true) -> [status_code:200] [err:<nil>]
 
 }
+
+func Example_bufferBody() {
+	req, _ := http.NewRequest("POST", facebookUrl, io.NopCloser(strings.NewReader("replayable body")))
+	ok := bufferBody(req, 64)
+	buf, _ := io.ReadAll(req.Body)
+	fmt.Printf("test: bufferBody(64) -> [replayable:%v] [body:%v]\n", ok, string(buf))
+
+	err := rewindBody(req)
+	buf, _ = io.ReadAll(req.Body)
+	fmt.Printf("test: rewindBody() -> [err:%v] [body:%v]\n", err, string(buf))
+
+	req, _ = http.NewRequest("POST", facebookUrl, io.NopCloser(strings.NewReader("body larger than max")))
+	ok = bufferBody(req, 4)
+	buf, _ = io.ReadAll(req.Body)
+	fmt.Printf("test: bufferBody(4) -> [replayable:%v] [body:%v]\n", ok, string(buf))
+
+	req, _ = http.NewRequest("GET", facebookUrl, nil)
+	ok = bufferBody(req, 4)
+	fmt.Printf("test: bufferBody(nil) -> [replayable:%v]\n", ok)
+
+	//Output:
+	//test: bufferBody(64) -> [replayable:true] [body:replayable body]
+	//test: rewindBody() -> [err:<nil>] [body:replayable body]
+	//test: bufferBody(4) -> [replayable:false] [body:body larger than max]
+	//test: bufferBody(nil) -> [replayable:true]
+
+}
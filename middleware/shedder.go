@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gotemplates/host/controller"
+	"math"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCPUInterval     = time.Second
+	DefaultLatencyHalfLife = time.Second
+
+	latencySmoothing = 0.1
+	clockTicks       = 100 // USER_HZ, the unit of the /proc CPU times
+)
+
+// shedPressure - the pressure at which each priority is shed, critical requests are never shed
+var shedPressure = []float64{controller.LowPriority: 1, controller.NormalPriority: 1.25, controller.HighPriority: 1.5}
+
+// ShedderConfig - thresholds for ingress load shedding, a threshold of 0 is not used. Low priority requests are
+// shed when a threshold is crossed, normal and high priority requests at 1.25 and 1.5 times the threshold
+type ShedderConfig struct {
+	MaxInFlight     int           // Requests in the handler
+	MaxQueueLatency time.Duration // Average wait before the application handler is called
+	MaxCPU          float64       // Process CPU usage, as a fraction of all CPUs
+	CPUInterval     time.Duration // CPU usage sample interval, 0 is DefaultCPUInterval
+	StatusCode      int
+}
+
+func NewShedderConfig(maxInFlight int, maxQueueLatency time.Duration, maxCPU float64, statusCode int) *ShedderConfig {
+	c := new(ShedderConfig)
+	c.MaxInFlight = maxInFlight
+	c.MaxQueueLatency = maxQueueLatency
+	c.MaxCPU = maxCPU
+	if statusCode <= 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.StatusCode = statusCode
+	return c
+}
+
+// Shedder - priority based load shedding for ingress traffic
+type Shedder struct {
+	config   ShedderConfig
+	mu       sync.Mutex
+	inFlight int
+	latency  float64 // Smoothed queue latency in nanoseconds
+	observed time.Time
+	cpu      float64
+	sampled  time.Time
+	cpuFn    func() float64
+}
+
+// NewShedder - create a shedder, at least one threshold is required
+func NewShedder(config *ShedderConfig) (*Shedder, error) {
+	if config == nil {
+		return nil, errors.New("invalid argument: shedder configuration is nil")
+	}
+	if config.MaxInFlight < 0 || config.MaxQueueLatency < 0 || config.MaxCPU < 0 || config.CPUInterval < 0 {
+		return nil, errors.New("invalid configuration: Shedder threshold is < 0")
+	}
+	if config.MaxCPU > 1 {
+		return nil, errors.New("invalid configuration: Shedder max CPU is > 1")
+	}
+	if config.MaxInFlight == 0 && config.MaxQueueLatency == 0 && config.MaxCPU == 0 {
+		return nil, errors.New("invalid configuration: Shedder has no thresholds")
+	}
+	s := new(Shedder)
+	s.config = *config
+	if s.config.CPUInterval == 0 {
+		s.config.CPUInterval = DefaultCPUInterval
+	}
+	if s.config.StatusCode <= 0 {
+		s.config.StatusCode = http.StatusServiceUnavailable
+	}
+	s.cpuFn = newCPUUsage()
+	return s, nil
+}
+
+func (s *Shedder) StatusCode() int {
+	return s.config.StatusCode
+}
+
+func (s *Shedder) InFlight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inFlight
+}
+
+// Pressure - the largest ratio of a signal to its threshold
+func (s *Shedder) Pressure() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pressure(time.Now())
+}
+
+// Acquire - determine if a request of the priority is admitted. An admitted request must be followed by a Release
+func (s *Shedder) Acquire(priority int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if priority < controller.LowPriority {
+		priority = controller.LowPriority
+	}
+	if priority < len(shedPressure) && s.pressure(time.Now()) >= shedPressure[priority] {
+		return false
+	}
+	s.inFlight++
+	return true
+}
+
+// Release - release a request admitted by Acquire
+func (s *Shedder) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight > 0 {
+		s.inFlight--
+	}
+}
+
+// Observe - record the time a request waited before the application handler was called
+func (s *Shedder) Observe(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.latency = s.decayedLatency(now)*(1-latencySmoothing) + float64(latency)*latencySmoothing
+	s.observed = now
+}
+
+func (s *Shedder) pressure(now time.Time) float64 {
+	p := 0.0
+	if s.config.MaxInFlight > 0 {
+		p = math.Max(p, float64(s.inFlight)/float64(s.config.MaxInFlight))
+	}
+	if s.config.MaxQueueLatency > 0 {
+		p = math.Max(p, s.decayedLatency(now)/float64(s.config.MaxQueueLatency))
+	}
+	if s.config.MaxCPU > 0 {
+		if now.Sub(s.sampled) >= s.config.CPUInterval {
+			s.cpu = s.cpuFn()
+			s.sampled = now
+		}
+		p = math.Max(p, s.cpu/s.config.MaxCPU)
+	}
+	return p
+}
+
+// decayedLatency - the smoothed latency halves for each DefaultLatencyHalfLife without an observation, so that
+// shedding all requests of a priority does not keep the latency from recovering
+func (s *Shedder) decayedLatency(now time.Time) float64 {
+	if s.observed.IsZero() {
+		return s.latency
+	}
+	return s.latency * math.Pow(0.5, float64(now.Sub(s.observed))/float64(DefaultLatencyHalfLife))
+}
+
+// newCPUUsage - process CPU usage between calls, as a fraction of all CPUs. Usage is 0 where /proc is not available
+func newCPUUsage() func() float64 {
+	prevTicks, _ := processTicks()
+	prev := time.Now()
+	return func() float64 {
+		ticks, ok := processTicks()
+		now := time.Now()
+		elapsed := now.Sub(prev).Seconds()
+		if !ok || elapsed <= 0 {
+			return 0
+		}
+		usage := float64(ticks-prevTicks) / clockTicks / elapsed / float64(runtime.NumCPU())
+		prevTicks, prev = ticks, now
+		return usage
+	}
+}
+
+// processTicks - user and system CPU time of the process, from /proc/self/stat
+func processTicks() (int64, bool) {
+	buf, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return 0, false
+	}
+	// The command name can contain spaces, so the fields are counted from its closing parenthesis
+	i := strings.LastIndexByte(string(buf), ')')
+	if i < 0 {
+		return 0, false
+	}
+	fields := strings.Fields(string(buf[i+1:]))
+	if len(fields) < 13 {
+		return 0, false
+	}
+	utime, err1 := strconv.ParseInt(fields[11], 10, 64)
+	stime, err2 := strconv.ParseInt(fields[12], 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+	return utime + stime, true
+}
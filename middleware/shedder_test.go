@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gotemplates/host/controller"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+func ExampleNewShedder() {
+	_, err := NewShedder(nil)
+	fmt.Printf("test: NewShedder(nil) -> [err:%v]\n", err)
+
+	_, err = NewShedder(NewShedderConfig(0, 0, 0, 0))
+	fmt.Printf("test: NewShedder() -> [err:%v]\n", err)
+
+	_, err = NewShedder(NewShedderConfig(0, 0, 2, 0))
+	fmt.Printf("test: NewShedder(cpu) -> [err:%v]\n", err)
+
+	s, err := NewShedder(NewShedderConfig(10, 0, 0, 0))
+	fmt.Printf("test: NewShedder(10) -> [err:%v] [status:%v] [cpu-interval:%v]\n", err, s.StatusCode(), s.config.CPUInterval)
+
+	//Output:
+	//test: NewShedder(nil) -> [err:invalid argument: shedder configuration is nil]
+	//test: NewShedder() -> [err:invalid configuration: Shedder has no thresholds]
+	//test: NewShedder(cpu) -> [err:invalid configuration: Shedder max CPU is > 1]
+	//test: NewShedder(10) -> [err:<nil>] [status:503] [cpu-interval:1s]
+
+}
+
+func ExampleShedder_Acquire() {
+	s, _ := NewShedder(NewShedderConfig(4, 0, 0, 0))
+	for i := 0; i < 4; i++ {
+		s.Acquire(controller.CriticalPriority)
+	}
+	fmt.Printf("test: Acquire() -> [in-flight:%v] [pressure:%v]\n", s.InFlight(), s.Pressure())
+	fmt.Printf("test: Acquire(low) -> %v\n", s.Acquire(controller.LowPriority))
+	fmt.Printf("test: Acquire(normal) -> %v\n", s.Acquire(controller.NormalPriority))
+	fmt.Printf("test: Acquire(high) -> %v\n", s.Acquire(controller.HighPriority))
+	fmt.Printf("test: Acquire(normal) -> [in-flight:%v] [ok:%v]\n", s.InFlight(), s.Acquire(controller.NormalPriority))
+	fmt.Printf("test: Acquire(critical) -> %v\n", s.Acquire(controller.CriticalPriority))
+
+	for s.InFlight() > 4 {
+		s.Release()
+	}
+	fmt.Printf("test: Release() -> [in-flight:%v] [low:%v]\n", s.InFlight(), s.Acquire(controller.LowPriority))
+
+	//Output:
+	//test: Acquire() -> [in-flight:4] [pressure:1]
+	//test: Acquire(low) -> false
+	//test: Acquire(normal) -> true
+	//test: Acquire(high) -> true
+	//test: Acquire(normal) -> [in-flight:6] [ok:false]
+	//test: Acquire(critical) -> true
+	//test: Release() -> [in-flight:4] [low:false]
+
+}
+
+func ExampleShedder_Signals() {
+	s, _ := NewShedder(NewShedderConfig(0, time.Millisecond*10, 0.5, 0))
+	cpu := 0.0
+	s.cpuFn = func() float64 { return cpu }
+	s.config.CPUInterval = time.Nanosecond
+
+	s.Observe(time.Millisecond * 120)
+	fmt.Printf("test: Observe(120ms) -> [low:%v] [high:%v]\n", s.Acquire(controller.LowPriority), s.Acquire(controller.HighPriority))
+	s.Release()
+
+	s.latency = 0
+	cpu = 0.6
+	fmt.Printf("test: cpu(0.6) -> [low:%v] [normal:%v] [high:%v]\n", s.Acquire(controller.LowPriority), s.Acquire(controller.NormalPriority), s.Acquire(controller.HighPriority))
+
+	cpu = 0.1
+	fmt.Printf("test: cpu(0.1) -> [low:%v]\n", s.Acquire(controller.LowPriority))
+
+	s.latency = float64(time.Millisecond * 100)
+	s.observed = time.Now().Add(-DefaultLatencyHalfLife * 10)
+	fmt.Printf("test: decayedLatency() -> [low:%v]\n", s.Acquire(controller.LowPriority))
+
+	//Output:
+	//test: Observe(120ms) -> [low:false] [high:true]
+	//test: cpu(0.6) -> [low:false] [normal:true] [high:true]
+	//test: cpu(0.1) -> [low:true]
+	//test: decayedLatency() -> [low:true]
+
+}
+
+func ExampleControllerHttpHostMetricsHandler_Shedder() {
+	name := "shed-route"
+	route := controller.NewRoute(name, controller.IngressTraffic, "", false, controller.NewPriorityConfig(controller.LowPriorityName, ""))
+	route.Pattern = "www.google.com/shed"
+	controller.IngressTable.AddController(route)
+
+	s, _ := NewShedder(NewShedderConfig(1, 0, 0, 429))
+	SetShedder(s)
+	defer SetShedder(nil)
+	s.Acquire(controller.CriticalPriority)
+
+	h := ControllerHttpHostMetricsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "")
+	req, _ := http.NewRequest("GET", "https://www.google.com/shed", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	fmt.Printf("test: ServeHTTP(shed) -> [status:%v] [in-flight:%v]\n", rec.Code, s.InFlight())
+
+	s.Release()
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	fmt.Printf("test: ServeHTTP() -> [status:%v] [in-flight:%v]\n", rec.Code, s.InFlight())
+
+	//Output:
+	//test: Write() -> [{"traffic":"ingress","route_name":"shed-route","method":"GET","host":"www.google.com","path":"/shed","protocol":"HTTP/1.1","status_code":429,"status_flags":"LS","bytes_received":-1,"bytes_sent":0,"timeout_ms":-1,"rate-limit":-1,"rate-burst":-1,"retry":,"retry-rate-limit":,"retry-rate-burst":,"failover":,"proxy":}]
+	//test: ServeHTTP(shed) -> [status:429] [in-flight:1]
+	//test: Write() -> [{"traffic":"ingress","route_name":"shed-route","method":"GET","host":"www.google.com","path":"/shed","protocol":"HTTP/1.1","status_code":200,"status_flags":"","bytes_received":-1,"bytes_sent":0,"timeout_ms":-1,"rate-limit":-1,"rate-burst":-1,"retry":,"retry-rate-limit":,"retry-rate-burst":,"failover":,"proxy":}]
+	//test: ServeHTTP() -> [status:200] [in-flight:0]
+
+}
+
+func ExampleSetShedder() {
+	s, _ := NewShedder(NewShedderConfig(1, 0, 0, 429))
+	defer SetShedder(nil)
+	fmt.Printf("test: loadShedder() -> [nil:%v]\n", loadShedder() == nil)
+
+	// The shedder is read by the handler while it is being set
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetShedder(s)
+		}()
+		go func() {
+			defer wg.Done()
+			loadShedder()
+		}()
+	}
+	wg.Wait()
+	fmt.Printf("test: SetShedder() -> [set:%v]\n", loadShedder() == s)
+
+	SetShedder(nil)
+	fmt.Printf("test: SetShedder(nil) -> [nil:%v]\n", loadShedder() == nil)
+
+	//Output:
+	//test: loadShedder() -> [nil:true]
+	//test: SetShedder() -> [set:true]
+	//test: SetShedder(nil) -> [nil:true]
+
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"github.com/gotemplates/host/controller"
+	"github.com/gotemplates/host/tracing"
+	"net/http"
+	"strconv"
+)
+
+const (
+	MethodAttribute = "http.method"
+	UrlAttribute    = "http.url"
+)
+
+// startSpan - start a span for the request, the request returned has the span in its context. The span is tagged
+// with the route name and the controller state, and the trace context is injected into a copy of the request headers
+// for a client span
+func startSpan(ctx context.Context, ctrl controller.Controller, traffic string, kind tracing.Kind, req *http.Request) (*http.Request, *tracing.Span) {
+	ctx, span := tracing.Start(ctx, traffic+" "+ctrl.Name(), kind)
+	span.SetAttribute(MethodAttribute, req.Method)
+	if req.URL != nil {
+		span.SetAttribute(UrlAttribute, req.URL.String())
+	}
+	span.SetAttribute(controller.RouteNameAttribute, ctrl.Name())
+	for k, v := range ctrl.State() {
+		if k != controller.ControllerName {
+			span.SetAttribute(controller.StateAttributePrefix+k, v)
+		}
+	}
+	req = req.WithContext(ctx)
+	if kind == tracing.ClientKind {
+		// A round tripper must not change the request of the caller
+		req.Header = req.Header.Clone()
+		if req.Header == nil {
+			req.Header = make(http.Header)
+		}
+		tracing.Inject(ctx, req.Header)
+	}
+	return req, span
+}
+
+// finishSpan - end the span, with the response status code if there is a response. An error is a span error
+func finishSpan(span *tracing.Span, statusCode int, err error) {
+	if statusCode > 0 {
+		span.SetAttribute(controller.StatusCodeAttribute, strconv.Itoa(statusCode))
+	}
+	if err != nil {
+		span.SetError(true)
+		span.SetAttribute("error", err.Error())
+	}
+	span.Finish()
+}
+
+func responseStatusCode(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
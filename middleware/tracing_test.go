@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/gotemplates/host/controller"
+	"github.com/gotemplates/host/tracing"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+type testExporter struct {
+	mu    sync.Mutex
+	spans []*tracing.Span
+}
+
+func (e *testExporter) Export(ctx context.Context, spans []*tracing.Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func Example_tracing() {
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get(tracing.TraceParentHeader)
+	}))
+	defer server.Close()
+
+	e := new(testExporter)
+	tracing.SetExporter(e, tracing.NewBatchConfig(0, 0, time.Hour))
+	defer tracing.Shutdown(context.Background())
+	controller.SetLogFn(func(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, statusFlags string, controllerState map[string]string) {
+	})
+	defer controller.SetLogFn(testHttpLog)
+
+	// The egress request is made while handling an ingress request, with a remote parent
+	var header http.Header
+	h := ControllerHttpHostMetricsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequestWithContext(r.Context(), "GET", server.URL, nil)
+		wrapper := &controllerWrapper{rt: http.DefaultTransport}
+		resp, err := wrapper.RoundTrip(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		header = req.Header
+		w.WriteHeader(http.StatusAccepted)
+	}), "")
+	r := httptest.NewRequest("GET", "http://localhost/search", nil)
+	r.Header.Set(tracing.TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	tracing.Flush(context.Background())
+
+	client, ingress := e.spans[0], e.spans[1]
+	sc, _ := tracing.ParseTraceParent(traceParent)
+	fmt.Printf("test: RoundTrip() -> [propagated:%v] [request-header:%v]\n", sc.SpanID == client.Context.SpanID, header.Get(tracing.TraceParentHeader))
+	fmt.Printf("test: ServerSpan() -> [name:%v] [trace:%v] [parent:%v]\n", ingress.Name, ingress.Context.TraceID, ingress.Parent)
+	fmt.Printf("test: ClientSpan() -> [name:%v] [trace:%v] [parent:%v]\n", client.Name, client.Context.TraceID, client.Parent == ingress.Context.SpanID)
+	for _, span := range []*tracing.Span{ingress, client} {
+		route, _ := span.Attribute(controller.RouteNameAttribute)
+		code, _ := span.Attribute(controller.StatusCodeAttribute)
+		method, _ := span.Attribute(MethodAttribute)
+		fmt.Printf("test: Attributes() -> [route:%v] [status_code:%v] [method:%v] [error:%v]\n", route, code, method, span.Error)
+	}
+
+	//Output:
+	//test: RoundTrip() -> [propagated:true] [request-header:]
+	//test: ServerSpan() -> [name:ingress *] [trace:4bf92f3577b34da6a3ce929d0e0e4736] [parent:00f067aa0ba902b7]
+	//test: ClientSpan() -> [name:egress *] [trace:4bf92f3577b34da6a3ce929d0e0e4736] [parent:true]
+	//test: Attributes() -> [route:*] [status_code:202] [method:GET] [error:false]
+	//test: Attributes() -> [route:*] [status_code:200] [method:GET] [error:false]
+
+}
+
+func Example_tracing_rejected() {
+	e := new(testExporter)
+	tracing.SetExporter(e, tracing.NewBatchConfig(0, 0, time.Hour))
+	defer tracing.Shutdown(context.Background())
+	controller.SetLogFn(func(traffic string, start time.Time, duration time.Duration, req *http.Request, resp *http.Response, statusFlags string, controllerState map[string]string) {
+	})
+	defer controller.SetLogFn(testHttpLog)
+
+	// The request is rejected by the rate limiter, before it is sent
+	req, _ := http.NewRequest("GET", twitterUrl, nil)
+	wrapper := &controllerWrapper{rt: http.DefaultTransport}
+	resp, err := wrapper.RoundTrip(req)
+	tracing.Flush(context.Background())
+
+	span := e.spans[0]
+	route, _ := span.Attribute(controller.RouteNameAttribute)
+	code, _ := span.Attribute(controller.StatusCodeAttribute)
+	flags, _ := span.Attribute(controller.StatusFlagsAttribute)
+	limit, _ := span.Attribute(controller.StateAttributePrefix + controller.RateLimitName)
+	fmt.Printf("test: RoundTrip() -> [status_code:%v] [err:%v] [spans:%v]\n", resp.StatusCode, err, len(e.spans))
+	fmt.Printf("test: Attributes() -> [name:%v] [route:%v] [status_code:%v] [status_flags:%v] [rate_limit:%v]\n", span.Name, route, code, flags, limit)
+
+	//Output:
+	//test: RoundTrip() -> [status_code:503] [err:<nil>] [spans:1]
+	//test: Attributes() -> [name:egress rate-limit-route] [route:rate-limit-route] [status_code:503] [status_flags:RL] [rate_limit:2000]
+
+}
@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+
+	traceParentVersion = "00"
+	sampledFlag        = 0x01
+)
+
+// TraceID - W3C trace id
+type TraceID [16]byte
+
+// SpanID - W3C parent id, the id of a span
+type SpanID [8]byte
+
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// SpanContext - the trace context that is propagated between services
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+	State   string // tracestate, propagated without change
+	Remote  bool   // extracted from a request
+}
+
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+func (sc SpanContext) IsSampled() bool { return sc.Flags&sampledFlag != 0 }
+
+// TraceParent - the traceparent header value
+func (sc SpanContext) TraceParent() string {
+	return traceParentVersion + "-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// ParseTraceParent - parse a traceparent header value. Versions other than 00 are parsed as version 00, as the
+// specification requires, if the value has at least the version 00 fields
+func ParseTraceParent(s string) (SpanContext, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 55 || (len(s) > 55 && (strings.HasPrefix(s, traceParentVersion) || s[55] != '-')) {
+		return SpanContext{}, false
+	}
+	if s[2] != '-' || s[35] != '-' || s[52] != '-' || s[:2] == "ff" || !isLowerHex(s[:55]) {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	var flags [1]byte
+	hex.Decode(sc.TraceID[:], []byte(s[3:35]))
+	hex.Decode(sc.SpanID[:], []byte(s[36:52]))
+	hex.Decode(flags[:], []byte(s[53:55]))
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
+
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if c != '-' && (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+type spanContextKey struct{}
+
+type spanKey struct{}
+
+// Extract - add the trace context of the request headers to the context, the context is not changed if the headers
+// do not have a valid traceparent
+func Extract(ctx context.Context, h http.Header) context.Context {
+	if h == nil {
+		return ctx
+	}
+	sc, ok := ParseTraceParent(h.Get(TraceParentHeader))
+	if !ok {
+		return ctx
+	}
+	sc.State = strings.Join(h.Values(TraceStateHeader), ",")
+	sc.Remote = true
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// Inject - set the traceparent and tracestate headers from the span, or remote trace context, of the context
+func Inject(ctx context.Context, h http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if h == nil || !sc.IsValid() {
+		return
+	}
+	h.Set(TraceParentHeader, sc.TraceParent())
+	if sc.State != "" {
+		h.Set(TraceStateHeader, sc.State)
+	} else {
+		h.Del(TraceStateHeader)
+	}
+}
+
+// SpanContextFromContext - the context of the current span, or of the remote parent if there is no span
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if ctx == nil {
+		return SpanContext{}
+	}
+	if span, ok := ctx.Value(spanKey{}).(*Span); ok {
+		return span.Context
+	}
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+// FromContext - the current span, nil if there is none
+func FromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+func ExampleParseTraceParent() {
+	sc, ok := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	fmt.Printf("test: ParseTraceParent() -> [ok:%v] [trace:%v] [span:%v] [sampled:%v]\n", ok, sc.TraceID, sc.SpanID, sc.IsSampled())
+
+	sc, ok = ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	fmt.Printf("test: ParseTraceParent(not-sampled) -> [ok:%v] [sampled:%v]\n", ok, sc.IsSampled())
+
+	_, ok = ParseTraceParent("00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+	fmt.Printf("test: ParseTraceParent(zero-trace-id) -> [ok:%v]\n", ok)
+
+	_, ok = ParseTraceParent("00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01")
+	fmt.Printf("test: ParseTraceParent(upper-case) -> [ok:%v]\n", ok)
+
+	_, ok = ParseTraceParent("ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	fmt.Printf("test: ParseTraceParent(version-ff) -> [ok:%v]\n", ok)
+
+	_, ok = ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	fmt.Printf("test: ParseTraceParent(version-00-extra) -> [ok:%v]\n", ok)
+
+	sc, ok = ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	fmt.Printf("test: ParseTraceParent(version-01-extra) -> [ok:%v] [%v]\n", ok, sc.TraceParent())
+
+	//Output:
+	//test: ParseTraceParent() -> [ok:true] [trace:4bf92f3577b34da6a3ce929d0e0e4736] [span:00f067aa0ba902b7] [sampled:true]
+	//test: ParseTraceParent(not-sampled) -> [ok:true] [sampled:false]
+	//test: ParseTraceParent(zero-trace-id) -> [ok:false]
+	//test: ParseTraceParent(upper-case) -> [ok:false]
+	//test: ParseTraceParent(version-ff) -> [ok:false]
+	//test: ParseTraceParent(version-00-extra) -> [ok:false]
+	//test: ParseTraceParent(version-01-extra) -> [ok:true] [00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01]
+
+}
+
+func ExampleInject() {
+	h := make(http.Header)
+	h.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.Add(TraceStateHeader, "vendor1=a")
+	h.Add(TraceStateHeader, "vendor2=b")
+	ctx := Extract(context.Background(), h)
+	sc := SpanContextFromContext(ctx)
+	fmt.Printf("test: Extract() -> [remote:%v] [state:%v]\n", sc.Remote, sc.State)
+
+	out := make(http.Header)
+	Inject(ctx, out)
+	fmt.Printf("test: Inject(remote) -> [traceparent:%v] [tracestate:%v]\n", out.Get(TraceParentHeader), out.Get(TraceStateHeader))
+
+	ctx, span := Start(ctx, "test", ClientKind)
+	out = make(http.Header)
+	Inject(ctx, out)
+	sc, _ = ParseTraceParent(out.Get(TraceParentHeader))
+	fmt.Printf("test: Inject(span) -> [trace:%v] [span:%v] [parent:%v]\n", sc.TraceID, sc.SpanID == span.Context.SpanID, span.Parent)
+
+	out = make(http.Header)
+	Inject(Extract(context.Background(), make(http.Header)), out)
+	fmt.Printf("test: Inject(none) -> [traceparent:%v]\n", out.Get(TraceParentHeader))
+
+	//Output:
+	//test: Extract() -> [remote:true] [state:vendor1=a,vendor2=b]
+	//test: Inject(remote) -> [traceparent:00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01] [tracestate:vendor1=a,vendor2=b]
+	//test: Inject(span) -> [trace:4bf92f3577b34da6a3ce929d0e0e4736] [span:true] [parent:00f067aa0ba902b7]
+	//test: Inject(none) -> [traceparent:]
+
+}
@@ -0,0 +1,224 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultQueueSize     = 2048
+	DefaultBatchSize     = 512
+	DefaultBatchInterval = time.Second * 5
+	DefaultExportTimeout = time.Second * 30
+)
+
+// Exporter - interface for sending ended spans to a tracing backend
+type Exporter interface {
+	Export(ctx context.Context, spans []*Span) error
+}
+
+// BatchConfig - configuration for exporting spans in batches, a value <= 0 is the default
+type BatchConfig struct {
+	QueueSize     int           // Spans waiting to be exported, spans are dropped when the queue is full
+	BatchSize     int           // Maximum spans in an export
+	Interval      time.Duration // Maximum wait before a partial batch is exported
+	ExportTimeout time.Duration
+}
+
+func NewBatchConfig(queueSize, batchSize int, interval time.Duration) *BatchConfig {
+	c := new(BatchConfig)
+	c.QueueSize = queueSize
+	c.BatchSize = batchSize
+	c.Interval = interval
+	return c
+}
+
+type batcher struct {
+	exporter Exporter
+	config   BatchConfig
+	queue    chan *Span
+	flush    chan chan struct{}
+	done     chan struct{}
+	exited   chan struct{}
+	once     sync.Once
+	dropped  uint64
+	failed   uint64
+}
+
+var (
+	mu      sync.RWMutex
+	current *batcher
+)
+
+// SetExporter - export sampled spans in batches from a background goroutine, nil stops exporting. The previous
+// exporter is shut down, and its queued spans are exported
+func SetExporter(exporter Exporter, config *BatchConfig) {
+	var b *batcher
+	if exporter != nil {
+		b = newBatcher(exporter, config)
+	}
+	mu.Lock()
+	prev := current
+	current = b
+	mu.Unlock()
+	if prev != nil {
+		prev.shutdown(context.Background())
+	}
+}
+
+// Flush - export the queued spans
+func Flush(ctx context.Context) error {
+	mu.RLock()
+	b := current
+	mu.RUnlock()
+	if b == nil {
+		return nil
+	}
+	return b.flushQueue(ctx)
+}
+
+// Shutdown - export the queued spans and stop exporting
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	b := current
+	current = nil
+	mu.Unlock()
+	if b == nil {
+		return nil
+	}
+	return b.shutdown(ctx)
+}
+
+// Stats - spans dropped because the queue was full, and spans that failed to export
+func Stats() (dropped, failed uint64) {
+	mu.RLock()
+	b := current
+	mu.RUnlock()
+	if b == nil {
+		return 0, 0
+	}
+	return atomic.LoadUint64(&b.dropped), atomic.LoadUint64(&b.failed)
+}
+
+func export(s *Span) {
+	mu.RLock()
+	b := current
+	mu.RUnlock()
+	if b == nil {
+		return
+	}
+	select {
+	case b.queue <- s:
+	default:
+		atomic.AddUint64(&b.dropped, 1)
+	}
+}
+
+func newBatcher(exporter Exporter, config *BatchConfig) *batcher {
+	b := new(batcher)
+	b.exporter = exporter
+	if config != nil {
+		b.config = *config
+	}
+	if b.config.QueueSize <= 0 {
+		b.config.QueueSize = DefaultQueueSize
+	}
+	if b.config.BatchSize <= 0 {
+		b.config.BatchSize = DefaultBatchSize
+	}
+	if b.config.Interval <= 0 {
+		b.config.Interval = DefaultBatchInterval
+	}
+	if b.config.ExportTimeout <= 0 {
+		b.config.ExportTimeout = DefaultExportTimeout
+	}
+	b.queue = make(chan *Span, b.config.QueueSize)
+	b.flush = make(chan chan struct{})
+	b.done = make(chan struct{})
+	b.exited = make(chan struct{})
+	go b.run()
+	return b
+}
+
+func (b *batcher) run() {
+	defer close(b.exited)
+	ticker := time.NewTicker(b.config.Interval)
+	defer ticker.Stop()
+	var batch []*Span
+	for {
+		select {
+		case s := <-b.queue:
+			batch = append(batch, s)
+			if len(batch) >= b.config.BatchSize {
+				batch = b.export(batch)
+			}
+		case <-ticker.C:
+			batch = b.export(batch)
+		case reply := <-b.flush:
+			batch = b.export(b.drain(batch))
+			close(reply)
+		case <-b.done:
+			b.export(b.drain(batch))
+			return
+		}
+	}
+}
+
+// drain - add the queued spans to the batch
+func (b *batcher) drain(batch []*Span) []*Span {
+	for {
+		select {
+		case s := <-b.queue:
+			batch = append(batch, s)
+		default:
+			return batch
+		}
+	}
+}
+
+// export - export the batch in exports of at most BatchSize spans, and return an empty batch
+func (b *batcher) export(batch []*Span) []*Span {
+	for len(batch) > 0 {
+		n := len(batch)
+		if n > b.config.BatchSize {
+			n = b.config.BatchSize
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), b.config.ExportTimeout)
+		if err := b.exporter.Export(ctx, batch[:n]); err != nil {
+			atomic.AddUint64(&b.failed, uint64(n))
+		}
+		cancel()
+		batch = batch[n:]
+	}
+	return nil
+}
+
+func (b *batcher) flushQueue(ctx context.Context) error {
+	reply := make(chan struct{})
+	select {
+	case b.flush <- reply:
+	case <-b.exited:
+		return errors.New("invalid state: exporter is shut down")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-reply:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *batcher) shutdown(ctx context.Context) error {
+	b.once.Do(func() { close(b.done) })
+	select {
+	case <-b.exited:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
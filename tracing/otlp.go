@@ -0,0 +1,169 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+	ScopeName           = "github.com/gotemplates/host"
+	otlpStatusError     = 2
+	maxErrorBody        = 1024
+)
+
+// OTLPConfig - configuration for the OTLP/HTTP exporter, spans are sent with the JSON encoding
+type OTLPConfig struct {
+	Endpoint    string            // Collector traces url, "" is DefaultOTLPEndpoint
+	ServiceName string            // service.name resource attribute
+	Headers     map[string]string // Headers added to each export, for example authorization
+	Client      *http.Client      // nil is a client with the default transport
+}
+
+func NewOTLPConfig(endpoint, serviceName string) *OTLPConfig {
+	c := new(OTLPConfig)
+	if endpoint == "" {
+		endpoint = DefaultOTLPEndpoint
+	}
+	c.Endpoint = endpoint
+	c.ServiceName = serviceName
+	return c
+}
+
+// OTLPExporter - an Exporter that sends spans to an OpenTelemetry collector with OTLP/HTTP
+type OTLPExporter struct {
+	config OTLPConfig
+}
+
+func NewOTLPExporter(config *OTLPConfig) (*OTLPExporter, error) {
+	if config == nil {
+		return nil, errors.New("invalid argument: OTLP configuration is nil")
+	}
+	e := new(OTLPExporter)
+	e.config = *config
+	if e.config.Endpoint == "" {
+		e.config.Endpoint = DefaultOTLPEndpoint
+	}
+	if e.config.Client == nil {
+		// Not the default client, which may be wrapped by the egress controllers
+		e.config.Client = &http.Client{Transport: http.DefaultTransport}
+	}
+	return e, nil
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, spans []*Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	buf, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.config.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.New(fmt.Sprintf("invalid response: OTLP export status code [%v] [%s]", resp.StatusCode, body))
+	}
+	return nil
+}
+
+// OTLP JSON encoding of an ExportTraceServiceRequest, ids are hex and 64 bit integers are strings
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceId           string          `json:"traceId"`
+	SpanId            string          `json:"spanId"`
+	TraceState        string          `json:"traceState,omitempty"`
+	ParentSpanId      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              Kind            `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code int `json:"code"`
+}
+
+func (e *OTLPExporter) request(spans []*Span) otlpRequest {
+	scope := otlpScopeSpans{Scope: otlpScope{Name: ScopeName}}
+	for _, s := range spans {
+		item := otlpSpan{
+			TraceId:           s.Context.TraceID.String(),
+			SpanId:            s.Context.SpanID.String(),
+			TraceState:        s.Context.State,
+			Name:              s.Name,
+			Kind:              s.Kind,
+			StartTimeUnixNano: unixNano(s.Start),
+			EndTimeUnixNano:   unixNano(s.End),
+		}
+		if s.Parent.IsValid() {
+			item.ParentSpanId = s.Parent.String()
+		}
+		for _, k := range s.Attributes() {
+			v, _ := s.Attribute(k)
+			item.Attributes = append(item.Attributes, otlpAttribute{Key: k, Value: otlpValue{StringValue: v}})
+		}
+		if s.Error {
+			item.Status = &otlpStatus{Code: otlpStatusError}
+		}
+		scope.Spans = append(scope.Spans, item)
+	}
+	resource := otlpResource{Attributes: []otlpAttribute{{Key: "service.name", Value: otlpValue{StringValue: e.config.ServiceName}}}}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{Resource: resource, ScopeSpans: []otlpScopeSpans{scope}}}}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleOTLPExporter_Export() {
+	var body []byte
+	var contentType, auth string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+		auth = r.Header.Get("Authorization")
+	}))
+	defer collector.Close()
+
+	config := NewOTLPConfig(collector.URL, "test-service")
+	config.Headers = map[string]string{"Authorization": "Bearer token"}
+	e, _ := NewOTLPExporter(config)
+
+	parent, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	parent.State = "vendor=a"
+	ctx := context.WithValue(context.Background(), spanContextKey{}, parent)
+	_, span := Start(ctx, "egress test-route", ClientKind)
+	span.SetAttribute("route.name", "test-route")
+	span.SetError(true)
+	span.Finish()
+
+	err := e.Export(context.Background(), []*Span{span})
+	fmt.Printf("test: Export() -> [err:%v] [content-type:%v] [authorization:%v]\n", err, contentType, auth)
+
+	var req otlpRequest
+	json.Unmarshal(body, &req)
+	rs := req.ResourceSpans[0]
+	s := rs.ScopeSpans[0].Spans[0]
+	fmt.Printf("test: Export() -> [resource:%v] [scope:%v]\n", rs.Resource.Attributes, rs.ScopeSpans[0].Scope.Name)
+	fmt.Printf("test: Export() -> [trace:%v] [parent:%v] [state:%v] [name:%v] [kind:%v] [attributes:%v] [status:%v]\n", s.TraceId, s.ParentSpanId,
+		s.TraceState, s.Name, s.Kind, s.Attributes, *s.Status)
+	fmt.Printf("test: Export() -> [span:%v] [start:%v] [end:%v]\n", s.SpanId == span.Context.SpanID.String(), s.StartTimeUnixNano == unixNano(span.Start),
+		s.EndTimeUnixNano == unixNano(span.End))
+
+	//Output:
+	//test: Export() -> [err:<nil>] [content-type:application/json] [authorization:Bearer token]
+	//test: Export() -> [resource:[{service.name {test-service}}]] [scope:github.com/gotemplates/host]
+	//test: Export() -> [trace:4bf92f3577b34da6a3ce929d0e0e4736] [parent:00f067aa0ba902b7] [state:vendor=a] [name:egress test-route] [kind:3] [attributes:[{route.name {test-route}}]] [status:{2}]
+	//test: Export() -> [span:true] [start:true] [end:true]
+
+}
+
+func ExampleOTLPExporter_Export_error() {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("unavailable"))
+	}))
+	defer collector.Close()
+
+	e, _ := NewOTLPExporter(NewOTLPConfig(collector.URL, "test-service"))
+	_, span := Start(context.Background(), "test", InternalKind)
+	err := e.Export(context.Background(), []*Span{span})
+	fmt.Printf("test: Export() -> [err:%v]\n", err)
+
+	_, err = NewOTLPExporter(nil)
+	fmt.Printf("test: NewOTLPExporter(nil) -> [err:%v]\n", err)
+
+	//Output:
+	//test: Export() -> [err:invalid response: OTLP export status code [503] [unavailable]]
+	//test: NewOTLPExporter(nil) -> [err:invalid argument: OTLP configuration is nil]
+
+}
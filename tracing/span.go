@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kind - the relationship of a span to the remote side of a request, values are those of OTLP
+type Kind int
+
+const (
+	InternalKind Kind = 1
+	ServerKind   Kind = 2
+	ClientKind   Kind = 3
+)
+
+// Span - an operation of a trace. A span is exported when it ends, if it is sampled
+type Span struct {
+	Name    string
+	Kind    Kind
+	Context SpanContext
+	Parent  SpanID
+	Start   time.Time
+	End     time.Time
+	Error   bool
+
+	mu         sync.Mutex
+	attributes map[string]string
+	ended      bool
+}
+
+// Start - start a span that is a child of the span, or remote trace context, of the context. A span without a
+// parent starts a new, sampled, trace
+func Start(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	parent := SpanContextFromContext(ctx)
+	span := &Span{Name: name, Kind: kind, Start: time.Now().UTC()}
+	if parent.IsValid() {
+		span.Context = SpanContext{TraceID: parent.TraceID, Flags: parent.Flags, State: parent.State}
+		span.Parent = parent.SpanID
+	} else {
+		span.Context = SpanContext{TraceID: newTraceID(), Flags: sampledFlag}
+	}
+	span.Context.SpanID = newSpanID()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttribute - set an attribute, attributes set after the span ends are ignored
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	if s.attributes == nil {
+		s.attributes = make(map[string]string)
+	}
+	s.attributes[key] = value
+}
+
+// SetError - set the status of the span
+func (s *Span) SetError(err bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.Error = err
+	}
+}
+
+// Attribute - the value of an attribute
+func (s *Span) Attribute(key string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.attributes[key]
+	return v, ok
+}
+
+// Attributes - the attribute keys, sorted
+func (s *Span) Attributes() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.attributes))
+	for k := range s.attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Finish - end the span, and export it if it is sampled. Only the first call has an effect
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now().UTC()
+	s.mu.Unlock()
+	if s.Context.IsSampled() {
+		export(s)
+	}
+}
@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type testExporter struct {
+	mu      sync.Mutex
+	exports []int
+	spans   []*Span
+	err     error
+}
+
+func (e *testExporter) Export(ctx context.Context, spans []*Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.exports = append(e.exports, len(spans))
+	e.spans = append(e.spans, spans...)
+	return e.err
+}
+
+func ExampleStart() {
+	e := new(testExporter)
+	SetExporter(e, NewBatchConfig(0, 0, time.Hour))
+	defer Shutdown(context.Background())
+
+	ctx, root := Start(context.Background(), "root", ServerKind)
+	_, child := Start(ctx, "child", ClientKind)
+	child.SetAttribute("key", "value")
+	child.SetError(true)
+	child.Finish()
+	child.SetAttribute("ignored", "value")
+	root.Finish()
+	root.Finish()
+
+	Flush(context.Background())
+	fmt.Printf("test: Start() -> [exports:%v] [root-parent:%v] [same-trace:%v] [child-parent:%v]\n", e.exports, root.Parent.IsValid(),
+		root.Context.TraceID == child.Context.TraceID, child.Parent == root.Context.SpanID)
+	fmt.Printf("test: Span() -> [name:%v] [kind:%v] [attributes:%v] [error:%v] [ended:%v]\n", e.spans[0].Name, e.spans[0].Kind, e.spans[0].Attributes(),
+		e.spans[0].Error, !e.spans[0].End.Before(e.spans[0].Start))
+
+	//Output:
+	//test: Start() -> [exports:[2]] [root-parent:false] [same-trace:true] [child-parent:true]
+	//test: Span() -> [name:child] [kind:3] [attributes:[key]] [error:true] [ended:true]
+
+}
+
+func ExampleStart_notSampled() {
+	e := new(testExporter)
+	SetExporter(e, nil)
+	defer Shutdown(context.Background())
+
+	parent, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ctx := context.WithValue(context.Background(), spanContextKey{}, parent)
+	_, span := Start(ctx, "not-sampled", ServerKind)
+	span.Finish()
+
+	Flush(context.Background())
+	fmt.Printf("test: Start(not-sampled) -> [exports:%v] [sampled:%v]\n", len(e.exports), span.Context.IsSampled())
+
+	//Output:
+	//test: Start(not-sampled) -> [exports:0] [sampled:false]
+
+}
+
+func ExampleSetExporter() {
+	e := &testExporter{err: errors.New("export failure")}
+	SetExporter(e, &BatchConfig{QueueSize: 10, BatchSize: 2, Interval: time.Hour})
+
+	for i := 0; i < 5; i++ {
+		_, span := Start(context.Background(), fmt.Sprintf("span-%v", i), InternalKind)
+		span.Finish()
+	}
+	Flush(context.Background())
+	_, failed := Stats()
+	fmt.Printf("test: SetExporter() -> [exports:%v] [failed:%v]\n", e.exports, failed)
+
+	Shutdown(context.Background())
+	fmt.Printf("test: Shutdown() -> [flush:%v]\n", Flush(context.Background()))
+
+	//Output:
+	//test: SetExporter() -> [exports:[2 2 1]] [failed:5]
+	//test: Shutdown() -> [flush:<nil>]
+
+}
+
+func Example_export_dropped() {
+	// A batcher without a goroutine reading the queue, so it is removed without a shutdown
+	mu.Lock()
+	current = &batcher{queue: make(chan *Span, 2)}
+	mu.Unlock()
+	defer func() {
+		mu.Lock()
+		current = nil
+		mu.Unlock()
+	}()
+
+	for i := 0; i < 5; i++ {
+		_, span := Start(context.Background(), fmt.Sprintf("span-%v", i), InternalKind)
+		span.Finish()
+	}
+	dropped, failed := Stats()
+	fmt.Printf("test: export() -> [queued:%v] [dropped:%v] [failed:%v]\n", len(current.queue), dropped, failed)
+
+	//Output:
+	//test: export() -> [queued:2] [dropped:3] [failed:0]
+
+}